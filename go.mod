@@ -4,12 +4,22 @@ go 1.22.3
 
 require golang.org/x/time v0.6.0
 
-require github.com/hakadoriya/z.go v0.0.0-20240922214027-5c221e47f81a
+require (
+	github.com/hakadoriya/z.go v0.0.0-20240922214027-5c221e47f81a
+	go.opentelemetry.io/otel/sdk v1.30.0
+	go.opentelemetry.io/otel/sdk/metric v1.30.0
+	go.yaml.in/yaml/v3 v3.0.4
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+)
 
 require (
 	github.com/go-logr/logr v1.4.2 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
 	go.opentelemetry.io/otel v1.30.0
-	go.opentelemetry.io/otel/metric v1.30.0 // indirect
+	go.opentelemetry.io/otel/metric v1.30.0
 	go.opentelemetry.io/otel/trace v1.30.0
 )
@@ -18,9 +18,24 @@ type PostOAuthV1AccessTokensRequest struct {
 type PostOAuthV1AccessTokensResponse struct {
 	AccessToken string `json:"accessToken"`
 	TokenType   string `json:"tokenType"`
-	ExpiresIn   string `json:"expiresIn"`
-	Scope       string `json:"scope"`
-	IssuedAt    string `json:"issuedAt"`
+	// ExpiresIn is the lifetime of the access token in seconds.
+	ExpiresIn int64  `json:"expiresIn"`
+	Scope     string `json:"scope"`
+	IssuedAt  Time   `json:"issuedAt"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts expiresIn both as a number and as a string, because the API returns it as a string.
+func (r *PostOAuthV1AccessTokensResponse) UnmarshalJSON(b []byte) error {
+	type alias PostOAuthV1AccessTokensResponse
+	v := struct {
+		*alias
+		ExpiresIn flexInt64 `json:"expiresIn"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return errorz.Errorf("json.Unmarshal: %w", err)
+	}
+	r.ExpiresIn = int64(v.ExpiresIn)
+	return nil
 }
 
 // Access Token Generation
@@ -54,7 +69,7 @@ type PostOAuthV1AccessTokensResponse struct {
 //	    "issuedAt": "1550570350202"
 //	}
 func (c *Client) PostOAuthV1AccessTokens(ctx context.Context, req *PostOAuthV1AccessTokensRequest) (*PostOAuthV1AccessTokensResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -7,13 +7,15 @@ import (
 	"path"
 	"strconv"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
 type WebArenaIndigoV1AuthAPIKey struct {
 	ID        int64  `json:"id"`
 	APIKey    string `json:"apiKey"`
-	CreatedAt string `json:"created_at"` //nolint:tagliatelle // JSON field name is defined by the API
+	CreatedAt Time   `json:"created_at"` //nolint:tagliatelle // JSON field name is defined by the API
 }
 
 // API Key List
@@ -45,7 +47,7 @@ type WebArenaIndigoV1AuthAPIKey struct {
 //	    ]
 //	}
 func (c *Client) GetWebArenaIndigoV1AuthAPIKey(ctx context.Context) (*GetWebArenaIndigoV1AuthAPIKeyResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, PathWebArenaIndigoV1AuthAPIKey, nil)
@@ -95,7 +97,7 @@ type GetWebArenaIndigoV1AuthAPIKeyResponse struct {
 //	    "message": "API Key is removed successfully"
 //	}
 func (c *Client) DeleteWebArenaIndigoV1AuthAPIKey(ctx context.Context, apiKeyID int64) (*DeleteWebArenaIndigoV1AuthAPIKeyResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyAPIKeyID.Int64(apiKeyID)))
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodDelete, path.Join(PathWebArenaIndigoV1AuthAPIKey, strconv.FormatInt(apiKeyID, 10)), nil) //nolint:staticcheck
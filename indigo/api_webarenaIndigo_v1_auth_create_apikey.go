@@ -30,7 +30,7 @@ import (
 //	    "apiSecret": "LmAY0pB1xA1fas"
 //	}
 func (c *Client) CreateWebArenaIndigoV1AuthCreateAPIKey(ctx context.Context) (*CreateWebArenaIndigoV1AuthCreateAPIKeyResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, PathWebArenaIndigoV1AuthCreateAPIKey, nil)
@@ -7,6 +7,8 @@ import (
 	"path"
 	"strconv"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -28,7 +30,7 @@ import (
 // RESPONSE BODY
 // {"STATUS":0}.
 func (c *Client) DeleteWebArenaIndigoV1DiskDeleteSnapshot(ctx context.Context, snapshotID int64) (*DeleteWebArenaIndigoV1DiskDeleteSnapshotResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeySnapshotID.Int64(snapshotID)))
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodDelete, path.Join(PathWebArenaIndigoV1DiskDeleteSnapshot, strconv.FormatInt(snapshotID, 10)), nil) //nolint:staticcheck
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -27,7 +29,7 @@ import (
 // RESPONSE BODY
 // {"STATUS":0}.
 func (c *Client) PostWebArenaIndigoV1DiskRestoreSnapshot(ctx context.Context, req *PostWebArenaIndigoV1DiskRestoreSnapshotRequest) (*PostWebArenaIndigoV1DiskRestoreSnapshotResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(req.InstanceID), attributeKeySnapshotID.Int64(req.SnapshotID)))
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -56,8 +58,8 @@ func (c *Client) PostWebArenaIndigoV1DiskRestoreSnapshot(ctx context.Context, re
 }
 
 type PostWebArenaIndigoV1DiskRestoreSnapshotRequest struct {
-	InstanceID int64  `json:"instanceid"`
-	SnapshotID string `json:"snapshotid"`
+	InstanceID int64 `json:"instanceid"`
+	SnapshotID int64 `json:"snapshotid,string"`
 }
 
 type PostWebArenaIndigoV1DiskRestoreSnapshotResponse struct {
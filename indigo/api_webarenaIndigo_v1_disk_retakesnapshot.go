@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -28,7 +30,7 @@ import (
 //
 //	{"STATUS":0}.
 func (c *Client) PostWebArenaIndigoV1DiskRetakeSnapshot(ctx context.Context, req *PostWebArenaIndigoV1DiskRetakeSnapshotRequest) (*PostWebArenaIndigoV1DiskRetakeSnapshotResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(req.InstanceID), attributeKeySnapshotID.Int64(req.SnapshotID)))
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -57,8 +59,8 @@ func (c *Client) PostWebArenaIndigoV1DiskRetakeSnapshot(ctx context.Context, req
 }
 
 type PostWebArenaIndigoV1DiskRetakeSnapshotRequest struct {
-	InstanceID int64  `json:"instanceid"`
-	SnapshotID string `json:"snapshotid"`
+	InstanceID int64 `json:"instanceid"`
+	SnapshotID int64 `json:"snapshotid,string"`
 }
 
 type PostWebArenaIndigoV1DiskRetakeSnapshotResponse struct {
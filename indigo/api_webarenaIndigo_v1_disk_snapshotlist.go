@@ -7,6 +7,8 @@ import (
 	"path"
 	"strconv"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -14,15 +16,30 @@ type WebArenaIndigoV1DiskSnapshot struct {
 	ID                 int64  `json:"id"`
 	Name               string `json:"name"`
 	ServiceID          string `json:"service_id"` //nolint:tagliatelle // JSON field name is defined by the API
-	UserID             string `json:"user_id"`    //nolint:tagliatelle // JSON field name is defined by the API
+	UserID             int64  `json:"user_id"`    //nolint:tagliatelle // JSON field name is defined by the API
 	DiskID             int64  `json:"disk_id"`    //nolint:tagliatelle // JSON field name is defined by the API
 	Volume             int64  `json:"volume"`
 	SlotNumber         int64  `json:"slot_number"` //nolint:tagliatelle // JSON field name is defined by the API
 	Status             string `json:"status"`
-	Size               string `json:"size"`
+	Size               int64  `json:"size"`
 	Deleted            int64  `json:"deleted"`
-	CompletedTimestamp string `json:"completed_timestamp"` //nolint:tagliatelle // JSON field name is defined by the API
-	DeletedTimestamp   string `json:"deleted_timestamp"`   //nolint:tagliatelle // JSON field name is defined by the API
+	CompletedTimestamp Time   `json:"completed_timestamp"` //nolint:tagliatelle // JSON field name is defined by the API
+	DeletedTimestamp   Time   `json:"deleted_timestamp"`   //nolint:tagliatelle // JSON field name is defined by the API
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts user_id and size both as numbers and as strings, because the API returns them as strings.
+func (s *WebArenaIndigoV1DiskSnapshot) UnmarshalJSON(b []byte) error {
+	type alias WebArenaIndigoV1DiskSnapshot
+	v := struct {
+		*alias
+		UserID flexInt64 `json:"user_id"` //nolint:tagliatelle // JSON field name is defined by the API
+		Size   flexInt64 `json:"size"`
+	}{alias: (*alias)(s)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return errorz.Errorf("json.Unmarshal: %w", err)
+	}
+	s.UserID, s.Size = int64(v.UserID), int64(v.Size)
+	return nil
 }
 
 // Snapshot list
@@ -74,7 +91,7 @@ type WebArenaIndigoV1DiskSnapshot struct {
 //
 // ].
 func (c *Client) GetWebArenaIndigoV1DiskSnapshotList(ctx context.Context, instanceID int64) (*GetWebArenaIndigoV1DiskSnapshotListResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(instanceID)))
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, path.Join(PathWebArenaIndigoV1DiskSnapshotList, strconv.FormatInt(instanceID, 10)), nil)
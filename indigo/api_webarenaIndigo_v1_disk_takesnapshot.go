@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -28,7 +30,7 @@ import (
 //
 //	{"STATUS":0}.
 func (c *Client) PostWebArenaIndigoV1DiskTakeSnapshot(ctx context.Context, req *PostWebArenaIndigoV1DiskTakeSnapshotRequest) (*PostWebArenaIndigoV1DiskTakeSnapshotResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(req.InstanceID)))
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -59,7 +61,7 @@ func (c *Client) PostWebArenaIndigoV1DiskTakeSnapshot(ctx context.Context, req *
 type PostWebArenaIndigoV1DiskTakeSnapshotRequest struct {
 	Name       string `json:"name"`
 	InstanceID int64  `json:"instanceid"`
-	SlotNum    string `json:"slotnum"`
+	SlotNum    int64  `json:"slotnum,string"`
 }
 
 type PostWebArenaIndigoV1DiskTakeSnapshotResponse struct {
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -35,7 +37,7 @@ import (
 //	    "sucessCode": "F60003"
 //	}
 func (c *Client) PostWebArenaIndigoV1NwAssign(ctx context.Context, req *PostWebArenaIndigoV1NwAssignRequest) (*PostWebArenaIndigoV1NwAssignResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(req.InstanceID), attributeKeyFirewallID.Int64(req.TemplateID)))
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -51,9 +51,14 @@ type WebArenaIndigoV1NwFirewallRule struct {
 //	    "firewallId": 55
 //	}
 func (c *Client) PostWebArenaIndigoV1NwCreateFirewall(ctx context.Context, req *PostWebArenaIndigoV1NwCreateFirewallRequest) (*PostWebArenaIndigoV1NwCreateFirewallResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
+	if err := req.Validate(); err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("req.Validate: %w", err)
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, errorz.Errorf("json.Marshal: %w", err)
@@ -83,7 +88,34 @@ type PostWebArenaIndigoV1NwCreateFirewallRequest struct {
 	Name      string                           `json:"name"`
 	Inbound   []WebArenaIndigoV1NwFirewallRule `json:"inbound"`
 	Outbound  []WebArenaIndigoV1NwFirewallRule `json:"outbound"`
-	Instances []string                         `json:"instances"`
+	Instances []int64                          `json:"instances"`
+}
+
+// MarshalJSON implements json.Marshaler. It encodes Instances as strings, e.g. ["6","5"], as the API requires.
+func (r PostWebArenaIndigoV1NwCreateFirewallRequest) MarshalJSON() ([]byte, error) {
+	type alias PostWebArenaIndigoV1NwCreateFirewallRequest
+	b, err := json.Marshal(struct {
+		alias
+		Instances []quotedInt64 `json:"instances"`
+	}{alias: alias(r), Instances: quoteInt64s(r.Instances)})
+	if err != nil {
+		return nil, errorz.Errorf("json.Marshal: %w", err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts Instances both as numbers and as strings.
+func (r *PostWebArenaIndigoV1NwCreateFirewallRequest) UnmarshalJSON(b []byte) error {
+	type alias PostWebArenaIndigoV1NwCreateFirewallRequest
+	v := struct {
+		*alias
+		Instances []quotedInt64 `json:"instances"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return errorz.Errorf("json.Unmarshal: %w", err)
+	}
+	r.Instances = unquoteInt64s(v.Instances)
+	return nil
 }
 
 type PostWebArenaIndigoV1NwCreateFirewallResponse struct {
@@ -7,6 +7,8 @@ import (
 	"path"
 	"strconv"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -32,7 +34,7 @@ import (
 //	    "sucessCode": "F6005"
 //	}
 func (c *Client) DeleteWebArenaIndigoV1NwDeleteFirewall(ctx context.Context, firewallID int64) (*DeleteWebArenaIndigoV1NwDeleteFirewallResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyFirewallID.Int64(firewallID)))
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodDelete, path.Join(PathWebArenaIndigoV1NwDeleteFirewall, strconv.FormatInt(firewallID, 10)), nil) //nolint:staticcheck
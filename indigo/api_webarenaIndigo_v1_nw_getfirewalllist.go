@@ -14,8 +14,8 @@ type WebArenaIndigoV1NwFirewall struct {
 	UserID    int64  `json:"user_id"`    //nolint:tagliatelle // JSON field name is defined by the API
 	Name      string `json:"name"`
 	Status    int64  `json:"status"`
-	CreatedAt string `json:"created_at"` //nolint:tagliatelle // JSON field name is defined by the API
-	UpdatedAt string `json:"updated_at"` //nolint:tagliatelle // JSON field name is defined by the API
+	CreatedAt Time   `json:"created_at"` //nolint:tagliatelle // JSON field name is defined by the API
+	UpdatedAt Time   `json:"updated_at"` //nolint:tagliatelle // JSON field name is defined by the API
 }
 
 // Get Firewall list
@@ -56,7 +56,7 @@ type WebArenaIndigoV1NwFirewall struct {
 //	  }
 //	]
 func (c *Client) GetWebArenaIndigoV1NwGetFirewallList(ctx context.Context) (*GetWebArenaIndigoV1NwGetFirewallListResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, PathWebArenaIndigoV1NwGetFirewallList, nil)
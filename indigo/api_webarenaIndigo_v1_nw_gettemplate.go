@@ -8,6 +8,8 @@ import (
 	"path"
 	"strconv"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -77,7 +79,7 @@ type WebArenaIndigoV1NwGetTemplateFirewall struct {
 //
 // IMPORTANT: The response body is not a valid JSON, so we need to read it as a byte slice and then append '[' and ']' to make it a valid JSON.
 func (c *Client) GetWebArenaIndigoV1NwGetTemplate(ctx context.Context, firewallID int64) (*GetWebArenaIndigoV1NwGetTemplateResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyFirewallID.Int64(firewallID)))
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, path.Join(PathWebArenaIndigoV1NwGetTemplate, strconv.FormatInt(firewallID, 10)), nil) //nolint:staticcheck
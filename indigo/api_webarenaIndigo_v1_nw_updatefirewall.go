@@ -45,9 +45,15 @@ import (
 //	    "firewallId": 55
 //	}
 func (c *Client) UpdateWebArenaIndigoV1NwFirewall(ctx context.Context, req *UpdateWebArenaIndigoV1NwFirewallRequest) (*UpdateWebArenaIndigoV1NwFirewallResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
+	if err := req.Validate(); err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("req.Validate: %w", err)
+	}
+	span.SetAttributes(attributeKeyFirewallID.Int64(req.TemplateID))
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, errorz.Errorf("json.Marshal: %w", err)
@@ -78,7 +84,34 @@ type UpdateWebArenaIndigoV1NwFirewallRequest struct {
 	Name       string                           `json:"name"`
 	Inbound    []WebArenaIndigoV1NwFirewallRule `json:"inbound"`
 	Outbound   []WebArenaIndigoV1NwFirewallRule `json:"outbound"`
-	Instances  []string                         `json:"instances"`
+	Instances  []int64                          `json:"instances"`
+}
+
+// MarshalJSON implements json.Marshaler. It encodes Instances as strings, e.g. ["6","5"], as the API requires.
+func (r UpdateWebArenaIndigoV1NwFirewallRequest) MarshalJSON() ([]byte, error) {
+	type alias UpdateWebArenaIndigoV1NwFirewallRequest
+	b, err := json.Marshal(struct {
+		alias
+		Instances []quotedInt64 `json:"instances"`
+	}{alias: alias(r), Instances: quoteInt64s(r.Instances)})
+	if err != nil {
+		return nil, errorz.Errorf("json.Marshal: %w", err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts Instances both as numbers and as strings.
+func (r *UpdateWebArenaIndigoV1NwFirewallRequest) UnmarshalJSON(b []byte) error {
+	type alias UpdateWebArenaIndigoV1NwFirewallRequest
+	v := struct {
+		*alias
+		Instances []quotedInt64 `json:"instances"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return errorz.Errorf("json.Unmarshal: %w", err)
+	}
+	r.Instances = unquoteInt64s(v.Instances)
+	return nil
 }
 
 type UpdateWebArenaIndigoV1NwFirewallResponse struct {
@@ -5,39 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
-type WebArenaIndigoV1VmInstanceDate struct {
-	Date         string `json:"date"`
-	TimezoneType int64  `json:"timezone_type"` //nolint:tagliatelle // JSON field name is defined by the API
-	Timezone     string `json:"timezone"`
-}
-
-func (d WebArenaIndigoV1VmInstanceDate) UnmarshalJSON(b []byte) error {
-	type Alias WebArenaIndigoV1VmInstanceDate
-	aux := &struct {
-		*Alias
-	}{
-		Alias: (*Alias)(&d),
-	}
-	if err := json.Unmarshal(b, &aux); err == nil {
-		d = WebArenaIndigoV1VmInstanceDate(*aux.Alias)
-		return nil
-	}
-
-	var s string
-	if err := json.Unmarshal(b, &s); err != nil {
-		return errorz.Errorf("json.Unmarshal: %w", err)
-	}
-
-	d.Date = s
-	d.TimezoneType = 3
-	d.Timezone = "UTC"
-
-	return nil
-}
-
 type WebArenaIndigoV1VmInstanceOS struct {
 	ID       int64  `json:"id"`
 	Name     string `json:"name"` //nolint:tagliatelle // JSON field name is defined by the API
@@ -45,35 +17,50 @@ type WebArenaIndigoV1VmInstanceOS struct {
 }
 
 type WebArenaIndigoV1VmInstance struct {
-	ID               int64                          `json:"id"`
-	InstanceName     string                         `json:"instance_name"` //nolint:tagliatelle // JSON field name is defined by the API
-	SetNo            int64                          `json:"set_no"`        //nolint:tagliatelle // JSON field name is defined by the API
-	VpsKind          string                         `json:"vps_kind"`      //nolint:tagliatelle // JSON field name is defined by the API
-	SequenceID       int64                          `json:"sequence_id"`   //nolint:tagliatelle // JSON field name is defined by the API
-	UserID           int64                          `json:"user_id"`       //nolint:tagliatelle // JSON field name is defined by the API
-	ServiceID        string                         `json:"service_id"`    //nolint:tagliatelle // JSON field name is defined by the API
-	Status           string                         `json:"status"`
-	SshKeyID         int64                          `json:"sshkey_id"`  //nolint:revive,stylecheck,tagliatelle // JSON field name is defined by the API
-	StartDate        WebArenaIndigoV1VmInstanceDate `json:"start_date"` //nolint:tagliatelle // JSON field name is defined by the API
-	HostID           int64                          `json:"host_id"`    //nolint:tagliatelle // JSON field name is defined by the API
-	Plan             string                         `json:"plan"`
-	DiskPoint        int64                          `json:"disk_point"` //nolint:tagliatelle // JSON field name is defined by the API
-	MemSize          int64                          `json:"memsize"`
-	CPUs             int64                          `json:"cpus"`
-	OsID             int64                          `json:"os_id"` //nolint:tagliatelle // JSON field name is defined by the API
-	OtherStatus      int64                          `json:"otherstatus"`
-	UUID             string                         `json:"uuid"`
-	UIDGID           int64                          `json:"uidgid"`
-	VncPort          int64                          `json:"vnc_port"`   //nolint:tagliatelle // JSON field name is defined by the API
-	VncPasswd        string                         `json:"vnc_passwd"` //nolint:tagliatelle // JSON field name is defined by the API
-	ArpaName         string                         `json:"arpaname"`
-	ArpaDate         string                         `json:"arpadate"`
-	StatusChangeDate WebArenaIndigoV1VmInstanceDate `json:"status_change_date"` //nolint:tagliatelle // JSON field name is defined by the API
-	UpdatedAt        string                         `json:"updated_at"`         //nolint:tagliatelle // JSON field name is defined by the API
-	VMRevert         int64                          `json:"vm_revert"`          //nolint:tagliatelle // JSON field name is defined by the API
-	VEID             string                         `json:"VEID"`               //nolint:tagliatelle // JSON field name is defined by the API
-	OS               WebArenaIndigoV1VmInstanceOS   `json:"os"`
-	IP               string                         `json:"ip"`
+	ID               int64                        `json:"id"`
+	InstanceName     string                       `json:"instance_name"` //nolint:tagliatelle // JSON field name is defined by the API
+	SetNo            int64                        `json:"set_no"`        //nolint:tagliatelle // JSON field name is defined by the API
+	VpsKind          int64                        `json:"vps_kind"`      //nolint:tagliatelle // JSON field name is defined by the API
+	SequenceID       int64                        `json:"sequence_id"`   //nolint:tagliatelle // JSON field name is defined by the API
+	UserID           int64                        `json:"user_id"`       //nolint:tagliatelle // JSON field name is defined by the API
+	ServiceID        string                       `json:"service_id"`    //nolint:tagliatelle // JSON field name is defined by the API
+	Status           InstanceStatus               `json:"status"`
+	SshKeyID         int64                        `json:"sshkey_id"`  //nolint:revive,stylecheck,tagliatelle // JSON field name is defined by the API
+	StartDate        Time                         `json:"start_date"` //nolint:tagliatelle // JSON field name is defined by the API
+	HostID           int64                        `json:"host_id"`    //nolint:tagliatelle // JSON field name is defined by the API
+	Plan             string                       `json:"plan"`
+	DiskPoint        int64                        `json:"disk_point"` //nolint:tagliatelle // JSON field name is defined by the API
+	MemSize          int64                        `json:"memsize"`
+	CPUs             int64                        `json:"cpus"`
+	OsID             int64                        `json:"os_id"` //nolint:tagliatelle // JSON field name is defined by the API
+	OtherStatus      int64                        `json:"otherstatus"`
+	UUID             string                       `json:"uuid"`
+	UIDGID           int64                        `json:"uidgid"`
+	VncPort          int64                        `json:"vnc_port"`   //nolint:tagliatelle // JSON field name is defined by the API
+	VncPasswd        string                       `json:"vnc_passwd"` //nolint:tagliatelle // JSON field name is defined by the API
+	ArpaName         string                       `json:"arpaname"`
+	ArpaDate         string                       `json:"arpadate"`
+	StatusChangeDate Time                         `json:"status_change_date"` //nolint:tagliatelle // JSON field name is defined by the API
+	UpdatedAt        Time                         `json:"updated_at"`         //nolint:tagliatelle // JSON field name is defined by the API
+	VMRevert         int64                        `json:"vm_revert"`          //nolint:tagliatelle // JSON field name is defined by the API
+	VEID             string                       `json:"VEID"`               //nolint:tagliatelle // JSON field name is defined by the API
+	OS               WebArenaIndigoV1VmInstanceOS `json:"os"`
+	IP               string                       `json:"ip"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts vps_kind both as a number and as a string,
+// because the API returns it as a number in the instance list and as a string in the created instance.
+func (i *WebArenaIndigoV1VmInstance) UnmarshalJSON(b []byte) error {
+	type alias WebArenaIndigoV1VmInstance
+	v := struct {
+		*alias
+		VpsKind flexInt64 `json:"vps_kind"` //nolint:tagliatelle // JSON field name is defined by the API
+	}{alias: (*alias)(i)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return errorz.Errorf("json.Unmarshal: %w", err)
+	}
+	i.VpsKind = int64(v.VpsKind)
+	return nil
 }
 
 // Instance Creation
@@ -141,7 +128,7 @@ type WebArenaIndigoV1VmInstance struct {
 //	    }
 //	}
 func (c *Client) PostWebArenaIndigoV1VmCreateInstance(ctx context.Context, req *PostWebArenaIndigoV1VmCreateInstanceRequest) (*PostWebArenaIndigoV1VmCreateInstanceResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -160,6 +147,7 @@ func (c *Client) PostWebArenaIndigoV1VmCreateInstance(ctx context.Context, req *
 		return nil, errorz.Errorf("c.doRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
+	c.invalidateInstanceCache()
 
 	var resp PostWebArenaIndigoV1VmCreateInstanceResponse
 	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
@@ -248,7 +236,7 @@ type PostWebArenaIndigoV1VmCreateInstanceResponse struct {
 //	    }
 //	}
 func (c *Client) PostWebArenaIndigoV1VmCreateWindowsInstance(ctx context.Context, req *PostWebArenaIndigoV1VmCreateWindowsInstanceRequest) (*PostWebArenaIndigoV1VmCreateWindowsInstanceResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -267,6 +255,7 @@ func (c *Client) PostWebArenaIndigoV1VmCreateWindowsInstance(ctx context.Context
 		return nil, errorz.Errorf("c.doRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
+	c.invalidateInstanceCache()
 
 	var resp PostWebArenaIndigoV1VmCreateWindowsInstanceResponse
 	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
@@ -355,7 +344,7 @@ type PostWebArenaIndigoV1VmCreateWindowsInstanceResponse struct {
 //	    }
 //	}
 func (c *Client) PostWebArenaIndigoV1VmCreateImportURLInstance(ctx context.Context, req *PostWebArenaIndigoV1VmCreateImportURLInstanceRequest) (*PostWebArenaIndigoV1VmCreateImportURLInstanceResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -374,6 +363,7 @@ func (c *Client) PostWebArenaIndigoV1VmCreateImportURLInstance(ctx context.Conte
 		return nil, errorz.Errorf("c.doRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
+	c.invalidateInstanceCache()
 
 	var resp PostWebArenaIndigoV1VmCreateImportURLInstanceResponse
 	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
@@ -461,7 +451,7 @@ type PostWebArenaIndigoV1VmCreateImportURLInstanceResponse struct {
 //	    }
 //	}
 func (c *Client) PostWebArenaIndigoV1VmCreateSnapshotInstance(ctx context.Context, req *PostWebArenaIndigoV1VmCreateSnapshotInstanceRequest) (*PostWebArenaIndigoV1VmCreateSnapshotInstanceResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeySnapshotID.Int64(req.SnapshotID)))
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -480,6 +470,7 @@ func (c *Client) PostWebArenaIndigoV1VmCreateSnapshotInstance(ctx context.Contex
 		return nil, errorz.Errorf("c.doRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
+	c.invalidateInstanceCache()
 
 	var resp PostWebArenaIndigoV1VmCreateSnapshotInstanceResponse
 	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
@@ -491,7 +482,7 @@ func (c *Client) PostWebArenaIndigoV1VmCreateSnapshotInstance(ctx context.Contex
 
 type PostWebArenaIndigoV1VmCreateSnapshotInstanceRequest struct {
 	SshKeyID     int64  `json:"sshKeyId"` //nolint:revive,stylecheck
-	SnapshotID   string `json:"snapshotId"`
+	SnapshotID   int64  `json:"snapshotId,string"`
 	InstancePlan int64  `json:"instancePlan"`
 	InstanceName string `json:"instanceName"`
 }
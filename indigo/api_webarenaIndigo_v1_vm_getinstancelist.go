@@ -98,7 +98,7 @@ import (
 //		}
 //	]
 func (c *Client) GetWebArenaIndigoV1VmGetInstanceList(ctx context.Context) (GetWebArenaIndigoV1VmGetInstanceListResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList, nil)
@@ -15,10 +15,10 @@ type WebArenaIndigoV1VmInstanceSpec struct {
 	ID              int64                          `json:"id"`
 	Name            string                         `json:"name"`
 	Description     string                         `json:"description"`
-	UsePossibleDate string                         `json:"use_possible_date"` //nolint:tagliatelle // JSON field name is defined by the API
+	UsePossibleDate Time                           `json:"use_possible_date"` //nolint:tagliatelle // JSON field name is defined by the API
 	InstanceTypeID  int64                          `json:"instancetype_id"`   //nolint:tagliatelle // JSON field name is defined by the API
-	CreatedAt       string                         `json:"created_at"`        //nolint:tagliatelle // JSON field name is defined by the API
-	UpdatedAt       string                         `json:"updated_at"`        //nolint:tagliatelle // JSON field name is defined by the API
+	CreatedAt       Time                           `json:"created_at"`        //nolint:tagliatelle // JSON field name is defined by the API
+	UpdatedAt       Time                           `json:"updated_at"`        //nolint:tagliatelle // JSON field name is defined by the API
 	InstanceType    WebArenaIndigoV1VmInstanceType `json:"instance_type"`     //nolint:tagliatelle // JSON field name is defined by the API
 }
 
@@ -62,7 +62,7 @@ type WebArenaIndigoV1VmInstanceSpec struct {
 //	    ]
 //	}
 func (c *Client) GetWebArenaIndigoV1VmInstanceSpec(ctx context.Context, instanceTypeID, osID int64) (*GetWebArenaIndigoV1VmInstanceSpecResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	urlValues := url.Values{}
@@ -74,9 +74,9 @@ func (c *Client) GetWebArenaIndigoV1VmInstanceSpec(ctx context.Context, instance
 		return nil, errorz.Errorf("c.newRequest: %w", err)
 	}
 
-	httpResp, err := c.doRequest(httpReq)
+	httpResp, err := c.doCacheableRequest(httpReq)
 	if err != nil {
-		return nil, errorz.Errorf("c.doRequest: %w", err)
+		return nil, errorz.Errorf("c.doCacheableRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
 
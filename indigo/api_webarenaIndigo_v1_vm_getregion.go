@@ -14,7 +14,7 @@ import (
 type WebArenaIndigoV1VmRegion struct {
 	ID              int64  `json:"id"`
 	Name            string `json:"name"`
-	UsePossibleDate string `json:"use_possible_date"` //nolint:tagliatelle // JSON field name is defined by the API
+	UsePossibleDate Time   `json:"use_possible_date"` //nolint:tagliatelle // JSON field name is defined by the API
 }
 
 // Get region list
@@ -51,7 +51,7 @@ type WebArenaIndigoV1VmRegion struct {
 //	    ]
 //	}
 func (c *Client) GetWebArenaIndigoV1VmGetRegion(ctx context.Context, instanceTypeID int64) (*GetWebArenaIndigoV1VmGetRegionResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	urlValues := url.Values{}
@@ -62,9 +62,9 @@ func (c *Client) GetWebArenaIndigoV1VmGetRegion(ctx context.Context, instanceTyp
 		return nil, errorz.Errorf("c.newRequest: %w", err)
 	}
 
-	httpResp, err := c.doRequest(httpReq)
+	httpResp, err := c.doCacheableRequest(httpReq)
 	if err != nil {
-		return nil, errorz.Errorf("c.doRequest: %w", err)
+		return nil, errorz.Errorf("c.doCacheableRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
 
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -33,7 +35,7 @@ import (
 //	    "instanceStatus": "shutoff"
 //	}
 func (c *Client) PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx context.Context, req *PostWebArenaIndigoV1VmInstanceStatusUpdateRequest) (*PostWebArenaIndigoV1VmInstanceStatusUpdateResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(req.InstanceID)))
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -52,6 +54,7 @@ func (c *Client) PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx context.Context,
 		return nil, errorz.Errorf("c.doRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
+	c.invalidateInstanceCache()
 
 	var resp PostWebArenaIndigoV1VmInstanceStatusUpdateResponse
 	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
@@ -62,13 +65,13 @@ func (c *Client) PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx context.Context,
 }
 
 type PostWebArenaIndigoV1VmInstanceStatusUpdateRequest struct {
-	InstanceID string `json:"instanceId"`
-	Status     string `json:"status"`
+	InstanceID int64          `json:"instanceId,string"`
+	Status     InstanceAction `json:"status"`
 }
 
 type PostWebArenaIndigoV1VmInstanceStatusUpdateResponse struct {
-	Success        bool   `json:"success"`
-	Message        string `json:"message"`
-	SuccessCode    string `json:"sucessCode"`
-	InstanceStatus string `json:"instanceStatus"`
+	Success        bool           `json:"success"`
+	Message        string         `json:"message"`
+	SuccessCode    string         `json:"sucessCode"`
+	InstanceStatus InstanceStatus `json:"instanceStatus"`
 }
@@ -12,8 +12,8 @@ type WebArenaIndigoV1VmInstanceType struct {
 	ID          int64  `json:"id"`
 	Name        string `json:"name"`
 	DisplayName string `json:"display_name"` //nolint:tagliatelle // JSON field name is defined by the API
-	CreatedAt   string `json:"created_at"`   //nolint:tagliatelle // JSON field name is defined by the API
-	UpdatedAt   string `json:"updated_at"`   //nolint:tagliatelle // JSON field name is defined by the API
+	CreatedAt   Time   `json:"created_at"`   //nolint:tagliatelle // JSON field name is defined by the API
+	UpdatedAt   Time   `json:"updated_at"`   //nolint:tagliatelle // JSON field name is defined by the API
 }
 
 // Instance Type List
@@ -47,7 +47,7 @@ type WebArenaIndigoV1VmInstanceType struct {
 //	    ]
 //	}
 func (c *Client) GetWebArenaIndigoV1VmInstanceTypes(ctx context.Context) (*GetWebArenaIndigoV1VmInstanceTypesResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, PathWebArenaIndigoV1VmInstanceTypes, nil)
@@ -55,9 +55,9 @@ func (c *Client) GetWebArenaIndigoV1VmInstanceTypes(ctx context.Context) (*GetWe
 		return nil, errorz.Errorf("c.newRequest: %w", err)
 	}
 
-	httpResp, err := c.doRequest(httpReq)
+	httpResp, err := c.doCacheableRequest(httpReq)
 	if err != nil {
-		return nil, errorz.Errorf("c.doRequest: %w", err)
+		return nil, errorz.Errorf("c.doCacheableRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
 
@@ -19,6 +19,13 @@ type WebArenaIndigoV1VmOS struct {
 	InstanceTypeID int64  `json:"instancetype_id"` //nolint:tagliatelle // JSON field name is defined by the API
 }
 
+type WebArenaIndigoV1VmOSCategory struct {
+	ID      int64                  `json:"id"`
+	Name    string                 `json:"name"`
+	Logo    string                 `json:"logo"`
+	OsLists []WebArenaIndigoV1VmOS `json:"osLists"`
+}
+
 // Get OS list
 // https://indigo.arena.ne.jp/userapi/#get_os_list
 //
@@ -57,7 +64,7 @@ type WebArenaIndigoV1VmOS struct {
 //	    ]
 //	}
 func (c *Client) GetWebArenaIndigoV1VmOSList(ctx context.Context, instanceTypeID int64) (*GetWebArenaIndigoV1VmOsListResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	urlValues := url.Values{}
@@ -68,9 +75,9 @@ func (c *Client) GetWebArenaIndigoV1VmOSList(ctx context.Context, instanceTypeID
 		return nil, errorz.Errorf("c.newRequest: %w", err)
 	}
 
-	httpResp, err := c.doRequest(httpReq)
+	httpResp, err := c.doCacheableRequest(httpReq)
 	if err != nil {
-		return nil, errorz.Errorf("c.doRequest: %w", err)
+		return nil, errorz.Errorf("c.doCacheableRequest: %w", err)
 	}
 	defer httpResp.Body.Close()
 
@@ -83,7 +90,7 @@ func (c *Client) GetWebArenaIndigoV1VmOSList(ctx context.Context, instanceTypeID
 }
 
 type GetWebArenaIndigoV1VmOsListResponse struct {
-	Success    bool                             `json:"success"`
-	Total      int64                            `json:"total"`
-	OsCategory []WebArenaIndigoV1VmInstanceSpec `json:"osCategory"`
+	Success    bool                           `json:"success"`
+	Total      int64                          `json:"total"`
+	OsCategory []WebArenaIndigoV1VmOSCategory `json:"osCategory"`
 }
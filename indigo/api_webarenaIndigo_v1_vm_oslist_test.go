@@ -19,5 +19,10 @@ func TestClient_GetWebArenaIndigoV1VmOSList(t *testing.T) {
 		resp, err := client.GetWebArenaIndigoV1VmOSList(ctx, 1)
 		requirez.NoError(t, err)
 		requirez.NotNil(t, resp)
+		for _, category := range resp.OsCategory {
+			for _, os := range category.OsLists {
+				requirez.Equal(t, category.ID, os.CategoryID)
+			}
+		}
 	})
 }
@@ -7,6 +7,8 @@ import (
 	"path"
 	"strconv"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hakadoriya/z.go/errorz"
 )
 
@@ -55,7 +57,7 @@ import (
 //	    ]
 //	}
 func (c *Client) GetWebArenaIndigoV1VmSSHKey(ctx context.Context) (*WebArenaIndigoV1VmSSHKeyResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, PathWebArenaIndigoV1VmSSHKey, nil)
@@ -90,8 +92,8 @@ type WebArenaIndigoV1VmSSHKey struct {
 	Name      string `json:"name"`
 	Sshkey    string `json:"sshkey"`
 	Status    string `json:"status"`
-	CreatedAt string `json:"created_at"` //nolint:revive,stylecheck,tagliatelle
-	UpdatedAt string `json:"updated_at"` //nolint:revive,stylecheck,tagliatelle
+	CreatedAt Time   `json:"created_at"` //nolint:revive,stylecheck,tagliatelle
+	UpdatedAt Time   `json:"updated_at"` //nolint:revive,stylecheck,tagliatelle
 }
 
 // Create SSH Key
@@ -130,7 +132,7 @@ type WebArenaIndigoV1VmSSHKey struct {
 //	    }
 //	}
 func (c *Client) CreateWebArenaIndigoV1VmSSHKey(ctx context.Context, req *CreateWebArenaIndigoV1VmSSHKeyRequest) (*CreateWebArenaIndigoV1VmSSHKeyResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -202,7 +204,7 @@ type CreateWebArenaIndigoV1VmSSHKeyResponse struct {
 //	    ]
 //	}
 func (c *Client) RetrieveWebArenaIndigoV1VmSSHKey(ctx context.Context, sshKeyID int64) (*RetrieveWebArenaIndigoV1VmSSHKeyResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeySSHKeyID.Int64(sshKeyID)))
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, path.Join(PathWebArenaIndigoV1VmSSHKey, strconv.FormatInt(sshKeyID, 10)), nil) //nolint:staticcheck
@@ -256,7 +258,7 @@ type RetrieveWebArenaIndigoV1VmSSHKeyResponse struct {
 //	    "message": "SSH key has been updated successfully"
 //	}
 func (c *Client) UpdateWebArenaIndigoV1VmSSHKey(ctx context.Context, id int64, req *UpdateWebArenaIndigoV1VmSSHKeyRequest) (*UpdateWebArenaIndigoV1VmSSHKeyResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeySSHKeyID.Int64(id)))
 	defer span.End()
 
 	body, err := json.Marshal(req)
@@ -317,7 +319,7 @@ type UpdateWebArenaIndigoV1VmSSHKeyResponse struct {
 //	    "message": "SSH key has been removed successfully"
 //	}
 func (c *Client) DestroyWebArenaIndigoV1VmSSHKey(ctx context.Context, sshKeyID int64) (*DestroyWebArenaIndigoV1VmSSHKeyResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeySSHKeyID.Int64(sshKeyID)))
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodDelete, path.Join(PathWebArenaIndigoV1VmSSHKey, strconv.FormatInt(sshKeyID, 10)), nil) //nolint:staticcheck
@@ -59,7 +59,7 @@ type WebArenaIndigoV1VmSSHKeyActiveStatusResponse struct {
 //	    ]
 //	}
 func (c *Client) GetWebArenaIndigoV1VmSSHKeyActiveStatus(ctx context.Context) (*WebArenaIndigoV1VmSSHKeyActiveStatusResponse, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	httpReq, err := c.newRequest(ctx, http.MethodGet, PathWebArenaIndigoV1VmSSHKeyActiveStatus, nil)
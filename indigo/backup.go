@@ -0,0 +1,160 @@
+package indigo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// BackupStep is a step of BackupInstance.
+type BackupStep string
+
+const (
+	// BackupStepStop looks up the instance, stops it if it is running, and waits for it to be "shutoff".
+	BackupStepStop BackupStep = "stop"
+	// BackupStepSnapshot takes a snapshot of the instance and waits for it to be "created".
+	BackupStepSnapshot BackupStep = "snapshot"
+	// BackupStepStart starts the instance if it was running before the backup, and waits for it to be "running".
+	BackupStepStart BackupStep = "start"
+)
+
+// BackupError is the error returned by BackupInstance. It reports the step that failed.
+//
+// If the instance was running before the backup and BackupInstance has requested to stop it,
+// BackupInstance starts it again even if a later step fails, e.g. the wait for the instance to stop times out,
+// and RestartErr reports the error of that restart.
+type BackupError struct {
+	Step       BackupStep
+	InstanceID int64
+	Err        error
+	// RestartErr is the error of starting the instance again after the failure, or nil if it succeeded or was not needed.
+	RestartErr error
+}
+
+func (e *BackupError) Error() string {
+	if e.RestartErr != nil {
+		return fmt.Sprintf("indigo: backup step=%s instanceID=%d: %v (restart: %v)", e.Step, e.InstanceID, e.Err, e.RestartErr)
+	}
+	return fmt.Sprintf("indigo: backup step=%s instanceID=%d: %v", e.Step, e.InstanceID, e.Err)
+}
+
+func (e *BackupError) Unwrap() []error {
+	if e.RestartErr != nil {
+		return []error{e.Err, e.RestartErr}
+	}
+	return []error{e.Err}
+}
+
+// BackupInstance takes a consistent snapshot of the instance named snapshotName, and returns the created snapshot.
+//
+// It stops the instance if it is running, takes a snapshot and waits for it to be "created",
+// and then starts the instance again if it was running. Each wait follows the WaitPolicy of the client, or the one set by ContextWithWaitPolicy.
+//
+// If a step fails, it returns a *BackupError that reports the step. It starts the instance again if it has requested to stop the instance,
+// even if ctx is canceled, so that the instance is not left stopped by a failed backup.
+//
+// Example:
+//
+//	snapshot, err := client.BackupInstance(ctx, 16, "nightly-"+time.Now().Format("20060102"))
+//	var backupErr *indigo.BackupError
+//	if errors.As(err, &backupErr) {
+//		log.Printf("backup failed at %s: %v", backupErr.Step, backupErr.Err)
+//	}
+func (c *Client) BackupInstance(ctx context.Context, instanceID int64, snapshotName string) (*WebArenaIndigoV1DiskSnapshot, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(instanceID)))
+	defer span.End()
+
+	fail := func(step BackupStep, err error, restart bool) (*WebArenaIndigoV1DiskSnapshot, error) {
+		backupErr := &BackupError{Step: step, InstanceID: instanceID, Err: err}
+		if restart {
+			// NOTE: Restart the instance even if ctx is done, so that the instance is left as it was before the backup.
+			if err := c.restartInstance(context.WithoutCancel(ctx), instanceID); err != nil {
+				backupErr.RestartErr = errorz.Errorf("c.restartInstance: %w", err)
+			}
+		}
+		recordError(span, backupErr)
+		return nil, backupErr
+	}
+
+	// stop
+	instances, err := c.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+	if err != nil {
+		return fail(BackupStepStop, errorz.Errorf("c.GetWebArenaIndigoV1VmGetInstanceList: %w", err), false)
+	}
+	i := slices.IndexFunc(instances, func(instance WebArenaIndigoV1VmInstance) bool { return instance.ID == instanceID })
+	if i < 0 {
+		return fail(BackupStepStop, errorz.Errorf("instanceID=%d: %w", instanceID, ErrNotFound), false)
+	}
+	wasRunning := false
+	switch status := instances[i].Status; status {
+	case InstanceStatusRunning:
+		wasRunning = true
+		if _, err := c.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{
+			InstanceID: instanceID,
+			Status:     InstanceActionStop,
+		}); err != nil {
+			return fail(BackupStepStop, errorz.Errorf("c.PostWebArenaIndigoV1VmInstanceStatusUpdate: %w", err), false)
+		}
+		// NOTE: The instance is stopping from here on, so start it again even if the wait fails, e.g. because it has timed out.
+		if _, err := c.WaitForInstanceStatus(ctx, instanceID, InstanceStatusShutoff); err != nil {
+			return fail(BackupStepStop, errorz.Errorf("c.WaitForInstanceStatus: %w", err), true)
+		}
+	case InstanceStatusShutoff, InstanceStatusUnused:
+		// NOTE: The instance is already stopped.
+	default:
+		return fail(BackupStepStop, errorz.Errorf("instanceID=%d status=%s: instance is neither running nor stopped: %w", instanceID, status, ErrInvalidArgument), false)
+	}
+
+	// snapshot
+	snapshot, err := c.TakeSnapshotAndWait(ctx, &PostWebArenaIndigoV1DiskTakeSnapshotRequest{Name: snapshotName, InstanceID: instanceID, SlotNum: 0})
+	if err != nil {
+		return fail(BackupStepSnapshot, errorz.Errorf("c.TakeSnapshotAndWait: %w", err), wasRunning)
+	}
+
+	// start
+	if wasRunning {
+		if err := c.startInstanceAndWait(ctx, instanceID); err != nil {
+			return fail(BackupStepStart, errorz.Errorf("c.startInstanceAndWait: %w", err), false)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// restartInstance starts the instance that BackupInstance has stopped, and waits for it to be "running".
+// It waits for the instance to be "shutoff" first, because the stop may still be in progress if the wait for it has failed.
+func (c *Client) restartInstance(ctx context.Context, instanceID int64) error {
+	if _, err := c.WaitForInstanceStatus(ctx, instanceID, InstanceStatusShutoff); err != nil {
+		return errorz.Errorf("c.WaitForInstanceStatus: %w", err)
+	}
+	if err := c.startInstanceAndWait(ctx, instanceID); err != nil {
+		return errorz.Errorf("c.startInstanceAndWait: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) startInstanceAndWait(ctx context.Context, instanceID int64) error {
+	return c.updateInstanceStatusAndWait(ctx, instanceID, InstanceActionStart)
+}
+
+// updateInstanceStatusAndWait requests the action for the instance, and waits for the instance to reach the target status of the action.
+// The caller must have checked that the action is valid for the current status of the instance.
+func (c *Client) updateInstanceStatusAndWait(ctx context.Context, instanceID int64, action InstanceAction) error {
+	if _, err := c.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{
+		InstanceID: instanceID,
+		Status:     action,
+	}); err != nil {
+		return errorz.Errorf("c.PostWebArenaIndigoV1VmInstanceStatusUpdate: %w", err)
+	}
+
+	if _, err := c.WaitForInstanceStatus(ctx, instanceID, action.Target()); err != nil {
+		return errorz.Errorf("c.WaitForInstanceStatus: %w", err)
+	}
+
+	return nil
+}
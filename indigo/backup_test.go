@@ -0,0 +1,174 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func testInstanceStatus(ctx context.Context, tb testing.TB, client *Client, id int64) InstanceStatus {
+	tb.Helper()
+
+	instances, err := client.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+	requirez.NoError(tb, err)
+	i := slices.IndexFunc(instances, func(instance WebArenaIndigoV1VmInstance) bool { return instance.ID == id })
+	requirez.True(tb, i >= 0)
+
+	return instances[i].Status
+}
+
+//nolint:funlen
+func TestClient_BackupInstance(t *testing.T) {
+	t.Parallel()
+
+	const transitionDelay = 30 * time.Millisecond
+
+	t.Run("success,running", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(transitionDelay))
+		t.Cleanup(srv.Close)
+
+		recorder := &progressRecorder{}
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(recorder.record))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		instanceID := createTestInstance(ctx, t, client)
+		requirez.NoError(t, client.startInstanceAndWait(ctx, instanceID))
+
+		snapshot, err := client.BackupInstance(ctx, instanceID, "backup")
+		requirez.NoError(t, err)
+		requirez.Equal(t, "backup", snapshot.Name)
+		requirez.Equal(t, "created", snapshot.Status)
+		requirez.Equal(t, InstanceStatusRunning, testInstanceStatus(ctx, t, client, instanceID))
+		statuses := recorder.statuses()
+		requirez.True(t, slices.Contains(statuses, "stopping"))
+		requirez.True(t, slices.Contains(statuses, "pending"))
+	})
+
+	t.Run("success,stopped", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(transitionDelay))
+		t.Cleanup(srv.Close)
+
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(nil))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		instanceID := createTestInstance(ctx, t, client)
+
+		snapshot, err := client.BackupInstance(ctx, instanceID, "backup")
+		requirez.NoError(t, err)
+		requirez.Equal(t, "created", snapshot.Status)
+		requirez.Equal(t, InstanceStatusUnused, testInstanceStatus(ctx, t, client, instanceID))
+	})
+
+	t.Run("failure,snapshotFailed", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(transitionDelay))
+		t.Cleanup(srv.Close)
+
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(func(p WaitProgress) {
+			if p.Status == "pending" {
+				srv.SetSnapshotStatus(1, "failed")
+			}
+		}))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		instanceID := createTestInstance(ctx, t, client)
+		requirez.NoError(t, client.startInstanceAndWait(ctx, instanceID))
+
+		_, err = client.BackupInstance(ctx, instanceID, "backup")
+		var backupErr *BackupError
+		requirez.True(t, errors.As(err, &backupErr))
+		requirez.Equal(t, BackupStepSnapshot, backupErr.Step)
+		requirez.Equal(t, instanceID, backupErr.InstanceID)
+		requirez.NoError(t, backupErr.RestartErr)
+		requirez.ErrorIs(t, err, ErrWaitTerminalStatus)
+		requirez.Equal(t, InstanceStatusRunning, testInstanceStatus(ctx, t, client, instanceID))
+	})
+
+	t.Run("failure,restartFailed", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(transitionDelay))
+		t.Cleanup(srv.Close)
+
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(func(p WaitProgress) {
+			if p.Status == "pending" {
+				srv.SetSnapshotStatus(1, "failed")
+			}
+			if p.Status == "starting" {
+				srv.SetInstanceStatus(1, "error")
+			}
+		}))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		instanceID := createTestInstance(ctx, t, client)
+		requirez.True(t, srv.SetInstanceStatus(instanceID, "running"))
+
+		_, err = client.BackupInstance(ctx, instanceID, "backup")
+		var backupErr *BackupError
+		requirez.True(t, errors.As(err, &backupErr))
+		requirez.Equal(t, BackupStepSnapshot, backupErr.Step)
+		requirez.ErrorIs(t, backupErr.RestartErr, ErrWaitTerminalStatus)
+		requirez.ErrorContains(t, err, "restart")
+	})
+
+	t.Run("failure,stopWaitFailed", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(transitionDelay))
+		t.Cleanup(srv.Close)
+
+		var cancel context.CancelFunc
+		ctx, cancel := context.WithCancel(ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(func(p WaitProgress) {
+			if p.Status == "stopping" {
+				cancel()
+			}
+		})))
+		t.Cleanup(cancel)
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		instanceID := createTestInstance(ctx, t, client)
+		requirez.True(t, srv.SetInstanceStatus(instanceID, "running"))
+
+		_, err = client.BackupInstance(ctx, instanceID, "backup")
+		var backupErr *BackupError
+		requirez.True(t, errors.As(err, &backupErr))
+		requirez.Equal(t, BackupStepStop, backupErr.Step)
+		requirez.ErrorIs(t, err, context.Canceled)
+		// NOTE: The stop has been requested, so the instance is started again after it has stopped.
+		requirez.NoError(t, backupErr.RestartErr)
+		requirez.Equal(t, InstanceStatusRunning, testInstanceStatus(context.Background(), t, client, instanceID))
+	})
+
+	t.Run("failure,notFound", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(nil))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		_, err = client.BackupInstance(ctx, 999, "backup")
+		var backupErr *BackupError
+		requirez.True(t, errors.As(err, &backupErr))
+		requirez.Equal(t, BackupStepStop, backupErr.Step)
+		requirez.ErrorIs(t, err, ErrNotFound)
+	})
+}
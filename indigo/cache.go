@@ -0,0 +1,187 @@
+package indigo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// ResponseCache stores the response bodies of the cacheable endpoints for ClientOptionWithResponseCache.
+//
+// The cacheable endpoints are the GET endpoints of the catalog, which almost never changes:
+// GetWebArenaIndigoV1VmInstanceTypes, GetWebArenaIndigoV1VmGetRegion, GetWebArenaIndigoV1VmOSList and GetWebArenaIndigoV1VmInstanceSpec.
+//
+// NewMemoryResponseCache returns an in-memory ResponseCache.
+// Implement ResponseCache to store the bodies elsewhere, e.g. on disk to share them between runs of a CLI,
+// or in a shared store to share them between processes. The implementation must be safe for concurrent use.
+//
+// The client treats an error of Get as a miss, and ignores an error of Set after recording it to the span,
+// so that a broken cache never fails the API calls.
+type ResponseCache interface {
+	// Get returns the body stored for key, or false if there is none or it has expired.
+	Get(ctx context.Context, key string) (body []byte, ok bool, err error)
+	// Set stores body for key. The body must expire after ttl.
+	Set(ctx context.Context, key string, body []byte, ttl time.Duration) error
+	// Clear drops all the bodies stored by the client.
+	Clear(ctx context.Context) error
+}
+
+type responseCacheOption struct {
+	cache ResponseCache
+	ttl   time.Duration
+}
+
+func (o *responseCacheOption) apply(c *Client) {
+	c.responseCache = o.cache
+	c.responseCacheTTL = o.ttl
+}
+
+// ClientOptionWithResponseCache caches the responses of the cacheable endpoints in cache for ttl. See ResponseCache.
+// By default, no response is cached.
+//
+// Example:
+//
+//	client, err := indigo.NewClient(ctx, indigo.ClientOptionWithResponseCache(indigo.NewMemoryResponseCache(), 24*time.Hour))
+func ClientOptionWithResponseCache(cache ResponseCache, ttl time.Duration) ClientOption { //nolint:ireturn
+	return &responseCacheOption{cache: cache, ttl: ttl}
+}
+
+type bypassCacheContextKey struct{}
+
+// ContextWithoutCache returns a copy of ctx that makes the calls with it bypass the caches,
+// i.e. the ResponseCache of ClientOptionWithResponseCache and the instance list of ClientOptionWithInstanceCache.
+// The fresh responses are still stored, so that the following calls see them.
+func ContextWithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheContextKey{}, true)
+}
+
+func bypassCache(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheContextKey{}).(bool)
+	return bypass
+}
+
+// InvalidateCache drops the responses cached by ClientOptionWithResponseCache and the instance list cached by ClientOptionWithInstanceCache.
+func (c *Client) InvalidateCache(ctx context.Context) error {
+	c.invalidateInstanceCache()
+
+	if c.responseCache == nil {
+		return nil
+	}
+	if err := c.responseCache.Clear(ctx); err != nil {
+		return errorz.Errorf("c.responseCache.Clear: %w", err)
+	}
+	return nil
+}
+
+// doCacheableRequest is doRequest for the cacheable endpoints.
+// It returns the cached body as a 200 OK response if there is one, and otherwise caches the body of a 2xx response.
+func (c *Client) doCacheableRequest(req *http.Request) (*http.Response, error) {
+	if c.responseCache == nil || req.Method != http.MethodGet {
+		return c.doRequest(req)
+	}
+
+	ctx := req.Context()
+	span := trace.SpanFromContext(ctx)
+	key := req.Method + " " + req.URL.String()
+
+	if !bypassCache(ctx) {
+		body, ok, err := c.responseCache.Get(ctx, key)
+		if err != nil {
+			span.RecordError(errorz.Errorf("c.responseCache.Get: %w", err))
+		}
+		if err == nil && ok {
+			span.SetAttributes(attributeKeyCacheHit.Bool(true))
+			return &http.Response{
+				Status:        strconv.Itoa(http.StatusOK) + " " + http.StatusText(http.StatusOK),
+				StatusCode:    http.StatusOK,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{"Content-Type": {"application/json"}},
+				Body:          io.NopCloser(bytes.NewReader(body)),
+				ContentLength: int64(len(body)),
+				Request:       req,
+			}, nil
+		}
+	}
+	span.SetAttributes(attributeKeyCacheHit.Bool(false))
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, errorz.Errorf("io.ReadAll: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := c.responseCache.Set(ctx, key, body, c.responseCacheTTL); err != nil {
+		span.RecordError(errorz.Errorf("c.responseCache.Set: %w", err))
+	}
+
+	return resp, nil
+}
+
+// MemoryResponseCache is an in-memory ResponseCache.
+type MemoryResponseCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryResponseCacheEntry
+	now     func() time.Time
+}
+
+type memoryResponseCacheEntry struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+var _ ResponseCache = (*MemoryResponseCache)(nil)
+
+// NewMemoryResponseCache returns an empty MemoryResponseCache.
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return &MemoryResponseCache{entries: make(map[string]memoryResponseCacheEntry), now: time.Now}
+}
+
+// Get implements ResponseCache.
+func (m *MemoryResponseCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !m.now().Before(entry.expiresAt) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return bytes.Clone(entry.body), true, nil
+}
+
+// Set implements ResponseCache.
+func (m *MemoryResponseCache) Set(_ context.Context, key string, body []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryResponseCacheEntry{body: bytes.Clone(body), expiresAt: m.now().Add(ttl)}
+	return nil
+}
+
+// Clear implements ResponseCache.
+func (m *MemoryResponseCache) Clear(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clear(m.entries)
+	return nil
+}
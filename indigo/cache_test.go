@@ -0,0 +1,217 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+// isCatalogPath reports whether path is of a catalog endpoint or the instance list.
+func isCatalogPath(path string) bool {
+	return slices.Contains([]string{
+		PathWebArenaIndigoV1VmInstanceTypes,
+		PathWebArenaIndigoV1VmInstanceType,
+		PathWebArenaIndigoV1VmOSList,
+		PathWebArenaIndigoV1VmInstanceSpec,
+		PathWebArenaIndigoV1VmGetInstanceList,
+	}, path)
+}
+
+type failingResponseCache struct{}
+
+var errTestCache = errors.New("cache is broken")
+
+func (failingResponseCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errTestCache
+}
+
+func (failingResponseCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errTestCache
+}
+
+func (failingResponseCache) Clear(context.Context) error { return errTestCache }
+
+//nolint:funlen
+func TestClient_responseCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,withoutCache", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, requested := newRequestCountingClient(ctx, t, isCatalogPath)
+
+		for range 2 {
+			_, err := client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+			requirez.NoError(t, err)
+		}
+		requirez.Equal(t, int64(2), requested.Path(PathWebArenaIndigoV1VmInstanceTypes))
+	})
+
+	t.Run("success,cache", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, requested := newRequestCountingClient(ctx, t, isCatalogPath, ClientOptionWithResponseCache(NewMemoryResponseCache(), time.Hour))
+
+		for range 2 {
+			types, err := client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+			requirez.NoError(t, err)
+			requirez.True(t, len(types.InstanceTypes) > 0)
+
+			regions, err := client.GetWebArenaIndigoV1VmGetRegion(ctx, 1)
+			requirez.NoError(t, err)
+			requirez.True(t, len(regions.RegionList) > 0)
+
+			_, err = client.GetWebArenaIndigoV1VmOSList(ctx, 1)
+			requirez.NoError(t, err)
+
+			specs, err := client.GetWebArenaIndigoV1VmInstanceSpec(ctx, 1, 1)
+			requirez.NoError(t, err)
+			requirez.True(t, len(specs.SpecList) > 0)
+
+			_, err = client.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+			requirez.NoError(t, err)
+		}
+		requirez.Equal(t, int64(1), requested.Path(PathWebArenaIndigoV1VmInstanceTypes))
+		requirez.Equal(t, int64(1), requested.Path(PathWebArenaIndigoV1VmInstanceType))
+		requirez.Equal(t, int64(1), requested.Path(PathWebArenaIndigoV1VmOSList))
+		requirez.Equal(t, int64(1), requested.Path(PathWebArenaIndigoV1VmInstanceSpec))
+		// NOTE: The instance list is not a cacheable endpoint.
+		requirez.Equal(t, int64(2), requested.Path(PathWebArenaIndigoV1VmGetInstanceList))
+
+		// NOTE: The query is a part of the key.
+		_, err := client.GetWebArenaIndigoV1VmGetRegion(ctx, 2)
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(2), requested.Path(PathWebArenaIndigoV1VmInstanceType))
+	})
+
+	t.Run("success,bypass", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, requested := newRequestCountingClient(ctx, t, isCatalogPath, ClientOptionWithResponseCache(NewMemoryResponseCache(), time.Hour))
+
+		_, err := client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+		requirez.NoError(t, err)
+		_, err = client.GetWebArenaIndigoV1VmInstanceTypes(ContextWithoutCache(ctx))
+		requirez.NoError(t, err)
+		_, err = client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(2), requested.Path(PathWebArenaIndigoV1VmInstanceTypes))
+	})
+
+	t.Run("success,invalidate", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, requested := newRequestCountingClient(ctx, t, isCatalogPath,
+			ClientOptionWithResponseCache(NewMemoryResponseCache(), time.Hour),
+			ClientOptionWithInstanceCache(time.Hour),
+		)
+
+		for range 2 {
+			_, err := client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+			requirez.NoError(t, err)
+			_, err = client.ListInstances(ctx, nil)
+			requirez.NoError(t, err)
+		}
+		requirez.Equal(t, int64(1), requested.Path(PathWebArenaIndigoV1VmInstanceTypes))
+		requirez.Equal(t, int64(1), requested.Path(PathWebArenaIndigoV1VmGetInstanceList))
+
+		requirez.NoError(t, client.InvalidateCache(ctx))
+
+		_, err := client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+		requirez.NoError(t, err)
+		_, err = client.ListInstances(ctx, nil)
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(2), requested.Path(PathWebArenaIndigoV1VmInstanceTypes))
+		requirez.Equal(t, int64(2), requested.Path(PathWebArenaIndigoV1VmGetInstanceList))
+
+		// NOTE: The bypass also applies to the instance list.
+		_, err = client.ListInstances(ContextWithoutCache(ctx), nil)
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(3), requested.Path(PathWebArenaIndigoV1VmGetInstanceList))
+	})
+
+	t.Run("success,brokenCache", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, requested := newRequestCountingClient(ctx, t, isCatalogPath, ClientOptionWithResponseCache(failingResponseCache{}, time.Hour))
+
+		for range 2 {
+			_, err := client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+			requirez.NoError(t, err)
+		}
+		requirez.Equal(t, int64(2), requested.Path(PathWebArenaIndigoV1VmInstanceTypes))
+
+		requirez.ErrorIs(t, client.InvalidateCache(ctx), errTestCache)
+	})
+
+	t.Run("failure,notCachedOnError", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		var failed atomic.Bool
+		stub := newStubServer(t, srv, func(w http.ResponseWriter, r *http.Request) bool {
+			if strings.HasPrefix(r.URL.Path, PathWebArenaIndigoV1VmInstanceTypes) && !failed.Swap(true) {
+				writeStubError(w, http.StatusBadRequest, "Bad Request.")
+				return true
+			}
+			return false
+		})
+
+		ctx := context.Background()
+		cache := NewMemoryResponseCache()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithEndpoint(stub.URL), ClientOptionWithResponseCache(cache, time.Hour))...)
+		requirez.NoError(t, err)
+
+		_, err = client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+		requirez.ErrorIs(t, err, ErrUnexpectedStatusCode)
+
+		types, err := client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+		requirez.NoError(t, err)
+		requirez.True(t, len(types.InstanceTypes) > 0)
+	})
+}
+
+func TestMemoryResponseCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 12, 13, 41, 52, 0, time.UTC)
+	cache := NewMemoryResponseCache()
+	cache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	body := []byte(`{"success":true}`)
+	requirez.NoError(t, cache.Set(ctx, "key", body, time.Minute))
+	body[0] = 'x'
+
+	got, ok, err := cache.Get(ctx, "key")
+	requirez.NoError(t, err)
+	requirez.True(t, ok)
+	requirez.Equal(t, `{"success":true}`, string(got))
+
+	now = now.Add(time.Minute)
+	_, ok, err = cache.Get(ctx, "key")
+	requirez.NoError(t, err)
+	requirez.False(t, ok)
+
+	requirez.NoError(t, cache.Set(ctx, "key", body, time.Minute))
+	requirez.NoError(t, cache.Clear(ctx))
+	_, ok, err = cache.Get(ctx, "key")
+	requirez.NoError(t, err)
+	requirez.False(t, ok)
+}
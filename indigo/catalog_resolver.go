@@ -0,0 +1,310 @@
+package indigo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// defaultInstanceTypeName is the instance type of CatalogQuery if it is empty, i.e. the Linux instances.
+const defaultInstanceTypeName = "instance"
+
+// CatalogQuery names what an instance is created with, to be resolved into the IDs by ResolveCatalog.
+//
+// Each name is matched ignoring case, spaces and punctuation, so "ubuntu22.04" matches "Ubuntu 22.04".
+// A name that is not an exact match matches the entry that contains it, e.g. "22.04" matches "Ubuntu 22.04",
+// as long as only one entry contains it. A number matches the entry of the ID.
+type CatalogQuery struct {
+	// InstanceType is the name or the display name of the instance type, e.g. "instance", "winserver" or "KVM Instance".
+	// If empty, "instance" is used.
+	InstanceType string
+	// Region is the name of the region, e.g. "Tokyo".
+	Region string
+	// OS is the name or the view name of the OS, e.g. "Ubuntu 22.04".
+	OS string
+	// Plan is the plan code, e.g. "2CR2GB", or the name of the instance spec, e.g. "2 CPU & 2 GB RAM plan".
+	Plan string
+}
+
+// ResolvedCatalog is the IDs of the catalog entries that a CatalogQuery names.
+type ResolvedCatalog struct {
+	InstanceTypeID int64
+	RegionID       int64
+	OsID           int64
+	InstancePlan   int64
+}
+
+// CreateInstanceRequest returns the request to create a Linux instance with the resolved IDs.
+func (r *ResolvedCatalog) CreateInstanceRequest(instanceName string, sshKeyID int64) *PostWebArenaIndigoV1VmCreateInstanceRequest {
+	return &PostWebArenaIndigoV1VmCreateInstanceRequest{
+		SshKeyID:     sshKeyID,
+		RegionID:     r.RegionID,
+		OsID:         r.OsID,
+		InstancePlan: r.InstancePlan,
+		InstanceName: instanceName,
+	}
+}
+
+// CreateWindowsInstanceRequest returns the request to create a Windows instance with the resolved IDs.
+func (r *ResolvedCatalog) CreateWindowsInstanceRequest(instanceName, winPassword string) *PostWebArenaIndigoV1VmCreateWindowsInstanceRequest {
+	return &PostWebArenaIndigoV1VmCreateWindowsInstanceRequest{
+		WinPassword:  winPassword,
+		RegionID:     r.RegionID,
+		OsID:         r.OsID,
+		InstancePlan: r.InstancePlan,
+		InstanceName: instanceName,
+	}
+}
+
+// CatalogMatchError is returned by ResolveCatalog when a name of CatalogQuery matches no entry or more than one entry of the catalog.
+// It satisfies errors.Is for ErrNotFound if no entry matches, or ErrMultipleFound otherwise.
+type CatalogMatchError struct {
+	// Kind is what is resolved: "instance type", "region", "OS" or "plan".
+	Kind string
+	// Query is the name in CatalogQuery.
+	Query string
+	// Scope is what the entries are listed for, e.g. `instance type "KVM Instance"`. It is empty for the instance types.
+	Scope string
+	// Candidates are the entries that matched Query. It is empty if no entry matched.
+	Candidates []string
+	// Options are all the entries, i.e. the valid names.
+	Options []string
+}
+
+func (e *CatalogMatchError) Error() string {
+	scope := ""
+	if e.Scope != "" {
+		scope = " for " + e.Scope
+	}
+	if len(e.Candidates) > 0 {
+		return fmt.Sprintf("indigo: %s %q is ambiguous%s; it matches %s", e.Kind, e.Query, scope, quoteJoin(e.Candidates))
+	}
+	if len(e.Options) == 0 {
+		return fmt.Sprintf("indigo: %s %q is not available%s; there are no options", e.Kind, e.Query, scope)
+	}
+	return fmt.Sprintf("indigo: %s %q is not available%s; valid options: %s", e.Kind, e.Query, scope, quoteJoin(e.Options))
+}
+
+func (e *CatalogMatchError) Unwrap() error {
+	if len(e.Candidates) > 0 {
+		return ErrMultipleFound
+	}
+	return ErrNotFound
+}
+
+func quoteJoin(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// ResolveCatalog resolves the names of query into the IDs to create an instance with.
+// It returns ErrInvalidArgument if Region, OS or Plan is empty, and a *CatalogMatchError if a name does not resolve to one entry,
+// e.g. if the OS is not available for the instance type or the plan is not available for the OS.
+//
+// It calls GetWebArenaIndigoV1VmInstanceTypes, GetWebArenaIndigoV1VmGetRegion, GetWebArenaIndigoV1VmOSList and GetWebArenaIndigoV1VmInstanceSpec.
+// Use ClientOptionWithResponseCache to resolve many queries without spending the quota of the API.
+//
+// Example:
+//
+//	resolved, err := client.ResolveCatalog(ctx, &indigo.CatalogQuery{Region: "Tokyo", OS: "Ubuntu 22.04", Plan: "2CR2GB"})
+//	if err != nil {
+//		return err
+//	}
+//	created, err := client.PostWebArenaIndigoV1VmCreateInstance(ctx, resolved.CreateInstanceRequest("web-1", sshKeyID))
+//
+//nolint:funlen,cyclop
+func (c *Client) ResolveCatalog(ctx context.Context, query *CatalogQuery) (*ResolvedCatalog, error) {
+	ctx, span := c.start(ctx)
+	defer span.End()
+
+	if query == nil {
+		query = &CatalogQuery{}
+	}
+	for _, field := range []struct{ name, value string }{{"region", query.Region}, {"os", query.OS}, {"plan", query.Plan}} {
+		if strings.TrimSpace(field.value) == "" {
+			err := errorz.Errorf("%s is empty: %w", field.name, ErrInvalidArgument)
+			recordError(span, err)
+			return nil, err
+		}
+	}
+	instanceTypeQuery := query.InstanceType
+	if strings.TrimSpace(instanceTypeQuery) == "" {
+		instanceTypeQuery = defaultInstanceTypeName
+	}
+
+	types, err := c.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmInstanceTypes: %w", err)
+	}
+	instanceType, err := matchCatalog("instance type", instanceTypeQuery, "", types.InstanceTypes, instanceTypeNames)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("matchCatalog: %w", err)
+	}
+	typeScope := fmt.Sprintf("instance type %q", instanceTypeNames(instanceType)[0])
+
+	regions, err := c.GetWebArenaIndigoV1VmGetRegion(ctx, instanceType.ID)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmGetRegion: %w", err)
+	}
+	region, err := matchCatalog("region", query.Region, typeScope, regions.RegionList, regionNames)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("matchCatalog: %w", err)
+	}
+
+	osList, err := c.GetWebArenaIndigoV1VmOSList(ctx, instanceType.ID)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmOSList: %w", err)
+	}
+	var oses []OS
+	for _, category := range osList.OsCategory {
+		oses = append(oses, category.OsLists...)
+	}
+	os, err := matchCatalog("OS", query.OS, typeScope, oses, osNames)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("matchCatalog: %w", err)
+	}
+
+	specs, err := c.GetWebArenaIndigoV1VmInstanceSpec(ctx, instanceType.ID, os.ID)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmInstanceSpec: %w", err)
+	}
+	osScope := fmt.Sprintf("OS %q of %s", osNames(os)[0], typeScope)
+	spec, err := matchCatalog("plan", query.Plan, osScope, specs.SpecList, specNames)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("matchCatalog: %w", err)
+	}
+
+	return &ResolvedCatalog{
+		InstanceTypeID: instanceType.ID,
+		RegionID:       region.ID,
+		OsID:           os.ID,
+		InstancePlan:   spec.ID,
+	}, nil
+}
+
+// catalogEntry is an entry of the catalog that matchCatalog matches a name against.
+type catalogEntry interface {
+	InstanceType | Region | OS | InstanceSpec
+}
+
+// matchCatalog returns the entry that query names. names returns the names of an entry, the first of which is shown in the errors.
+// The entries are matched in order by the ID, by the whole name, and by a part of the name.
+func matchCatalog[T catalogEntry](kind, query, scope string, entries []T, names func(entry T) []string) (T, error) {
+	var zero T
+
+	display := func(matched []T) []string {
+		shown := make([]string, 0, len(matched))
+		for _, entry := range matched {
+			shown = append(shown, names(entry)[0])
+		}
+		return shown
+	}
+
+	if id, err := strconv.ParseInt(strings.TrimSpace(query), 10, 64); err == nil {
+		for _, entry := range entries {
+			if catalogEntryID(entry) == id {
+				return entry, nil
+			}
+		}
+	}
+
+	normalized := normalizeCatalogName(query)
+	for _, match := range []func(name string) bool{
+		func(name string) bool { return name == normalized },
+		func(name string) bool { return normalized != "" && strings.Contains(name, normalized) },
+	} {
+		var matched []T
+		for _, entry := range entries {
+			for _, name := range names(entry) {
+				if match(normalizeCatalogName(name)) {
+					matched = append(matched, entry)
+					break
+				}
+			}
+		}
+		switch len(matched) {
+		case 0:
+			continue
+		case 1:
+			return matched[0], nil
+		default:
+			return zero, &CatalogMatchError{Kind: kind, Query: query, Scope: scope, Candidates: display(matched), Options: display(entries)}
+		}
+	}
+
+	return zero, &CatalogMatchError{Kind: kind, Query: query, Scope: scope, Options: display(entries)}
+}
+
+func catalogEntryID[T catalogEntry](entry T) int64 {
+	switch entry := any(entry).(type) {
+	case InstanceType:
+		return entry.ID
+	case Region:
+		return entry.ID
+	case OS:
+		return entry.ID
+	case InstanceSpec:
+		return entry.ID
+	default:
+		return 0
+	}
+}
+
+// normalizeCatalogName lowercases name and drops all but the letters and the digits, so that "Ubuntu 22.04" and "ubuntu2204" are the same.
+func normalizeCatalogName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+func instanceTypeNames(instanceType InstanceType) []string {
+	if instanceType.DisplayName == "" {
+		return []string{instanceType.Name}
+	}
+	return []string{instanceType.DisplayName, instanceType.Name}
+}
+
+func regionNames(region Region) []string { return []string{region.Name} }
+
+func osNames(os OS) []string {
+	if os.ViewName == "" {
+		return []string{os.Name}
+	}
+	return []string{os.ViewName, os.Name}
+}
+
+// specPlanPattern matches the name of an instance spec such as "2 CPU & 2 GB RAM plan".
+//
+//nolint:gochecknoglobals
+var specPlanPattern = regexp.MustCompile(`(?i)(\d+)\s*CPU\D*?(\d+)\s*GB`)
+
+// specNames returns the plan code that the instances report, e.g. "2CR2GB", derived from the name of spec, and the name itself.
+func specNames(spec InstanceSpec) []string {
+	names := []string{spec.Name}
+	if m := specPlanPattern.FindStringSubmatch(spec.Name); m != nil {
+		names = append([]string{m[1] + "CR" + m[2] + "GB"}, names...)
+	}
+	if spec.Description != "" && spec.Description != spec.Name {
+		names = append(names, spec.Description)
+	}
+	return names
+}
@@ -0,0 +1,144 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+)
+
+//nolint:funlen
+func TestClient_ResolveCatalog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+
+		tests := []struct {
+			name  string
+			query *CatalogQuery
+			want  *ResolvedCatalog
+		}{
+			{
+				name:  "exact",
+				query: &CatalogQuery{Region: "Tokyo", OS: "Ubuntu 22.04", Plan: "2CR2GB"},
+				want:  &ResolvedCatalog{InstanceTypeID: 1, RegionID: 1, OsID: 2, InstancePlan: 2},
+			},
+			{
+				name:  "fuzzy",
+				query: &CatalogQuery{InstanceType: "kvm instance", Region: "tokyo1", OS: "ubuntu18.04", Plan: "4 cpu & 4 gb ram plan"},
+				want:  &ResolvedCatalog{InstanceTypeID: 1, RegionID: 2, OsID: 1, InstancePlan: 3},
+			},
+			{
+				name:  "partial",
+				query: &CatalogQuery{Region: "Tokyo", OS: "centos", Plan: "16GB"},
+				want:  &ResolvedCatalog{InstanceTypeID: 1, RegionID: 1, OsID: 3, InstancePlan: 5},
+			},
+			{
+				name:  "id",
+				query: &CatalogQuery{Region: "2", OS: "3", Plan: "1"},
+				want:  &ResolvedCatalog{InstanceTypeID: 1, RegionID: 2, OsID: 3, InstancePlan: 1},
+			},
+			{
+				name:  "windows",
+				query: &CatalogQuery{InstanceType: "winserver", Region: "Tokyo", OS: "Windows Server 2019", Plan: "4CR8GB"},
+				want:  &ResolvedCatalog{InstanceTypeID: 2, RegionID: 1, OsID: 10, InstancePlan: 11},
+			},
+		}
+		for _, tt := range tests {
+			got, err := client.ResolveCatalog(ctx, tt.query)
+			requirez.NoError(t, err)
+			requirez.Equal(t, tt.want, got)
+		}
+
+		resolved, err := client.Catalog.Resolve(ctx, &CatalogQuery{Region: "Tokyo", OS: "Ubuntu 22.04", Plan: "2CR2GB"})
+		requirez.NoError(t, err)
+		created, err := client.Instances.Create(ctx, resolved.CreateInstanceRequest("web-1", 0))
+		requirez.NoError(t, err)
+		requirez.Equal(t, "2CR2GB", created.Plan)
+		requirez.Equal(t, int64(2), created.OsID)
+	})
+
+	t.Run("success,cache", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, requested := newRequestCountingClient(ctx, t, isCatalogPath, ClientOptionWithResponseCache(NewMemoryResponseCache(), time.Hour))
+
+		for range 3 {
+			_, err := client.ResolveCatalog(ctx, &CatalogQuery{Region: "Tokyo", OS: "Ubuntu 22.04", Plan: "2CR2GB"})
+			requirez.NoError(t, err)
+		}
+		requirez.Equal(t, int64(1), requested.Path(PathWebArenaIndigoV1VmInstanceTypes))
+		requirez.Equal(t, int64(1), requested.Path(PathWebArenaIndigoV1VmInstanceSpec))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+
+		tests := []struct {
+			name        string
+			query       *CatalogQuery
+			wantErr     error
+			wantMessage string
+		}{
+			{
+				name:    "nil",
+				query:   nil,
+				wantErr: ErrInvalidArgument,
+			},
+			{
+				name:    "emptyPlan",
+				query:   &CatalogQuery{Region: "Tokyo", OS: "Ubuntu 22.04"},
+				wantErr: ErrInvalidArgument,
+			},
+			{
+				name:        "instanceType",
+				query:       &CatalogQuery{InstanceType: "bare metal", Region: "Tokyo", OS: "Ubuntu 22.04", Plan: "2CR2GB"},
+				wantErr:     ErrNotFound,
+				wantMessage: `indigo: instance type "bare metal" is not available; valid options: "KVM Instance", "Windows Server Instance"`,
+			},
+			{
+				name:        "region",
+				query:       &CatalogQuery{Region: "Osaka", OS: "Ubuntu 22.04", Plan: "2CR2GB"},
+				wantErr:     ErrNotFound,
+				wantMessage: `indigo: region "Osaka" is not available for instance type "KVM Instance"; valid options: "Tokyo", "Tokyo1"`,
+			},
+			{
+				name:        "osNotForInstanceType",
+				query:       &CatalogQuery{Region: "Tokyo", OS: "Windows Server 2019", Plan: "2CR2GB"},
+				wantErr:     ErrNotFound,
+				wantMessage: `indigo: OS "Windows Server 2019" is not available for instance type "KVM Instance"; valid options: "Ubuntu 18.04", "Ubuntu 22.04", "CentOS 7.6"`,
+			},
+			{
+				name:        "ambiguousOS",
+				query:       &CatalogQuery{Region: "Tokyo", OS: "ubuntu", Plan: "2CR2GB"},
+				wantErr:     ErrMultipleFound,
+				wantMessage: `indigo: OS "ubuntu" is ambiguous for instance type "KVM Instance"; it matches "Ubuntu 18.04", "Ubuntu 22.04"`,
+			},
+			{
+				name:        "planNotForOS",
+				query:       &CatalogQuery{InstanceType: "winserver", Region: "Tokyo", OS: "Windows Server 2019", Plan: "2CR2GB"},
+				wantErr:     ErrNotFound,
+				wantMessage: `indigo: plan "2CR2GB" is not available for OS "Windows Server 2019" of instance type "Windows Server Instance"; valid options: "4CR8GB"`,
+			},
+		}
+		for _, tt := range tests {
+			_, err := client.ResolveCatalog(ctx, tt.query)
+			requirez.ErrorIs(t, err, tt.wantErr)
+			if tt.wantMessage != "" {
+				var matchErr *CatalogMatchError
+				requirez.True(t, errors.As(err, &matchErr))
+				requirez.Equal(t, tt.wantMessage, matchErr.Error())
+			}
+		}
+	})
+}
@@ -3,19 +3,23 @@ package indigo
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"os"
-	"strconv"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 
 	"github.com/hakadoriya/z.go/envz"
 	"github.com/hakadoriya/z.go/errorz"
-	"github.com/hakadoriya/z.go/retryz"
 )
 
 type (
@@ -27,8 +31,26 @@ type (
 		IssuedAt    time.Time     `json:"issuedAt"`
 	}
 
+	// Client is a client for the WebARENA Indigo API.
+	//
+	// Client is safe for concurrent use by multiple goroutines.
+	// The access token is shared between the goroutines and refreshed by only one of them at a time.
 	Client struct {
-		debugLog     *log.Logger
+		// Instances, SSHKeys, Firewalls, Snapshots, APIKeys and Catalog operate the resources with List, Get, Create, Update and Delete,
+		// on top of the methods named after the API paths such as GetWebArenaIndigoV1VmGetInstanceList.
+		// They are interfaces, so they can be replaced with mocks in tests.
+		Instances InstanceService
+		SSHKeys   SSHKeyService
+		Firewalls FirewallService
+		Snapshots SnapshotService
+		APIKeys   APIKeyService
+		Catalog   CatalogService
+
+		debugLog *log.Logger
+		// logger emits one structured record per attempt. If nil, no record is emitted.
+		logger *slog.Logger
+		// redactor masks credentials and tokens in the dumps written to debugLog and the bodies written to logger.
+		redactor     *Redactor
 		httpClient   *http.Client
 		endpoint     string
 		clientID     string
@@ -51,9 +73,26 @@ type (
 		//	X-Request-Id: 00000000-0000-4000-0000-000000000000
 		//
 		//	{"errorCode": "429", "errorMessage": "Too Many Request.", "developerMessage": "Rate limit quota violation. Quota limit  exceeded. Identifier : ffffffff-ffff-4fff-ffff-ffffffffffff", "moreInfo": null, "requestId": "ffffffff-ffff-ffff-ffff-fffffffffffffffffff"}
-		rateLimiter *rate.Limiter
-		retryConfig *retryz.Config
-		accessToken *AccessToken
+		rateLimiter rateLimiter
+		// lastQuota is the quota reported by the last response, or nil if no response has reported it.
+		lastQuota   atomic.Pointer[quota]
+		retryPolicy *RetryPolicy
+		waitPolicy  *WaitPolicy
+		// instanceCache caches the instance list for GetInstance and the like. If nil, the list is not cached.
+		instanceCache *instanceListCache
+		// responseCache caches the responses of the cacheable endpoints for responseCacheTTL. If nil, no response is cached.
+		responseCache    ResponseCache
+		responseCacheTTL time.Duration
+		// tokenRefreshSkew is how long before the access token expires it is refreshed.
+		tokenRefreshSkew time.Duration
+		// newTokenSource returns the source of access tokens. If nil, access tokens are issued via IssueAccessToken.
+		newTokenSource  func(c *Client) (TokenSource, error)
+		lazyAccessToken bool
+		tokenSource     *reuseTokenSource
+		// tracerProvider and meterProvider are the providers set by the options. If nil, the global providers are used.
+		tracerProvider trace.TracerProvider
+		meterProvider  metric.MeterProvider
+		telemetry      *telemetry
 	}
 
 	ClientOption interface {
@@ -65,6 +104,8 @@ type debugLogOption struct{ debugLog *log.Logger }
 
 func (o *debugLogOption) apply(c *Client) { c.debugLog = o.debugLog }
 
+// ClientOptionWithDebugLog writes the dumps of the requests and responses to debugLog.
+// Credentials and tokens in the dumps are redacted, see ClientOptionWithRedactor.
 func ClientOptionWithDebugLog(debugLog *log.Logger) ClientOption { //nolint:ireturn
 	return &debugLogOption{debugLog: debugLog}
 }
@@ -105,7 +146,7 @@ func ClientOptionWithEndpoint(endpoint string) ClientOption { //nolint:ireturn
 	return &endpointOption{endpoint: endpoint}
 }
 
-type rateLimiterOption struct{ rateLimiter *rate.Limiter }
+type rateLimiterOption struct{ rateLimiter rateLimiter }
 
 func (o *rateLimiterOption) apply(c *Client) {
 	c.rateLimiter = o.rateLimiter
@@ -115,6 +156,64 @@ func ClientOptionWithoutRateLimiter() ClientOption { //nolint:ireturn
 	return &rateLimiterOption{rateLimiter: rate.NewLimiter(rate.Inf, 0)}
 }
 
+// ClientOptionWithQuotaRateLimiter replaces the default rate limiter, which allows 1 request every 10 seconds,
+// with a rate limiter driven by the X-Quota-Available and X-Quota-Reset response headers.
+// It spends the available quota in a burst, and waits until X-Quota-Reset once the quota reaches 0.
+func ClientOptionWithQuotaRateLimiter() ClientOption { //nolint:ireturn
+	return &rateLimiterOption{rateLimiter: newQuotaRateLimiter()}
+}
+
+type tokenRefreshSkewOption struct{ tokenRefreshSkew time.Duration }
+
+func (o *tokenRefreshSkewOption) apply(c *Client) { c.tokenRefreshSkew = o.tokenRefreshSkew }
+
+// ClientOptionWithTokenRefreshSkew sets how long before IssuedAt+ExpiresIn the access token is refreshed. The default is 1 minute.
+func ClientOptionWithTokenRefreshSkew(tokenRefreshSkew time.Duration) ClientOption { //nolint:ireturn
+	return &tokenRefreshSkewOption{tokenRefreshSkew: tokenRefreshSkew}
+}
+
+type tokenSourceOption struct {
+	newTokenSource func(c *Client) (TokenSource, error)
+}
+
+func (o *tokenSourceOption) apply(c *Client) { c.newTokenSource = o.newTokenSource }
+
+// ClientOptionWithTokenSource replaces IssueAccessToken with tokenSource as the source of access tokens.
+// The client credentials are not required with this option.
+func ClientOptionWithTokenSource(tokenSource TokenSource) ClientOption { //nolint:ireturn
+	return &tokenSourceOption{newTokenSource: func(*Client) (TokenSource, error) { return tokenSource, nil }}
+}
+
+// ClientOptionWithFileTokenCache caches the access token issued via IssueAccessToken in path with 0600 permissions,
+// and reuses it until it expires, even across processes. See FileTokenSource.
+// If path is empty, the path returned by DefaultTokenCachePath is used.
+func ClientOptionWithFileTokenCache(path string) ClientOption { //nolint:ireturn
+	return &tokenSourceOption{newTokenSource: func(c *Client) (TokenSource, error) {
+		if err := c.validateClientCredentials(); err != nil {
+			return nil, errorz.Errorf("c.validateClientCredentials: %w", err)
+		}
+		cachePath := path
+		if cachePath == "" {
+			defaultPath, err := DefaultTokenCachePath(c.endpoint, c.clientID)
+			if err != nil {
+				return nil, errorz.Errorf("DefaultTokenCachePath: %w", err)
+			}
+			cachePath = defaultPath
+		}
+		return NewFileTokenSource(cachePath, TokenSourceFunc(c.IssueAccessToken), c.tokenRefreshSkew), nil
+	}}
+}
+
+type lazyAccessTokenOption struct{}
+
+func (o *lazyAccessTokenOption) apply(c *Client) { c.lazyAccessToken = true }
+
+// ClientOptionWithLazyAccessToken makes NewClient skip getting an access token.
+// The access token is got on the first API call instead.
+func ClientOptionWithLazyAccessToken() ClientOption { //nolint:ireturn
+	return &lazyAccessTokenOption{}
+}
+
 func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 	// rate limit
 	const (
@@ -122,39 +221,68 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 		defaultRateLimitBurst    = 1
 	)
 
-	// retry
+	// access token
 	const (
-		defaultInitialRetryInterval = 1 * time.Second
-		defaultMaxRetryInterval     = 10 * time.Second
+		defaultTokenRefreshSkew = 1 * time.Minute
 	)
 
 	c := &Client{
 		debugLog:     log.New(io.Discard, "", log.LstdFlags),
+		redactor:     DefaultRedactor(),
 		httpClient:   http.DefaultClient,
 		endpoint:     envz.StringOrDefault(WEBARENA_INDIGO_ENDPOINT, "https://api.customer.jp"),
 		clientID:     os.Getenv(WEBARENA_INDIGO_CLIENT_ID),
 		clientSecret: os.Getenv(WEBARENA_INDIGO_CLIENT_SECRET),
 		rateLimiter:  rate.NewLimiter(rate.Every(defaultRateLimitInterval), defaultRateLimitBurst),
-		retryConfig:  retryz.NewConfig(defaultInitialRetryInterval, defaultMaxRetryInterval),
+		retryPolicy:  DefaultRetryPolicy(),
+		waitPolicy:   DefaultWaitPolicy(),
+
+		tokenRefreshSkew: defaultTokenRefreshSkew,
 	}
 
 	for _, opt := range opts {
 		opt.apply(c)
 	}
 
-	if c.clientID == "" || c.clientSecret == "" {
-		return nil, errorz.Errorf("clientId or clientSecret is empty: %w", ErrInvalidClientCredentials)
-	}
+	c.initServices()
 
-	accessToken, err := c.IssueAccessToken(ctx)
+	telemetry, err := newTelemetry(c.tracerProvider, c.meterProvider)
 	if err != nil {
-		return nil, errorz.Errorf("c.IssueAccessToken: %w", err)
+		return nil, errorz.Errorf("newTelemetry: %w", err)
+	}
+	c.telemetry = telemetry
+
+	var source TokenSource = TokenSourceFunc(c.IssueAccessToken)
+	if c.newTokenSource != nil {
+		s, err := c.newTokenSource(c)
+		if err != nil {
+			return nil, errorz.Errorf("c.newTokenSource: %w", err)
+		}
+		source = s
+	} else if err := c.validateClientCredentials(); err != nil {
+		return nil, errorz.Errorf("c.validateClientCredentials: %w", err)
+	}
+
+	c.tokenSource = newReuseTokenSource(source, c.tokenRefreshSkew)
+	if c.lazyAccessToken {
+		return c, nil
+	}
+
+	if _, err := c.tokenSource.Token(ctx); err != nil {
+		return nil, errorz.Errorf("c.tokenSource.Token: %w", err)
 	}
-	c.accessToken = accessToken
 
 	return c, nil
 }
 
+func (c *Client) validateClientCredentials() error {
+	if c.clientID == "" || c.clientSecret == "" {
+		return errorz.Errorf("clientId or clientSecret is empty: %w", ErrInvalidClientCredentials)
+	}
+
+	return nil
+}
+
 func (c *Client) newRequest(ctx context.Context, method string, urlSuffix string, reqBody []byte) (*http.Request, error) {
 	url := c.endpoint + urlSuffix
 
@@ -172,7 +300,7 @@ func (c *Client) newRequest(ctx context.Context, method string, urlSuffix string
 }
 
 func (c *Client) IssueAccessToken(ctx context.Context) (*AccessToken, error) {
-	ctx, span := start(ctx)
+	ctx, span := c.start(ctx)
 	defer span.End()
 
 	req := &PostOAuthV1AccessTokensRequest{
@@ -187,101 +315,189 @@ func (c *Client) IssueAccessToken(ctx context.Context) (*AccessToken, error) {
 		return nil, errorz.Errorf("c.PostOAuthV1AccessTokens: %w", err)
 	}
 
-	accessToken, err := c.convertAuthResponseToAccessToken(resp)
+	return c.convertAuthResponseToAccessToken(resp), nil
+}
+
+func (c *Client) convertAuthResponseToAccessToken(resp *PostOAuthV1AccessTokensResponse) *AccessToken {
+	return &AccessToken{
+		AccessToken: resp.AccessToken,
+		TokenType:   resp.TokenType,
+		ExpiresIn:   time.Duration(resp.ExpiresIn) * time.Second,
+		Scope:       resp.Scope,
+		IssuedAt:    resp.IssuedAt.Time,
+	}
+}
+
+// doRequest sends req with the access token, and records the error to the span of the API call.
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	resp, err := c.doRequestWithAccessToken(req)
 	if err != nil {
-		return nil, errorz.Errorf("c.convertResponseToAccessToken: %w", err)
+		recordError(trace.SpanFromContext(req.Context()), err)
+		return nil, err
 	}
 
-	return accessToken, nil
+	return resp, nil
 }
 
-func (c *Client) convertAuthResponseToAccessToken(resp *PostOAuthV1AccessTokensResponse) (*AccessToken, error) {
-	issuedAtUnixMilli, err := strconv.ParseInt(resp.IssuedAt, 10, 64) //nolint:mnd
+func (c *Client) doRequestWithAccessToken(req *http.Request) (*http.Response, error) {
+	accessToken, err := c.tokenSource.Token(req.Context())
 	if err != nil {
-		return nil, errorz.Errorf("strconv.ParseInt: IssuedAt=%s: %w", resp.IssuedAt, err)
+		return nil, errorz.Errorf("c.tokenSource.Token: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(withAccessToken(req, accessToken))
+	if err == nil || !errors.Is(err, ErrAPIReturnsUnauthorized) {
+		return resp, err
 	}
-	expiresIn, err := strconv.ParseInt(resp.ExpiresIn, 10, 64) //nolint:mnd
+
+	// NOTE: The server may revoke or expire the access token earlier than the local clock expects (e.g. the host clock drifts).
+	//       Re-issue the access token and replay the request exactly once.
+	c.tokenSource.invalidate(req.Context(), accessToken)
+	accessToken, err = c.tokenSource.Token(req.Context())
 	if err != nil {
-		return nil, errorz.Errorf("strconv.ParseInt: ExpiresIn=%s: %w", resp.ExpiresIn, err)
+		return nil, errorz.Errorf("c.tokenSource.Token: %w", err)
 	}
 
-	return &AccessToken{
-		AccessToken: resp.AccessToken,
-		TokenType:   resp.TokenType,
-		ExpiresIn:   time.Duration(expiresIn) * time.Second,
-		Scope:       resp.Scope,
-		IssuedAt:    time.UnixMilli(issuedAtUnixMilli),
-	}, nil
+	return c.doRequestWithRetry(withAccessToken(req, accessToken))
 }
 
-func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
-	if c.accessToken == nil || time.Now().After(c.accessToken.IssuedAt.Add(c.accessToken.ExpiresIn)) {
-		accessToken, err := c.IssueAccessToken(req.Context())
-		if err != nil {
-			return nil, errorz.Errorf("c.issueAccessToken: %w", err)
-		}
-		c.accessToken = accessToken
+// withAccessToken returns a copy of req with the Authorization header, leaving req intact so that it can be replayed with another access token.
+func withAccessToken(req *http.Request, accessToken *AccessToken) *http.Request {
+	out := req.Clone(req.Context())
+	out.Header.Set("Authorization", "Bearer "+accessToken.AccessToken)
+	return out
+}
+
+// cloneRequest returns a copy of req with ctx and a fresh body, so that the request can be sent more than once.
+// req itself is never sent, and its body is never read.
+func cloneRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	out := req.Clone(ctx)
+	if req.Body == nil || req.Body == http.NoBody {
+		return out, nil
+	}
+	if req.GetBody == nil {
+		return nil, errorz.Errorf("method=%s url=%s: %w", req.Method, req.URL, ErrRequestBodyNotReplayable)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.accessToken.AccessToken)
 
-	return c.doRequestWithoutAccessToken(req)
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, errorz.Errorf("req.GetBody: %w", err)
+	}
+	out.Body = body
+
+	return out, nil
 }
 
-//nolint:cyclop
+// doRequestWithoutAccessToken sends req, retrying according to c.retryPolicy, and records the error to the span of the API call.
 func (c *Client) doRequestWithoutAccessToken(req *http.Request) (*http.Response, error) {
-	var out *http.Response
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		recordError(trace.SpanFromContext(req.Context()), err)
+		return nil, err
+	}
 
-	f := func(ctx context.Context) (err error) {
-		var dumpReq, dumpResp []byte
-		defer func() { c.debugLog.Printf("\n[REQUEST]\n%s\n[RESPONSE]\n%s", dumpReq, dumpResp) }()
+	return resp, nil
+}
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return errorz.Errorf("c.rateLimiter.Wait: %w", err)
-		}
+// doRequestWithRetry sends req, retrying according to c.retryPolicy.
+// Each attempt sends a clone of req with a fresh body, so req must have GetBody if it has a body. newRequest always sets it.
+func (c *Client) doRequestWithRetry(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
 
-		dumpReq, err = httputil.DumpRequest(req, true)
-		if err != nil {
-			return errorz.Errorf("httputil.DumpRequest: %w", err)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doAttempt(req, attempt)
+		if err == nil {
+			return resp, nil
 		}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return errorz.Errorf("c.httpClient.Do: %w", err)
+		if ctx.Err() != nil || !c.retryPolicy.retryable(req, err) {
+			return nil, errorz.Errorf("attempt=%d: %w", attempt, err)
+		}
+		if 0 < c.retryPolicy.MaxAttempts && c.retryPolicy.MaxAttempts <= attempt {
+			// NOTE: errorz.Errorf wraps only one error, so wrap both of them with fmt.Errorf first.
+			return nil, errorz.Errorf("attempt=%d: %w", attempt, fmt.Errorf("%w: %w", ErrMaxAttemptsExceeded, err))
 		}
-		defer func() {
-			if err != nil {
-				_ = resp.Body.Close()
-			}
-		}()
 
-		dumpResp, err = httputil.DumpResponse(resp, true)
-		if err != nil {
-			return errorz.Errorf("httputil.DumpResponse: %w", err)
+		if waitErr := c.retryPolicy.wait(ctx, attempt, err); waitErr != nil {
+			return nil, errorz.Errorf("attempt=%d: %w", attempt, fmt.Errorf("c.retryPolicy.wait: %w: %w", waitErr, err))
 		}
+	}
+}
+
+func (c *Client) doAttempt(req *http.Request, attempt int) (_ *http.Response, err error) {
+	var (
+		dumpReq, dumpResp []byte
+		l                 = &attemptLog{attempt: attempt}
+	)
+	ctx, span := c.startAttempt(req, attempt)
+	defer func() {
+		c.debugLog.Printf("\n[REQUEST]\n%s\n[RESPONSE]\n%s", c.redactor.Redact(dumpReq), c.redactor.Redact(dumpResp))
+		l.err = err
+		c.logAttempt(ctx, l)
+		c.endAttempt(ctx, span, req, l.resp, l.latency, err)
+	}()
+
+	if err := c.waitRateLimiter(ctx); err != nil {
+		return nil, errorz.Errorf("c.waitRateLimiter: %w", err)
+	}
+
+	attemptReq, err := cloneRequest(ctx, req)
+	if err != nil {
+		return nil, errorz.Errorf("cloneRequest: %w", err)
+	}
+	l.req = attemptReq
+	if c.logBodies(ctx) {
+		l.reqBody = readBody(attemptReq.GetBody)
+	}
+
+	dumpReq, err = httputil.DumpRequest(attemptReq, true)
+	if err != nil {
+		return nil, errorz.Errorf("httputil.DumpRequest: %w", err)
+	}
 
-		switch {
-		case resp.StatusCode == http.StatusTooManyRequests:
-			return errorz.Errorf("method=%s url=%s code=%d body=%s: %w", req.Method, req.URL, resp.StatusCode, getLimitedBody(resp.Body), ErrAPIReturnsTooManyRequest)
-		case resp.StatusCode == http.StatusUnauthorized:
-			return errorz.Errorf("method=%s url=%s code=%d body=%s: %w", req.Method, req.URL, resp.StatusCode, getLimitedBody(resp.Body), ErrAPIReturnsUnauthorized)
-		case resp.StatusCode < http.StatusOK || http.StatusMultipleChoices <= resp.StatusCode:
-			return errorz.Errorf("method=%s url=%s code=%d body=%s: %w", req.Method, req.URL, resp.StatusCode, getLimitedBody(resp.Body), ErrUnexpectedStatusCode)
+	start := time.Now()
+	resp, err := c.httpClient.Do(attemptReq)
+	l.latency = time.Since(start)
+	if err != nil {
+		return nil, errorz.Errorf("c.httpClient.Do: %w", err)
+	}
+	l.resp = resp
+	if q, ok := parseQuota(resp.Header); ok {
+		c.lastQuota.Store(q)
+	}
+	if o, ok := c.rateLimiter.(quotaObserver); ok {
+		o.observeQuota(resp.Header)
+	}
+	defer func() {
+		if err != nil {
+			_ = resp.Body.Close()
 		}
+	}()
 
-		out = resp
-		return nil
+	dumpResp, err = httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, errorz.Errorf("httputil.DumpResponse: %w", err)
+	}
+	if c.logBodies(ctx) {
+		l.respBody = readResponseBody(resp)
 	}
 
-	retryer := retryz.New(req.Context(), c.retryConfig)
-	if err := retryer.Do(f, retryz.WithRetryableErrors(ErrAPIReturnsTooManyRequest)); err != nil {
-		return nil, errorz.Errorf("(*retry.Retryer) Do: %w", err)
+	if resp.StatusCode < http.StatusOK || http.StatusMultipleChoices <= resp.StatusCode {
+		return nil, errorz.Errorf("newAPIError: %w", newAPIError(attemptReq, resp))
 	}
 
-	return out, nil
+	return resp, nil
 }
 
-func getLimitedBody(r io.Reader) string {
-	const limitedBodyLength = 512
-	b, _ := io.ReadAll(io.LimitReader(r, limitedBodyLength))
-	return string(b)
+// waitRateLimiter waits for c.rateLimiter in its own span.
+func (c *Client) waitRateLimiter(ctx context.Context) error {
+	ctx, span := c.telemetry.tracer.Start(ctx, "indigo.rateLimiter.Wait")
+	defer span.End()
+
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		recordError(span, err)
+		return errorz.Errorf("c.rateLimiter.Wait: %w", err)
+	}
+
+	return nil
 }
@@ -1,27 +1,49 @@
 package indigo
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hakadoriya/z.go/errorz"
 	"github.com/hakadoriya/z.go/syncz"
 	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
 )
 
 var (
 	testClient     *Client
 	testClientOnce syncz.Once
+	testServer     *indigotest.Server
 )
 
+// NewTestClient returns a client for the real API if WEBARENA_INDIGO_CLIENT_ID and WEBARENA_INDIGO_CLIENT_SECRET are set,
+// otherwise a client for the in-process fake server of indigotest.
 func NewTestClient(ctx context.Context, tb testing.TB) *Client {
 	tb.Helper()
 
 	if err := testClientOnce.Do(func() error {
-		client, err := NewClient(ctx, ClientOptionWithDebugLog(log.New(os.Stdout, "", log.LstdFlags|log.Lmicroseconds|log.Lshortfile)))
+		opts := []ClientOption{ClientOptionWithDebugLog(log.New(os.Stdout, "", log.LstdFlags|log.Lmicroseconds|log.Lshortfile))}
+		if os.Getenv(WEBARENA_INDIGO_CLIENT_ID) == "" || os.Getenv(WEBARENA_INDIGO_CLIENT_SECRET) == "" {
+			testServer = indigotest.NewServer()
+			opts = append(opts, testServerClientOptions(testServer)...)
+		}
+		client, err := NewClient(ctx, opts...)
 		if err != nil {
 			return errorz.Errorf("NewClient: %w", err)
 		}
@@ -38,6 +60,15 @@ func NewTestClient(ctx context.Context, tb testing.TB) *Client {
 	return testClient
 }
 
+func testServerClientOptions(srv *indigotest.Server) []ClientOption {
+	return []ClientOption{
+		ClientOptionWithEndpoint(srv.URL),
+		ClientOptionWithClientID(srv.ClientID()),
+		ClientOptionWithClientSecret(srv.ClientSecret()),
+		ClientOptionWithoutRateLimiter(),
+	}
+}
+
 //nolint:tparallel,paralleltest
 func TestClient_refreshAccessToken(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
@@ -49,3 +80,285 @@ func TestClient_refreshAccessToken(t *testing.T) {
 		requirez.NotNil(t, accessToken)
 	})
 }
+
+func TestClient_doRequest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,reissueAccessTokenOnUnauthorized", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		transport := &pathCountingTransport{}
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithHTTPClient(&http.Client{Transport: transport}))...)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 1, transport.count(PathOAuthV1AccessTokens))
+
+		srv.RevokeAccessTokens()
+
+		// NOTE: POST with a request body, to check that the body is re-sent when the request is replayed.
+		resp, err := client.CreateWebArenaIndigoV1VmSSHKey(ctx, &CreateWebArenaIndigoV1VmSSHKeyRequest{SshName: "test", SshKey: "ssh-ed25519 AAAA"})
+		requirez.NoError(t, err)
+		requirez.Equal(t, "test", resp.SshKey.Name)
+		requirez.Equal(t, 2, transport.count(PathOAuthV1AccessTokens))
+		requirez.Equal(t, 2, transport.count(PathWebArenaIndigoV1VmSSHKey))
+
+		// The re-issued access token is reused.
+		_, err = client.GetWebArenaIndigoV1VmSSHKey(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 2, transport.count(PathOAuthV1AccessTokens))
+	})
+
+	t.Run("failure,unauthorizedWithFreshAccessToken", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		// NOTE: The API rejects every access token, even a freshly issued one.
+		var apiCalls atomic.Int64
+		stub := newStubServer(t, srv, func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path == PathOAuthV1AccessTokens {
+				return false
+			}
+			apiCalls.Add(1)
+			writeStubError(w, http.StatusUnauthorized, "Unauthorized")
+			return true
+		})
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithEndpoint(stub.URL))...)
+		requirez.NoError(t, err)
+
+		_, err = client.GetWebArenaIndigoV1VmSSHKey(ctx)
+		requirez.ErrorIs(t, err, ErrAPIReturnsUnauthorized)
+		requirez.Equal(t, int64(2), apiCalls.Load())
+	})
+}
+
+// newStubServer returns a server that passes requests to upstream unless intercept handles them and returns true.
+// intercept can read r.Body, the body is restored before the request is passed to upstream.
+func newStubServer(tb testing.TB, upstream *indigotest.Server, intercept func(w http.ResponseWriter, r *http.Request) bool) *httptest.Server {
+	tb.Helper()
+
+	upstreamURL, err := url.Parse(upstream.URL)
+	requirez.NoError(tb, err)
+	proxy := httputil.NewSingleHostReverseProxy(upstreamURL)
+
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if intercept(w, r) {
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		proxy.ServeHTTP(w, r)
+	}))
+	tb.Cleanup(stub.Close)
+
+	return stub
+}
+
+// requestCounter counts the requests to a fake server by path.
+type requestCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (c *requestCounter) add(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[path]++
+}
+
+// Load returns the number of all the counted requests.
+func (c *requestCounter) Load() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var total int64
+	for _, n := range c.counts {
+		total += n
+	}
+	return total
+}
+
+// Path returns the number of the counted requests to path.
+func (c *requestCounter) Path(path string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[path]
+}
+
+// newRequestCountingClient returns a client of a new fake server, whose requests are counted by path if counted reports true for the path.
+func newRequestCountingClient(ctx context.Context, tb testing.TB, counted func(path string) bool, opts ...ClientOption) (*Client, *indigotest.Server, *requestCounter) {
+	tb.Helper()
+
+	srv := indigotest.NewServer()
+	tb.Cleanup(srv.Close)
+
+	counter := &requestCounter{counts: make(map[string]int64)}
+	stub := newStubServer(tb, srv, func(_ http.ResponseWriter, r *http.Request) bool {
+		if counted(r.URL.Path) {
+			counter.add(r.URL.Path)
+		}
+		return false
+	})
+
+	client, err := NewClient(ctx, append(append(testServerClientOptions(srv), ClientOptionWithEndpoint(stub.URL)), opts...)...)
+	requirez.NoError(tb, err)
+
+	return client, srv, counter
+}
+
+// bodyRecordingTransport records the request bodies to path.
+// Like logging middlewares, it consumes req.Body itself, so a request whose body is not recreated for each attempt is recorded with an empty body.
+type bodyRecordingTransport struct {
+	path string
+
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (t *bodyRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path != t.path || req.Body == nil {
+		return http.DefaultTransport.RoundTrip(req) //nolint:wrapcheck
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	t.mu.Lock()
+	t.bodies = append(t.bodies, string(body))
+	t.mu.Unlock()
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.GetBody = nil
+	out.ContentLength = int64(len(body))
+	return http.DefaultTransport.RoundTrip(out) //nolint:wrapcheck
+}
+
+func (t *bodyRecordingTransport) recorded() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.bodies)
+}
+
+func writeStubError(w http.ResponseWriter, statusCode int, errorMessage string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = fmt.Fprintf(w, `{"errorCode":"%d","errorMessage":"%s","developerMessage":"stub"}`, statusCode, errorMessage)
+}
+
+//nolint:funlen
+func TestClient_doRequestWithoutAccessToken(t *testing.T) {
+	t.Parallel()
+
+	// newClientWithTooManyRequestsFirst returns a client whose first request to path fails with 429, and a function that returns the bodies of the requests to path.
+	newClientWithTooManyRequestsFirst := func(t *testing.T, srv *indigotest.Server, path string) (*Client, func() []string) {
+		t.Helper()
+
+		var calls atomic.Int64
+		stub := newStubServer(t, srv, func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path == path && calls.Add(1) == 1 {
+				writeStubError(w, http.StatusTooManyRequests, "Too Many Request.")
+				return true
+			}
+			return false
+		})
+
+		transport := &bodyRecordingTransport{path: path}
+		client, err := NewClient(context.Background(), append(testServerClientOptions(srv),
+			ClientOptionWithEndpoint(stub.URL),
+			ClientOptionWithHTTPClient(&http.Client{Transport: transport}),
+		)...)
+		requirez.NoError(t, err)
+		client.retryPolicy = &RetryPolicy{InitialInterval: time.Millisecond, MaxInterval: 10 * time.Millisecond, RetryableStatusCodes: []int{http.StatusTooManyRequests}}
+
+		return client, transport.recorded
+	}
+
+	t.Run("success,replayBodyAfterTooManyRequests,POST", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		client, bodies := newClientWithTooManyRequestsFirst(t, srv, PathWebArenaIndigoV1VmCreateInstance)
+
+		created, err := client.PostWebArenaIndigoV1VmCreateInstance(ctx, &PostWebArenaIndigoV1VmCreateInstanceRequest{
+			RegionID:     1,
+			OsID:         1,
+			InstancePlan: 1,
+			InstanceName: "test-instance",
+		})
+		requirez.NoError(t, err)
+		requirez.Equal(t, "test-instance", created.Vms.InstanceName)
+
+		got := bodies()
+		requirez.Equal(t, 2, len(got))
+		requirez.StringContains(t, got[0], `"instanceName":"test-instance"`)
+		requirez.Equal(t, got[0], got[1])
+	})
+
+	t.Run("success,replayBodyAfterTooManyRequests,PUT", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		client, bodies := newClientWithTooManyRequestsFirst(t, srv, PathWebArenaIndigoV1NwUpdateFirewall)
+
+		created, err := client.PostWebArenaIndigoV1NwCreateFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:      "Example",
+			Inbound:   []WebArenaIndigoV1NwFirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}},
+			Outbound:  []WebArenaIndigoV1NwFirewallRule{},
+			Instances: []int64{},
+		})
+		requirez.NoError(t, err)
+
+		_, err = client.UpdateWebArenaIndigoV1NwFirewall(ctx, &UpdateWebArenaIndigoV1NwFirewallRequest{
+			TemplateID: created.FirewallID,
+			Name:       "Example",
+			Inbound:    []WebArenaIndigoV1NwFirewallRule{{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"}},
+			Outbound:   []WebArenaIndigoV1NwFirewallRule{},
+			Instances:  []int64{},
+		})
+		requirez.NoError(t, err)
+
+		got := bodies()
+		requirez.Equal(t, 2, len(got))
+		requirez.StringContains(t, got[0], `"port":"22"`)
+		requirez.Equal(t, got[0], got[1])
+
+		template, err := client.GetWebArenaIndigoV1NwGetTemplate(ctx, created.FirewallID)
+		requirez.NoError(t, err)
+		requirez.Equal(t, "22", (*template)[0].Port)
+	})
+
+	t.Run("failure,bodyNotReplayable", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+PathWebArenaIndigoV1VmSSHKey, io.NopCloser(strings.NewReader(`{}`)))
+		requirez.NoError(t, err)
+		_, err = client.doRequestWithoutAccessToken(req)
+		requirez.ErrorIs(t, err, ErrRequestBodyNotReplayable)
+	})
+}
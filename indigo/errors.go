@@ -1,6 +1,12 @@
 package indigo
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
 
 const (
 	textPleaseCheckClientCredentialsEnv = "Please check the environment variable " + WEBARENA_INDIGO_CLIENT_ID + " and " + WEBARENA_INDIGO_CLIENT_SECRET
@@ -11,4 +17,84 @@ var (
 	ErrAPIReturnsTooManyRequest = errors.New("indigo: API returns Too Many Request")
 	ErrAPIReturnsUnauthorized   = errors.New("indigo: API returns Unauthorized. " + textPleaseCheckClientCredentialsEnv)
 	ErrInvalidClientCredentials = errors.New("indigo: invalid client credentials. " + textPleaseCheckClientCredentialsEnv)
+	ErrRequestBodyNotReplayable = errors.New("indigo: request body is not replayable")
+	ErrMaxAttemptsExceeded      = errors.New("indigo: max attempts exceeded")
+	ErrInvalidArgument          = errors.New("indigo: invalid argument")
+	ErrNotFound                 = errors.New("indigo: not found")
+	// ErrMultipleFound is returned when more than one resource matches a lookup that expects one.
+	ErrMultipleFound      = errors.New("indigo: multiple resources found")
+	ErrWaitTimeout        = errors.New("indigo: wait timed out")
+	ErrWaitTerminalStatus = errors.New("indigo: resource reached a terminal status")
+	// ErrInvalidInstanceTransition is returned when the action is not valid for the current status of the instance.
+	ErrInvalidInstanceTransition = errors.New("indigo: invalid instance status transition")
+	// ErrUnknownFirewallDirection is returned when the API returns a firewall rule whose direction is neither "in" nor "out".
+	ErrUnknownFirewallDirection = errors.New("indigo: unknown firewall direction")
+)
+
+// APIError is the error returned when the API responds with a non-2xx status code.
+//
+// Error Response Example:
+//
+//	{"errorCode": "429", "errorMessage": "Too Many Request.", "developerMessage": "Rate limit quota violation. Quota limit  exceeded. Identifier : ffffffff-ffff-4fff-ffff-ffffffffffff", "moreInfo": null, "requestId": "ffffffff-ffff-ffff-ffff-fffffffffffffffffff"}
+//
+// APIError satisfies errors.Is for ErrAPIReturnsTooManyRequest, ErrAPIReturnsUnauthorized or ErrUnexpectedStatusCode depending on StatusCode.
+type APIError struct {
+	Method     string `json:"-"`
+	URL        string `json:"-"`
+	StatusCode int    `json:"-"`
+	// RequestID is the value of the X-Request-Id response header.
+	RequestID string `json:"-"`
+	// Body is the response body, truncated to a limited length to keep the error message short.
+	Body string `json:"-"`
+	// Header is the response header.
+	Header http.Header `json:"-"`
+
+	// The following fields are parsed from the response body. They are empty if the body is not a JSON error response.
+	ErrorCode         string `json:"errorCode"`
+	ErrorMessage      string `json:"errorMessage"`
+	DeveloperMessage  string `json:"developerMessage"`
+	MoreInfo          string `json:"moreInfo"`
+	ResponseRequestID string `json:"requestId"`
+
+	err error
+}
+
+const (
+	// maxAPIErrorBodyLength bounds the response body read to parse an error response, so that a huge body does not exhaust the memory.
+	maxAPIErrorBodyLength = 1 << 20
+	// apiErrorBodyDisplayLength is the length that APIError.Body is truncated to.
+	apiErrorBodyDisplayLength = 512
 )
+
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxAPIErrorBodyLength))
+	e := &APIError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Body:       string(body[:min(len(body), apiErrorBodyDisplayLength)]),
+		Header:     resp.Header,
+	}
+
+	// NOTE: Parse the whole body, not the truncated one, so that a long message does not lose the fields after it.
+	// Ignore the error because the API does not always return a JSON error response.
+	_ = json.Unmarshal(body, e)
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		e.err = ErrAPIReturnsTooManyRequest
+	case http.StatusUnauthorized:
+		e.err = ErrAPIReturnsUnauthorized
+	default:
+		e.err = ErrUnexpectedStatusCode
+	}
+
+	return e
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("method=%s url=%s code=%d requestId=%s body=%s: %v", e.Method, e.URL, e.StatusCode, e.RequestID, e.Body, e.err)
+}
+
+func (e *APIError) Unwrap() error { return e.err }
@@ -0,0 +1,99 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,errorsAs", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		_, err = client.GetWebArenaIndigoV1NwGetTemplate(ctx, math.MaxInt)
+		requirez.ErrorIs(t, err, ErrUnexpectedStatusCode)
+
+		var apiErr *APIError
+		requirez.True(t, errors.As(err, &apiErr))
+		requirez.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		requirez.Equal(t, http.MethodGet, apiErr.Method)
+		requirez.StringContains(t, apiErr.URL, PathWebArenaIndigoV1NwGetTemplate)
+		requirez.StringHasPrefix(t, apiErr.RequestID, "00000000-0000-4000-8000-")
+		requirez.Equal(t, apiErr.RequestID, apiErr.ResponseRequestID)
+		requirez.Equal(t, "404", apiErr.ErrorCode)
+		requirez.Equal(t, "Firewall template not found", apiErr.DeveloperMessage)
+	})
+
+	t.Run("success,sentinels", func(t *testing.T) {
+		t.Parallel()
+
+		for statusCode, sentinel := range map[int]error{
+			http.StatusTooManyRequests:     ErrAPIReturnsTooManyRequest,
+			http.StatusUnauthorized:        ErrAPIReturnsUnauthorized,
+			http.StatusServiceUnavailable:  ErrUnexpectedStatusCode,
+			http.StatusInternalServerError: ErrUnexpectedStatusCode,
+		} {
+			req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "api.customer.jp", Path: "/"}}
+			resp := &http.Response{
+				StatusCode: statusCode,
+				Header:     http.Header{"X-Request-Id": []string{"00000000-0000-4000-0000-000000000000"}},
+				Body:       io.NopCloser(strings.NewReader(`{"errorCode": "429", "errorMessage": "Too Many Request.", "developerMessage": "Rate limit quota violation.", "moreInfo": null, "requestId": "ffffffff"}`)),
+			}
+			apiErr := newAPIError(req, resp)
+			requirez.ErrorIs(t, apiErr, sentinel)
+			requirez.Equal(t, "Too Many Request.", apiErr.ErrorMessage)
+			requirez.Equal(t, "", apiErr.MoreInfo)
+			requirez.Equal(t, "ffffffff", apiErr.ResponseRequestID)
+			requirez.StringContains(t, apiErr.Error(), "requestId=00000000-0000-4000-0000-000000000000")
+		}
+	})
+
+	t.Run("success,longBody", func(t *testing.T) {
+		t.Parallel()
+
+		req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "api.customer.jp", Path: "/"}}
+		resp := &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"developerMessage": "` + strings.Repeat("x", 1000) + `", "errorCode": "400", "errorMessage": "Bad Request"}`)),
+		}
+		apiErr := newAPIError(req, resp)
+		requirez.Equal(t, "400", apiErr.ErrorCode)
+		requirez.Equal(t, "Bad Request", apiErr.ErrorMessage)
+		requirez.Equal(t, 1000, len(apiErr.DeveloperMessage))
+		requirez.Equal(t, 512, len(apiErr.Body))
+	})
+
+	t.Run("success,notJSON", func(t *testing.T) {
+		t.Parallel()
+
+		req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "api.customer.jp", Path: "/"}}
+		resp := &http.Response{
+			StatusCode: http.StatusBadGateway,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("<html>Bad Gateway</html>")),
+		}
+		apiErr := newAPIError(req, resp)
+		requirez.ErrorIs(t, apiErr, ErrUnexpectedStatusCode)
+		requirez.Equal(t, "", apiErr.ErrorCode)
+		requirez.Equal(t, "<html>Bad Gateway</html>", apiErr.Body)
+	})
+}
@@ -0,0 +1,149 @@
+package indigo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+const (
+	fileTokenSourceDirPerm  = 0o700
+	fileTokenSourceFilePerm = 0o600
+)
+
+// FileTokenSource is a TokenSource that caches the access token of base in a file,
+// so that processes that run for a short time (e.g. CLI invocations or serverless functions)
+// can share an access token instead of issuing a new one every time.
+//
+// FileTokenSource is safe for concurrent use. The file is replaced atomically, so it is also safe to share the file between processes.
+type FileTokenSource struct {
+	path string
+	base TokenSource
+	// refreshSkew is how long before IssuedAt+ExpiresIn the cached token is regarded as expired.
+	refreshSkew time.Duration
+	now         func() time.Time
+
+	mu sync.Mutex
+}
+
+var _ TokenInvalidator = (*FileTokenSource)(nil)
+
+// NewFileTokenSource returns a FileTokenSource that stores the access token of base in path with 0600 permissions.
+// The cached access token is reused until refreshSkew before it expires.
+func NewFileTokenSource(path string, base TokenSource, refreshSkew time.Duration) *FileTokenSource {
+	return &FileTokenSource{
+		path:        path,
+		base:        base,
+		refreshSkew: refreshSkew,
+		now:         time.Now,
+	}
+}
+
+// DefaultTokenCachePath returns the path of the access token cache file for endpoint and clientID under os.UserCacheDir.
+func DefaultTokenCachePath(endpoint, clientID string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errorz.Errorf("os.UserCacheDir: %w", err)
+	}
+
+	// NOTE: Do not put the client ID itself into the file name.
+	sum := sha256.Sum256([]byte(endpoint + "\n" + clientID))
+
+	return filepath.Join(cacheDir, "webarena-go", "indigo", "token-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// Token returns the cached access token if it is still valid, otherwise issues a new one via base and caches it.
+// If the access token cannot be cached, e.g. because the directory is read-only, it is returned anyway,
+// and the error is recorded in the span of ctx, so that a broken cache does not stop the client.
+func (s *FileTokenSource) Token(ctx context.Context) (*AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.read()
+	if err == nil && s.now().Add(s.refreshSkew).Before(token.IssuedAt.Add(token.ExpiresIn)) {
+		return token, nil
+	}
+
+	token, err = s.base.Token(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("s.base.Token: %w", err)
+	}
+
+	if err := s.write(token); err != nil {
+		trace.SpanFromContext(ctx).RecordError(errorz.Errorf("s.write: path=%s: %w", s.path, err))
+	}
+
+	return token, nil
+}
+
+// InvalidateToken removes the cache file if it still holds token.
+func (s *FileTokenSource) InvalidateToken(_ context.Context, token *AccessToken) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, err := s.read()
+	if err != nil || cached.AccessToken != token.AccessToken {
+		return
+	}
+
+	_ = os.Remove(s.path)
+}
+
+func (s *FileTokenSource) read() (*AccessToken, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, errorz.Errorf("os.ReadFile: %w", err)
+	}
+
+	var token AccessToken
+	if err := json.Unmarshal(b, &token); err != nil {
+		return nil, errorz.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return &token, nil
+}
+
+func (s *FileTokenSource) write(token *AccessToken) error {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return errorz.Errorf("json.Marshal: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, fileTokenSourceDirPerm); err != nil {
+		return errorz.Errorf("os.MkdirAll: %w", err)
+	}
+
+	// NOTE: os.CreateTemp creates the file with 0600 permissions. Rename it into place so that other processes never read a partially written file.
+	f, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return errorz.Errorf("os.CreateTemp: %w", err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return errorz.Errorf("f.Write: %w", err)
+	}
+	if err := f.Chmod(fileTokenSourceFilePerm); err != nil {
+		_ = f.Close()
+		return errorz.Errorf("f.Chmod: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return errorz.Errorf("f.Close: %w", err)
+	}
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		return errorz.Errorf("os.Rename: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package indigo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func TestFileTokenSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,reuseAcrossClients", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		transport := &pathCountingTransport{}
+		path := filepath.Join(t.TempDir(), "cache", "token.json")
+		ctx := context.Background()
+		for range 3 {
+			client, err := NewClient(ctx, append(testServerClientOptions(srv),
+				ClientOptionWithHTTPClient(&http.Client{Transport: transport}),
+				ClientOptionWithFileTokenCache(path),
+			)...)
+			requirez.NoError(t, err)
+			_, err = client.GetWebArenaIndigoV1VmSSHKey(ctx)
+			requirez.NoError(t, err)
+		}
+		requirez.Equal(t, 1, transport.count(PathOAuthV1AccessTokens))
+
+		info, err := os.Stat(path)
+		requirez.NoError(t, err)
+		requirez.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		b, err := os.ReadFile(path)
+		requirez.NoError(t, err)
+		var token AccessToken
+		requirez.NoError(t, json.Unmarshal(b, &token))
+		requirez.Equal(t, "BearerToken", token.TokenType)
+		requirez.Equal(t, indigotest.DefaultTokenTTL, token.ExpiresIn)
+	})
+
+	t.Run("success,refreshExpired", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		base := TokenSourceFunc(func(ctx context.Context) (*AccessToken, error) {
+			calls++
+			return &AccessToken{AccessToken: "token", ExpiresIn: time.Hour, IssuedAt: time.Date(2024, 5, 12, 13, 41, 52, 0, time.UTC)}, nil
+		})
+		now := time.Date(2024, 5, 12, 13, 41, 52, 0, time.UTC)
+		s := NewFileTokenSource(filepath.Join(t.TempDir(), "token.json"), base, time.Minute)
+		s.now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, err := s.Token(ctx)
+		requirez.NoError(t, err)
+		_, err = s.Token(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 1, calls)
+
+		now = now.Add(59*time.Minute + time.Second)
+		_, err = s.Token(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 2, calls)
+	})
+
+	t.Run("success,writeError", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		base := TokenSourceFunc(func(ctx context.Context) (*AccessToken, error) {
+			calls++
+			return &AccessToken{AccessToken: "token", ExpiresIn: time.Hour, IssuedAt: time.Now()}, nil
+		})
+		// NOTE: The parent of the cache file is a regular file, so that the cache cannot be written.
+		parent := filepath.Join(t.TempDir(), "file")
+		requirez.NoError(t, os.WriteFile(parent, nil, 0o600))
+		s := NewFileTokenSource(filepath.Join(parent, "token.json"), base, time.Minute)
+
+		recorder := tracetest.NewSpanRecorder()
+		ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "test")
+		token, err := s.Token(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, "token", token.AccessToken)
+		span.End()
+
+		events := recorder.Ended()[0].Events()
+		requirez.Equal(t, 1, len(events))
+		requirez.Equal(t, "exception", events[0].Name)
+
+		// The access token is issued again, because it has not been cached.
+		_, err = s.Token(context.Background())
+		requirez.NoError(t, err)
+		requirez.Equal(t, 2, calls)
+	})
+
+	t.Run("success,invalidateOnUnauthorized", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		transport := &pathCountingTransport{}
+		path := filepath.Join(t.TempDir(), "token.json")
+		ctx := context.Background()
+		newClient := func() *Client {
+			client, err := NewClient(ctx, append(testServerClientOptions(srv),
+				ClientOptionWithHTTPClient(&http.Client{Transport: transport}),
+				ClientOptionWithFileTokenCache(path),
+			)...)
+			requirez.NoError(t, err)
+			return client
+		}
+
+		client := newClient()
+		srv.RevokeAccessTokens()
+		_, err := client.GetWebArenaIndigoV1VmSSHKey(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 2, transport.count(PathOAuthV1AccessTokens))
+
+		// The re-issued access token is cached in the file.
+		_, err = newClient().GetWebArenaIndigoV1VmSSHKey(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 2, transport.count(PathOAuthV1AccessTokens))
+	})
+}
+
+//nolint:paralleltest
+func TestDefaultTokenCachePath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	t.Run("success", func(t *testing.T) {
+		a, err := DefaultTokenCachePath("https://api.customer.jp", "client-a")
+		requirez.NoError(t, err)
+		b, err := DefaultTokenCachePath("https://api.customer.jp", "client-b")
+		requirez.NoError(t, err)
+		requirez.NotEqual(t, a, b)
+		requirez.False(t, strings.Contains(a, "client-a"))
+	})
+}
@@ -0,0 +1,248 @@
+package indigo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// FirewallPlanAction is what ApplyFirewall does to the firewall template.
+type FirewallPlanAction string
+
+const (
+	// FirewallPlanActionCreate creates the firewall because no firewall has the name.
+	FirewallPlanActionCreate FirewallPlanAction = "create"
+	// FirewallPlanActionUpdate replaces the rules and the instances of the firewall because its rules differ from the desired ones.
+	// The rules are compared as DiffFirewalls does.
+	FirewallPlanActionUpdate FirewallPlanAction = "update"
+	// FirewallPlanActionNone leaves the rules of the firewall as they are because they are the desired ones.
+	// The instances are still replaced with the desired ones unless they are nil.
+	FirewallPlanActionNone FirewallPlanAction = "none"
+)
+
+// FirewallPlan is the changes that ApplyFirewall makes, or PlanFirewall would make, to reconcile a firewall with the desired one.
+type FirewallPlan struct {
+	Action FirewallPlanAction
+	// FirewallID is the ID of the firewall. It is 0 if the firewall is to be created and the plan has not been applied.
+	FirewallID int64
+	Name       string
+	// Current is the firewall found by the name, or nil if it is to be created.
+	Current *FirewallTemplate
+	// Diff is the difference of the rules from the current ones to the desired ones. See DiffFirewalls.
+	// For FirewallPlanActionCreate, all the desired rules are added.
+	Diff FirewallDiff
+	// Instances are the desired instances, or nil if the instances of the firewall are left as they are. See ApplyFirewall.
+	Instances []int64
+	// Applied reports whether the changes have been made. It is false for PlanFirewall.
+	Applied bool
+}
+
+// HasChanges reports whether applying the plan calls a mutating endpoint.
+func (p *FirewallPlan) HasChanges() bool {
+	return p.Action != FirewallPlanActionNone || p.Instances != nil
+}
+
+// String returns the plan in a human-readable form, e.g.
+//
+//	~ firewall "web" (id=5): update
+//	  + in  HTTPS TCP 443 0.0.0.0
+//	  - in  HTTP TCP 80 0.0.0.0
+//	  ~ in  SSH TCP 22 0.0.0.0 => SSH TCP 22 192.0.2.0/24
+//	  instances: 1, 2
+func (p *FirewallPlan) String() string {
+	var b strings.Builder
+
+	mark := map[FirewallPlanAction]string{FirewallPlanActionCreate: "+", FirewallPlanActionUpdate: "~", FirewallPlanActionNone: "="}[p.Action]
+	fmt.Fprintf(&b, "%s firewall %q", mark, p.Name)
+	if p.FirewallID != 0 {
+		fmt.Fprintf(&b, " (id=%d)", p.FirewallID)
+	}
+	fmt.Fprintf(&b, ": %s\n", p.Action)
+
+	writeFirewallDiff(&b, "  ", &p.Diff)
+
+	switch {
+	case p.Instances == nil:
+	case len(p.Instances) == 0:
+		b.WriteString("  instances: none\n")
+	default:
+		ids := make([]string, 0, len(p.Instances))
+		for _, id := range p.Instances {
+			ids = append(ids, fmt.Sprint(id))
+		}
+		fmt.Fprintf(&b, "  instances: %s\n", strings.Join(ids, ", "))
+	}
+
+	return b.String()
+}
+
+// PlanFirewall returns the changes that ApplyFirewall would make for desired, without calling any mutating endpoint.
+// It is the dry run of ApplyFirewall.
+func (c *Client) PlanFirewall(ctx context.Context, desired *PostWebArenaIndigoV1NwCreateFirewallRequest) (*FirewallPlan, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyFirewallName.String(desiredFirewallName(desired))))
+	defer span.End()
+
+	plan, err := c.planFirewall(ctx, desired)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.planFirewall: %w", err)
+	}
+	span.SetAttributes(attributeKeyFirewallID.Int64(plan.FirewallID))
+
+	return plan, nil
+}
+
+// ApplyFirewall reconciles the firewall named desired.Name with desired, and returns the plan it has applied.
+//
+// It creates the firewall if no firewall has the name, updates it if its rules differ from the desired ones, and leaves it otherwise.
+// The order of the rules does not matter.
+//
+// The instances of the firewall are managed by desired.Instances, because the API does not report which instances a firewall is assigned to:
+//
+//   - If it is not nil, the firewall is assigned to exactly those instances, and an empty slice detaches all the instances.
+//     They are sent with the rules, even if the rules are unchanged.
+//   - If it is nil, the instances are left as they are. Because updating the rules of a firewall replaces its instances,
+//     it returns ErrInvalidArgument if the rules differ from the desired ones, instead of detaching all the instances.
+//
+// It returns ErrInvalidArgument if desired is invalid (see PostWebArenaIndigoV1NwCreateFirewallRequest.Validate) or as above,
+// and ErrMultipleFound if more than one firewall has the name.
+// Use PlanFirewall for a dry run.
+//
+// Example:
+//
+//	plan, err := client.ApplyFirewall(ctx, &indigo.PostWebArenaIndigoV1NwCreateFirewallRequest{
+//		Name:      "web",
+//		Inbound:   []indigo.FirewallRule{{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}},
+//		Outbound:  []indigo.FirewallRule{},
+//		Instances: []int64{16},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(plan)
+func (c *Client) ApplyFirewall(ctx context.Context, desired *PostWebArenaIndigoV1NwCreateFirewallRequest) (*FirewallPlan, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyFirewallName.String(desiredFirewallName(desired))))
+	defer span.End()
+
+	plan, err := c.planFirewall(ctx, desired)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.planFirewall: %w", err)
+	}
+
+	switch plan.Action {
+	case FirewallPlanActionCreate:
+		resp, err := c.PostWebArenaIndigoV1NwCreateFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:      desired.Name,
+			Inbound:   nonNilRules(desired.Inbound),
+			Outbound:  nonNilRules(desired.Outbound),
+			Instances: append([]int64{}, plan.Instances...),
+		})
+		if err != nil {
+			recordError(span, err)
+			return nil, errorz.Errorf("c.PostWebArenaIndigoV1NwCreateFirewall: %w", err)
+		}
+		plan.FirewallID = resp.FirewallID
+	case FirewallPlanActionUpdate, FirewallPlanActionNone:
+		if plan.Instances == nil {
+			// NOTE: planFirewall refuses FirewallPlanActionUpdate with nil instances, so this leaves the firewall as it is.
+			break
+		}
+		inbound, outbound := desired.Inbound, desired.Outbound
+		if plan.Action == FirewallPlanActionNone {
+			// NOTE: The current rules are sent as they are, so that only the instances are replaced.
+			inbound, outbound = plan.Current.Inbound, plan.Current.Outbound
+		}
+		if _, err := c.UpdateWebArenaIndigoV1NwFirewall(ctx, &UpdateWebArenaIndigoV1NwFirewallRequest{
+			TemplateID: plan.FirewallID,
+			Name:       desired.Name,
+			Inbound:    nonNilRules(inbound),
+			Outbound:   nonNilRules(outbound),
+			Instances:  append([]int64{}, plan.Instances...),
+		}); err != nil {
+			recordError(span, err)
+			return nil, errorz.Errorf("c.UpdateWebArenaIndigoV1NwFirewall: %w", err)
+		}
+	}
+	plan.Applied = true
+	span.SetAttributes(attributeKeyFirewallID.Int64(plan.FirewallID))
+
+	return plan, nil
+}
+
+func desiredFirewallName(desired *PostWebArenaIndigoV1NwCreateFirewallRequest) string {
+	if desired == nil {
+		return ""
+	}
+	return desired.Name
+}
+
+func (c *Client) planFirewall(ctx context.Context, desired *PostWebArenaIndigoV1NwCreateFirewallRequest) (*FirewallPlan, error) {
+	if err := desired.Validate(); err != nil {
+		return nil, errorz.Errorf("desired.Validate: %w", err)
+	}
+
+	firewalls, err := c.GetWebArenaIndigoV1NwGetFirewallList(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1NwGetFirewallList: %w", err)
+	}
+
+	instances := slices.Clone(desired.Instances)
+	slices.Sort(instances)
+	plan := &FirewallPlan{Name: desired.Name, Instances: slices.Compact(instances)}
+
+	var found []Firewall
+	for _, firewall := range *firewalls {
+		if firewall.Name == desired.Name {
+			found = append(found, firewall)
+		}
+	}
+	switch len(found) {
+	case 0:
+		plan.Action = FirewallPlanActionCreate
+		plan.Diff = FirewallDiff{
+			Inbound:  FirewallRulesDiff{Added: slices.Clone(desired.Inbound)},
+			Outbound: FirewallRulesDiff{Added: slices.Clone(desired.Outbound)},
+		}
+		return plan, nil
+	case 1:
+		plan.FirewallID = found[0].ID
+	default:
+		return nil, errorz.Errorf("name=%q firewallIDs=[%d %d]: %w", desired.Name, found[0].ID, found[1].ID, ErrMultipleFound)
+	}
+
+	rows, err := c.GetWebArenaIndigoV1NwGetTemplate(ctx, plan.FirewallID)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1NwGetTemplate: firewallID=%d: %w", plan.FirewallID, err)
+	}
+	plan.Current, err = NewFirewallTemplate(plan.FirewallID, *rows)
+	if err != nil {
+		return nil, errorz.Errorf("NewFirewallTemplate: %w", err)
+	}
+	plan.Current.Name = found[0].Name
+
+	plan.Diff = *DiffFirewalls(plan.Current, &FirewallTemplate{Name: desired.Name, Inbound: desired.Inbound, Outbound: desired.Outbound})
+	if plan.Diff.Empty() {
+		plan.Action = FirewallPlanActionNone
+		return plan, nil
+	}
+	if plan.Instances == nil {
+		return nil, errorz.Errorf("name=%q firewallID=%d: instances are nil, but updating the rules would detach all the instances of the firewall; set the instances to keep: %w", desired.Name, plan.FirewallID, ErrInvalidArgument)
+	}
+	plan.Action = FirewallPlanActionUpdate
+
+	return plan, nil
+}
+
+// nonNilRules returns rules, or an empty slice if it is nil, so that it is sent as [] instead of null.
+func nonNilRules(rules []FirewallRule) []FirewallRule {
+	if rules == nil {
+		return []FirewallRule{}
+	}
+	return rules
+}
@@ -0,0 +1,202 @@
+package indigo
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+// isFirewallMutationPath reports whether path is of a mutating firewall endpoint.
+func isFirewallMutationPath(path string) bool {
+	return slices.ContainsFunc([]string{
+		PathWebArenaIndigoV1NwCreateFirewall,
+		PathWebArenaIndigoV1NwUpdateFirewall,
+		PathWebArenaIndigoV1NwAssign,
+		PathWebArenaIndigoV1NwDeleteFirewall,
+	}, func(prefix string) bool { return strings.HasPrefix(path, prefix) })
+}
+
+func firewallInstances(tb testing.TB, srv *indigotest.Server, firewallID int64) []int64 {
+	tb.Helper()
+
+	instances, found := srv.FirewallInstances(firewallID)
+	requirez.True(tb, found)
+	return instances
+}
+
+//nolint:funlen
+func TestClient_ApplyFirewall(t *testing.T) {
+	t.Parallel()
+
+	http80 := FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}
+	https443 := FirewallRule{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}
+	ssh22 := FirewallRule{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"}
+
+	t.Run("success,createUpdateNone", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, srv, mutated := newRequestCountingClient(ctx, t, isFirewallMutationPath)
+		instanceID := createTestInstance(ctx, t, client)
+
+		desired := &PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:      "web",
+			Inbound:   []FirewallRule{http80, ssh22},
+			Outbound:  []FirewallRule{https443},
+			Instances: []int64{instanceID},
+		}
+
+		// create
+		plan, err := client.PlanFirewall(ctx, desired)
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionCreate, plan.Action)
+		requirez.Equal(t, int64(0), plan.FirewallID)
+		requirez.Equal(t, []FirewallRule{http80, ssh22}, plan.Diff.Inbound.Added)
+		requirez.False(t, plan.Applied)
+		requirez.Equal(t, int64(0), mutated.Load())
+
+		plan, err = client.ApplyFirewall(ctx, desired)
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionCreate, plan.Action)
+		requirez.True(t, plan.FirewallID != 0)
+		requirez.True(t, plan.Applied)
+		requirez.Equal(t, int64(1), mutated.Load())
+		firewallID := plan.FirewallID
+
+		got, err := client.Firewalls.Get(ctx, firewallID)
+		requirez.NoError(t, err)
+		requirez.Equal(t, &FirewallTemplate{ID: firewallID, Name: "web", Inbound: []FirewallRule{http80, ssh22}, Outbound: []FirewallRule{https443}}, got)
+
+		// none: the order of the rules does not matter, and the instances are assigned again.
+		desired.Inbound = []FirewallRule{ssh22, http80}
+		plan, err = client.ApplyFirewall(ctx, desired)
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionNone, plan.Action)
+		requirez.Equal(t, firewallID, plan.FirewallID)
+		requirez.True(t, plan.HasChanges())
+		requirez.Equal(t, int64(2), mutated.Load())
+		requirez.Equal(t, []int64{instanceID}, firewallInstances(t, srv, firewallID))
+
+		// update: the instance stays attached after a rule-only update.
+		desired.Inbound = []FirewallRule{https443, ssh22}
+		plan, err = client.PlanFirewall(ctx, desired)
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionUpdate, plan.Action)
+		requirez.Equal(t, FirewallRulesDiff{Added: []FirewallRule{https443}, Removed: []FirewallRule{http80}}, plan.Diff.Inbound)
+		requirez.True(t, plan.Diff.Outbound.Empty())
+		requirez.Equal(t, int64(2), mutated.Load())
+
+		plan, err = client.ApplyFirewall(ctx, desired)
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionUpdate, plan.Action)
+		requirez.Equal(t, int64(3), mutated.Load())
+		requirez.Equal(t, []int64{instanceID}, firewallInstances(t, srv, firewallID))
+
+		// nil instances: the instances are left as they are, and an update that would detach them is refused.
+		desired.Instances = nil
+		plan, err = client.ApplyFirewall(ctx, desired)
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionNone, plan.Action)
+		requirez.False(t, plan.HasChanges())
+		requirez.Equal(t, int64(3), mutated.Load())
+
+		desired.Inbound = []FirewallRule{https443}
+		_, err = client.PlanFirewall(ctx, desired)
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		_, err = client.ApplyFirewall(ctx, desired)
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		requirez.Equal(t, int64(3), mutated.Load())
+		requirez.Equal(t, []int64{instanceID}, firewallInstances(t, srv, firewallID))
+
+		// empty instances: all the instances are detached, even if the rules are unchanged.
+		desired.Inbound = []FirewallRule{https443, ssh22}
+		desired.Instances = []int64{}
+		plan, err = client.ApplyFirewall(ctx, desired)
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionNone, plan.Action)
+		requirez.True(t, plan.HasChanges())
+		requirez.Equal(t, int64(4), mutated.Load())
+		requirez.Equal(t, []int64{}, firewallInstances(t, srv, firewallID))
+	})
+
+	t.Run("success,noneLowercaseProtocol", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, srv, mutated := newRequestCountingClient(ctx, t, isFirewallMutationPath)
+		instanceID := createTestInstance(ctx, t, client)
+
+		lowercase := FirewallRule{Type: "HTTP", Protocol: "tcp", Port: "80", Source: "0.0.0.0"}
+		created, err := client.PostWebArenaIndigoV1NwCreateFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "web", Inbound: []FirewallRule{lowercase}, Outbound: []FirewallRule{}, Instances: []int64{}})
+		requirez.NoError(t, err)
+
+		// NOTE: The current rules with the lowercase protocol are resent as they are to assign the instance.
+		plan, err := client.ApplyFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "web", Inbound: []FirewallRule{http80}, Instances: []int64{instanceID}})
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionNone, plan.Action)
+		requirez.Equal(t, int64(2), mutated.Load())
+		requirez.Equal(t, []int64{instanceID}, firewallInstances(t, srv, created.FirewallID))
+
+		got, err := client.Firewalls.Get(ctx, created.FirewallID)
+		requirez.NoError(t, err)
+		requirez.Equal(t, []FirewallRule{lowercase}, got.Inbound)
+	})
+
+	t.Run("success,String", func(t *testing.T) {
+		t.Parallel()
+
+		plan := &FirewallPlan{
+			Action:     FirewallPlanActionUpdate,
+			FirewallID: 5,
+			Name:       "web",
+			Diff: FirewallDiff{Inbound: FirewallRulesDiff{
+				Added:   []FirewallRule{https443},
+				Removed: []FirewallRule{http80},
+				Changed: []FirewallRuleChange{{From: FirewallRule{Type: "SSH", Protocol: "TCP", Port: "22", Source: "0.0.0.0"}, To: ssh22}},
+			}},
+			Instances: []int64{1, 2},
+		}
+		requirez.Equal(t, `~ firewall "web" (id=5): update
+  + in  HTTPS TCP 443 0.0.0.0
+  - in  HTTP TCP 80 0.0.0.0
+  ~ in  SSH TCP 22 0.0.0.0 => SSH TCP 22 192.0.2.0/24
+  instances: 1, 2
+`, plan.String())
+
+		plan = &FirewallPlan{Action: FirewallPlanActionNone, FirewallID: 5, Name: "web", Instances: []int64{}}
+		requirez.Equal(t, "= firewall \"web\" (id=5): none\n  instances: none\n", plan.String())
+	})
+
+	t.Run("failure,invalidArgument", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, mutated := newRequestCountingClient(ctx, t, isFirewallMutationPath)
+
+		_, err := client.ApplyFirewall(ctx, nil)
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		_, err = client.ApplyFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Inbound: []FirewallRule{http80}})
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		requirez.Equal(t, int64(0), mutated.Load())
+	})
+
+	t.Run("failure,multipleFound", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, mutated := newRequestCountingClient(ctx, t, isFirewallMutationPath)
+		for range 2 {
+			_, err := client.PostWebArenaIndigoV1NwCreateFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "web", Inbound: []FirewallRule{}, Outbound: []FirewallRule{}, Instances: []int64{}})
+			requirez.NoError(t, err)
+		}
+
+		_, err := client.ApplyFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "web"})
+		requirez.ErrorIs(t, err, ErrMultipleFound)
+		requirez.Equal(t, int64(2), mutated.Load())
+	})
+}
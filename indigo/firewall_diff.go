@@ -0,0 +1,197 @@
+package indigo
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FirewallRuleChange is a rule whose type or source has changed. See DiffFirewallRules.
+type FirewallRuleChange struct {
+	From FirewallRule
+	To   FirewallRule
+}
+
+// FirewallRulesDiff is the difference between two sets of the rules of a direction.
+type FirewallRulesDiff struct {
+	// Added are the rules that are only in the desired set.
+	Added []FirewallRule
+	// Removed are the rules that are only in the current set.
+	Removed []FirewallRule
+	// Changed are the rules that are in both sets for the same protocol and port, but with a different type or source.
+	Changed []FirewallRuleChange
+}
+
+// Empty reports whether the sets are equivalent.
+func (d *FirewallRulesDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// FirewallDiff is the difference between two firewalls. See DiffFirewalls.
+type FirewallDiff struct {
+	Inbound  FirewallRulesDiff
+	Outbound FirewallRulesDiff
+}
+
+// Empty reports whether the rules of the firewalls are equivalent.
+func (d *FirewallDiff) Empty() bool {
+	return d.Inbound.Empty() && d.Outbound.Empty()
+}
+
+// String returns the difference in a human-readable form, one rule per line, in the same form as the rules of FirewallPlan.String:
+// "+" for an added rule, "-" for a removed one, and "~" for a changed one.
+func (d *FirewallDiff) String() string {
+	var b strings.Builder
+	writeFirewallDiff(&b, "", d)
+	return b.String()
+}
+
+func writeFirewallDiff(w io.Writer, indent string, d *FirewallDiff) {
+	formatRule := func(rule FirewallRule) string {
+		return strings.Join([]string{rule.Type, rule.Protocol, rule.Port, rule.Source}, " ")
+	}
+	for _, group := range []struct {
+		direction string
+		diff      *FirewallRulesDiff
+	}{{"in ", &d.Inbound}, {"out", &d.Outbound}} {
+		for _, rule := range group.diff.Added {
+			fmt.Fprintf(w, "%s+ %s %s\n", indent, group.direction, formatRule(rule))
+		}
+		for _, rule := range group.diff.Removed {
+			fmt.Fprintf(w, "%s- %s %s\n", indent, group.direction, formatRule(rule))
+		}
+		for _, change := range group.diff.Changed {
+			fmt.Fprintf(w, "%s~ %s %s => %s\n", indent, group.direction, formatRule(change.From), formatRule(change.To))
+		}
+	}
+}
+
+// DiffFirewalls returns the difference of the rules from current to desired. It does not compare the names or the IDs.
+//
+// Either side can come from the API, i.e. FirewallService.Get or NewFirewallTemplate of the output of GetWebArenaIndigoV1NwGetTemplate,
+// or from a local definition, e.g. firewallfile.File.Template.
+func DiffFirewalls(current, desired *FirewallTemplate) *FirewallDiff {
+	return &FirewallDiff{
+		Inbound:  DiffFirewallRules(current.Inbound, desired.Inbound),
+		Outbound: DiffFirewallRules(current.Outbound, desired.Outbound),
+	}
+}
+
+// DiffFirewallRules returns the difference of the rules of a direction from current to desired.
+//
+// The rules are compared as multisets after normalization, so the following differences are ignored:
+//
+//   - the order of the rules.
+//   - the case of Type and Protocol, e.g. "https" and "HTTPS".
+//   - the notation of Port, e.g. "80" and "80-80".
+//   - the notation of Source, e.g. "0.0.0.0" and "0.0.0.0/0", which both mean any address, or "192.0.2.1" and "192.0.2.1/32".
+//
+// A removed rule and an added rule for the same protocol and port are reported as a change.
+// The rules in the result are the ones in current and desired as they are.
+func DiffFirewallRules(current, desired []FirewallRule) FirewallRulesDiff {
+	remaining := slices.Clone(current)
+	var added []FirewallRule
+	for _, rule := range desired {
+		key := normalizeFirewallRule(rule)
+		if i := slices.IndexFunc(remaining, func(r FirewallRule) bool { return normalizeFirewallRule(r) == key }); i >= 0 {
+			remaining = slices.Delete(remaining, i, i+1)
+			continue
+		}
+		added = append(added, rule)
+	}
+
+	var diff FirewallRulesDiff
+	for _, rule := range added {
+		key := normalizeFirewallRule(rule)
+		i := slices.IndexFunc(remaining, func(r FirewallRule) bool {
+			n := normalizeFirewallRule(r)
+			return n.Protocol == key.Protocol && n.Port == key.Port
+		})
+		if i < 0 {
+			diff.Added = append(diff.Added, rule)
+			continue
+		}
+		diff.Changed = append(diff.Changed, FirewallRuleChange{From: remaining[i], To: rule})
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+	if len(remaining) > 0 {
+		diff.Removed = remaining
+	}
+
+	return diff
+}
+
+// normalizeFirewallRule returns the rule in the canonical form to compare it with the other rules.
+func normalizeFirewallRule(rule FirewallRule) FirewallRule {
+	return FirewallRule{
+		Type:     strings.ToLower(strings.TrimSpace(rule.Type)),
+		Protocol: strings.ToUpper(strings.TrimSpace(rule.Protocol)),
+		Port:     normalizeFirewallPort(rule.Port),
+		Source:   normalizeFirewallSource(rule.Source),
+	}
+}
+
+// normalizeFirewallPort returns "80" for "80", "080" and "80-80", and "8000-8080" for "8000-8080".
+// It returns port as it is if it is not a port or a range.
+func normalizeFirewallPort(port string) string {
+	first, last, ok := firewallPortRange(port)
+	if !ok {
+		return strings.TrimSpace(port)
+	}
+	if first == last {
+		return strconv.FormatUint(uint64(first), 10)
+	}
+	return strconv.FormatUint(uint64(first), 10) + "-" + strconv.FormatUint(uint64(last), 10)
+}
+
+// firewallPortRange returns the first and the last ports that port means, e.g. 80 and 80 for "80", and 8000 and 8080 for "8000-8080".
+func firewallPortRange(port string) (first, last uint16, ok bool) {
+	from, to, isRange := strings.Cut(strings.TrimSpace(port), "-")
+	first64, err := strconv.ParseUint(strings.TrimSpace(from), 10, 16)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !isRange {
+		return uint16(first64), uint16(first64), true //nolint:gosec // ParseUint with bitSize 16 does not overflow.
+	}
+	last64, err := strconv.ParseUint(strings.TrimSpace(to), 10, 16)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint16(first64), uint16(last64), true //nolint:gosec // ParseUint with bitSize 16 does not overflow.
+}
+
+// normalizeFirewallSource returns the prefix that source means, e.g. "0.0.0.0/0" for "0.0.0.0", which means any address,
+// and "192.0.2.1/32" for "192.0.2.1". It returns source as it is if it is neither an IP address nor a CIDR.
+func normalizeFirewallSource(source string) string {
+	prefix, ok := firewallSourcePrefix(source)
+	if !ok {
+		return strings.TrimSpace(source)
+	}
+	return prefix.String()
+}
+
+// firewallSourcePrefix returns the prefix that source means. An unspecified address, i.e. "0.0.0.0" or "::", means any address.
+func firewallSourcePrefix(source string) (netip.Prefix, bool) {
+	source = strings.TrimSpace(source)
+	if strings.Contains(source, "/") {
+		prefix, err := netip.ParsePrefix(source)
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		return prefix.Masked(), true
+	}
+
+	addr, err := netip.ParseAddr(source)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+	if addr.IsUnspecified() {
+		return netip.PrefixFrom(addr, 0), true
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
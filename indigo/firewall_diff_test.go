@@ -0,0 +1,168 @@
+package indigo
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+)
+
+func TestDiffFirewalls(t *testing.T) {
+	t.Parallel()
+
+	http80 := FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}
+	https443 := FirewallRule{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}
+	ssh22 := FirewallRule{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"}
+
+	t.Run("success,equivalent", func(t *testing.T) {
+		t.Parallel()
+
+		current := &FirewallTemplate{
+			Inbound:  []FirewallRule{http80, ssh22, http80},
+			Outbound: []FirewallRule{{Type: "Custom", Protocol: "UDP", Port: "60000-61000", Source: "2001:db8::"}},
+		}
+		desired := &FirewallTemplate{
+			Inbound: []FirewallRule{
+				{Type: "SSH", Protocol: "tcp", Port: "22-22", Source: "192.0.2.0/24"},
+				{Type: "http", Protocol: "TCP", Port: "80", Source: "0.0.0.0/0"},
+				{Type: "HTTP", Protocol: "TCP", Port: "080", Source: " 0.0.0.0 "},
+			},
+			Outbound: []FirewallRule{{Type: "custom", Protocol: "UDP", Port: "60000-61000", Source: "2001:db8::/128"}},
+		}
+		diff := DiffFirewalls(current, desired)
+		requirez.True(t, diff.Empty())
+		requirez.Equal(t, "", diff.String())
+	})
+
+	t.Run("success,changes", func(t *testing.T) {
+		t.Parallel()
+
+		sshAny := FirewallRule{Type: "SSH", Protocol: "TCP", Port: "22", Source: "0.0.0.0"}
+		current := &FirewallTemplate{Inbound: []FirewallRule{http80, http80, sshAny}, Outbound: []FirewallRule{https443}}
+		desired := &FirewallTemplate{Inbound: []FirewallRule{ssh22, https443, http80}, Outbound: nil}
+
+		diff := DiffFirewalls(current, desired)
+		requirez.False(t, diff.Empty())
+		requirez.Equal(t, FirewallRulesDiff{
+			Added:   []FirewallRule{https443},
+			Removed: []FirewallRule{http80},
+			Changed: []FirewallRuleChange{{From: sshAny, To: ssh22}},
+		}, diff.Inbound)
+		requirez.Equal(t, FirewallRulesDiff{Removed: []FirewallRule{https443}}, diff.Outbound)
+		requirez.Equal(t, `+ in  HTTPS TCP 443 0.0.0.0
+- in  HTTP TCP 80 0.0.0.0
+~ in  SSH TCP 22 0.0.0.0 => SSH TCP 22 192.0.2.0/24
+- out HTTPS TCP 443 0.0.0.0
+`, diff.String())
+	})
+
+	t.Run("success,differentSources", func(t *testing.T) {
+		t.Parallel()
+
+		// NOTE: Only an unspecified address means any address. A host and its network are different sources.
+		for _, source := range []string{"192.0.2.1", "192.0.2.0/24", "0.0.0.1", "::/0"} {
+			diff := DiffFirewallRules([]FirewallRule{http80}, []FirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: source}})
+			requirez.Equal(t, 1, len(diff.Changed))
+		}
+	})
+
+	t.Run("success,PlanFirewall", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+		_, err := client.Firewalls.Create(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "web", Inbound: []FirewallRule{http80}, Outbound: []FirewallRule{}, Instances: []int64{}})
+		requirez.NoError(t, err)
+
+		plan, err := client.PlanFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "web", Inbound: []FirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0/0"}}})
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallPlanActionNone, plan.Action)
+	})
+}
+
+//nolint:funlen
+func TestClient_CheckFirewallDrift(t *testing.T) {
+	t.Parallel()
+
+	http80 := FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}
+	ssh22 := FirewallRule{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+		create := func(name string, inbound ...FirewallRule) int64 {
+			t.Helper()
+			id, err := client.Firewalls.Create(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: name, Inbound: inbound, Outbound: []FirewallRule{}, Instances: []int64{}})
+			requirez.NoError(t, err)
+			return id
+		}
+		webID := create("web", http80, ssh22)
+		create("api", http80)
+		dup1 := create("batch", ssh22)
+		dup2 := create("batch", ssh22)
+		consoleID := create("console", http80)
+
+		desired := []*FirewallTemplate{
+			{Name: "web", Inbound: []FirewallRule{ssh22, {Type: "http", Protocol: "TCP", Port: "80", Source: "0.0.0.0/0"}}},
+			{Name: "api", Inbound: []FirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "192.0.2.0/24"}}},
+			{Name: "batch", Inbound: []FirewallRule{ssh22}},
+			{Name: "db"},
+		}
+
+		report, err := client.CheckFirewallDrift(ctx, desired)
+		requirez.NoError(t, err)
+		requirez.True(t, report.HasDrift())
+		requirez.Equal(t, 5, report.Checked)
+		requirez.Equal(t, 5, len(report.Drifts))
+
+		api := report.Drifts[0]
+		requirez.Equal(t, FirewallDriftKindChanged, api.Kind)
+		requirez.Equal(t, "api", api.Name)
+		requirez.Equal(t, []FirewallRuleChange{{From: desired[1].Inbound[0], To: http80}}, api.Diff.Inbound.Changed)
+		requirez.Equal(t, FirewallDrift{Kind: FirewallDriftKindDuplicate, Name: "batch", FirewallID: dup1}, report.Drifts[1])
+		requirez.Equal(t, FirewallDrift{Kind: FirewallDriftKindDuplicate, Name: "batch", FirewallID: dup2}, report.Drifts[2])
+		requirez.Equal(t, FirewallDrift{Kind: FirewallDriftKindUnmanaged, Name: "console", FirewallID: consoleID}, report.Drifts[3])
+		requirez.Equal(t, FirewallDrift{Kind: FirewallDriftKindMissing, Name: "db"}, report.Drifts[4])
+		requirez.Equal(t, `~ firewall "api" (id=`+strconv.FormatInt(api.FirewallID, 10)+`): changed
+  ~ in  HTTP TCP 80 192.0.2.0/24 => HTTP TCP 80 0.0.0.0
+! firewall "batch" (id=`+strconv.FormatInt(dup1, 10)+`): duplicate
+! firewall "batch" (id=`+strconv.FormatInt(dup2, 10)+`): duplicate
+? firewall "console" (id=`+strconv.FormatInt(consoleID, 10)+`): unmanaged
++ firewall "db": missing
+`, report.String())
+
+		// NOTE: "web" has not drifted, because the rules are equivalent.
+		for _, drift := range report.Drifts {
+			requirez.True(t, drift.FirewallID != webID)
+		}
+
+		report, err = client.Firewalls.CheckDrift(ctx, []*FirewallTemplate{desired[0]})
+		requirez.NoError(t, err)
+		requirez.Equal(t, 4, len(report.Drifts))
+	})
+
+	t.Run("success,noDrift", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+		report, err := client.CheckFirewallDrift(ctx, nil)
+		requirez.NoError(t, err)
+		requirez.False(t, report.HasDrift())
+		requirez.Equal(t, "", report.String())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+		_, err := client.CheckFirewallDrift(ctx, []*FirewallTemplate{{Name: "web"}, {Name: "web"}})
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		_, err = client.CheckFirewallDrift(ctx, []*FirewallTemplate{{}})
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+	})
+}
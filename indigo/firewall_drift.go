@@ -0,0 +1,169 @@
+package indigo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// FirewallDriftKind is how a firewall has drifted from the desired one.
+type FirewallDriftKind string
+
+const (
+	// FirewallDriftKindMissing is a desired firewall that does not exist.
+	FirewallDriftKindMissing FirewallDriftKind = "missing"
+	// FirewallDriftKindChanged is a firewall whose rules differ from the desired ones.
+	FirewallDriftKindChanged FirewallDriftKind = "changed"
+	// FirewallDriftKindDuplicate is a firewall that has the same name as another one, so that it is ambiguous which one is desired.
+	FirewallDriftKindDuplicate FirewallDriftKind = "duplicate"
+	// FirewallDriftKindUnmanaged is a firewall that is not desired, e.g. the one created in the web console.
+	FirewallDriftKindUnmanaged FirewallDriftKind = "unmanaged"
+)
+
+// FirewallDrift is a firewall that has drifted from the desired one.
+type FirewallDrift struct {
+	Kind FirewallDriftKind
+	Name string
+	// FirewallID is the ID of the firewall. It is 0 for FirewallDriftKindMissing.
+	FirewallID int64
+	// Diff is the difference of the rules from the desired ones to the current ones for FirewallDriftKindChanged, and nil otherwise,
+	// i.e. Added are the rules that have been added to the firewall.
+	Diff *FirewallDiff
+}
+
+// FirewallDriftReport is the result of CheckFirewallDrift.
+type FirewallDriftReport struct {
+	// Checked is the number of the firewalls that exist.
+	Checked int
+	// Drifts are the drifted firewalls, sorted by the name and the ID.
+	Drifts []FirewallDrift
+}
+
+// HasDrift reports whether any firewall has drifted.
+func (r *FirewallDriftReport) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+// String returns the report in a human-readable form, e.g.
+//
+//	~ firewall "web" (id=5): changed
+//	  ~ in  SSH TCP 22 192.0.2.0/24 => SSH TCP 22 0.0.0.0
+//	+ firewall "db": missing
+//	? firewall "test" (id=7): unmanaged
+func (r *FirewallDriftReport) String() string {
+	var b strings.Builder
+	for _, drift := range r.Drifts {
+		mark := map[FirewallDriftKind]string{
+			FirewallDriftKindMissing:   "+",
+			FirewallDriftKindChanged:   "~",
+			FirewallDriftKindDuplicate: "!",
+			FirewallDriftKindUnmanaged: "?",
+		}[drift.Kind]
+		fmt.Fprintf(&b, "%s firewall %q", mark, drift.Name)
+		if drift.FirewallID != 0 {
+			fmt.Fprintf(&b, " (id=%d)", drift.FirewallID)
+		}
+		fmt.Fprintf(&b, ": %s\n", drift.Kind)
+		if drift.Diff != nil {
+			writeFirewallDiff(&b, "  ", drift.Diff)
+		}
+	}
+	return b.String()
+}
+
+// CheckFirewallDrift compares every firewall in GetWebArenaIndigoV1NwGetFirewallList with the desired ones by the name,
+// and reports the firewalls that have drifted, e.g. because someone has edited them in the web console.
+// The rules are compared as DiffFirewalls does, and the IDs and the instances of desired are ignored.
+//
+// It calls GetWebArenaIndigoV1NwGetTemplate for each firewall of a desired name, so it makes as many API calls as the firewalls,
+// which count towards the rate limit of the API. It returns ErrInvalidArgument if desired has a name more than once.
+//
+// Example:
+//
+//	report, err := client.CheckFirewallDrift(ctx, []*indigo.FirewallTemplate{file.Template()}) // file is a firewallfile.File.
+//	if err != nil {
+//		return err
+//	}
+//	if report.HasDrift() {
+//		alert(report.String())
+//	}
+func (c *Client) CheckFirewallDrift(ctx context.Context, desired []*FirewallTemplate) (*FirewallDriftReport, error) {
+	ctx, span := c.start(ctx)
+	defer span.End()
+
+	report, err := c.checkFirewallDrift(ctx, desired)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.checkFirewallDrift: %w", err)
+	}
+	span.SetAttributes(attributeKeyFirewallCount.Int(report.Checked))
+
+	return report, nil
+}
+
+func (c *Client) checkFirewallDrift(ctx context.Context, desired []*FirewallTemplate) (*FirewallDriftReport, error) {
+	desiredByName := make(map[string]*FirewallTemplate, len(desired))
+	for _, template := range desired {
+		if template == nil || template.Name == "" {
+			return nil, errorz.Errorf("name is empty: %w", ErrInvalidArgument)
+		}
+		if _, ok := desiredByName[template.Name]; ok {
+			return nil, errorz.Errorf("name=%q: duplicate desired firewall: %w", template.Name, ErrInvalidArgument)
+		}
+		desiredByName[template.Name] = template
+	}
+
+	firewalls, err := c.GetWebArenaIndigoV1NwGetFirewallList(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1NwGetFirewallList: %w", err)
+	}
+
+	countByName := make(map[string]int, len(*firewalls))
+	for _, firewall := range *firewalls {
+		countByName[firewall.Name]++
+	}
+
+	report := &FirewallDriftReport{Checked: len(*firewalls)}
+	for _, firewall := range *firewalls {
+		want, ok := desiredByName[firewall.Name]
+		switch {
+		case !ok:
+			report.Drifts = append(report.Drifts, FirewallDrift{Kind: FirewallDriftKindUnmanaged, Name: firewall.Name, FirewallID: firewall.ID})
+			continue
+		case countByName[firewall.Name] > 1:
+			report.Drifts = append(report.Drifts, FirewallDrift{Kind: FirewallDriftKindDuplicate, Name: firewall.Name, FirewallID: firewall.ID})
+			continue
+		}
+
+		rows, err := c.GetWebArenaIndigoV1NwGetTemplate(ctx, firewall.ID)
+		if err != nil {
+			return nil, errorz.Errorf("c.GetWebArenaIndigoV1NwGetTemplate: firewallID=%d: %w", firewall.ID, err)
+		}
+		current, err := NewFirewallTemplate(firewall.ID, *rows)
+		if err != nil {
+			return nil, errorz.Errorf("NewFirewallTemplate: %w", err)
+		}
+		if diff := DiffFirewalls(want, current); !diff.Empty() {
+			report.Drifts = append(report.Drifts, FirewallDrift{Kind: FirewallDriftKindChanged, Name: firewall.Name, FirewallID: firewall.ID, Diff: diff})
+		}
+	}
+
+	for _, template := range desired {
+		if countByName[template.Name] == 0 {
+			report.Drifts = append(report.Drifts, FirewallDrift{Kind: FirewallDriftKindMissing, Name: template.Name})
+		}
+	}
+
+	slices.SortStableFunc(report.Drifts, func(a, b FirewallDrift) int {
+		if n := strings.Compare(a.Name, b.Name); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.FirewallID, b.FirewallID)
+	})
+
+	return report, nil
+}
@@ -0,0 +1,448 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// FirewallLintSeverity is how risky a FirewallLintFinding is.
+type FirewallLintSeverity string
+
+const (
+	// FirewallLintSeverityLow is a rule that is not risky by itself but makes the firewall harder to review, e.g. a duplicate.
+	FirewallLintSeverityLow FirewallLintSeverity = "low"
+	// FirewallLintSeverityMedium is a rule that allows more than it likely needs to.
+	FirewallLintSeverityMedium FirewallLintSeverity = "medium"
+	// FirewallLintSeverityHigh is a rule that exposes a service to the internet.
+	FirewallLintSeverityHigh FirewallLintSeverity = "high"
+)
+
+func (s FirewallLintSeverity) rank() int {
+	switch s {
+	case FirewallLintSeverityLow:
+		return 1
+	case FirewallLintSeverityMedium:
+		return 2 //nolint:mnd
+	case FirewallLintSeverityHigh:
+		return 3 //nolint:mnd
+	default:
+		return 0
+	}
+}
+
+// The IDs of the checks of DefaultFirewallLintChecks.
+const (
+	// FirewallLintCheckRemoteAccessOpen reports an inbound rule that opens SSH or RDP to any address.
+	FirewallLintCheckRemoteAccessOpen = "remote-access-open"
+	// FirewallLintCheckDatabaseOpen reports an inbound rule that opens the port of a database, e.g. MySQL or PostgreSQL, to any address.
+	FirewallLintCheckDatabaseOpen = "database-open"
+	// FirewallLintCheckAllPortsOpen reports an inbound rule that opens all the ports to any address.
+	FirewallLintCheckAllPortsOpen = "all-ports-open"
+	// FirewallLintCheckOutboundAllowAll reports an outbound rule that allows all the ports to any address.
+	FirewallLintCheckOutboundAllowAll = "outbound-allow-all"
+	// FirewallLintCheckDuplicateRule reports a rule that allows the same protocol, port and source as a preceding rule.
+	FirewallLintCheckDuplicateRule = "duplicate-rule"
+	// FirewallLintCheckShadowedRule reports a rule whose traffic is all allowed by another, broader rule.
+	FirewallLintCheckShadowedRule = "shadowed-rule"
+	// FirewallLintCheckInvalidRule reports a rule that WebArenaIndigoV1NwFirewallRule.Validate rejects, with the reason.
+	// The other checks skip such a rule, because it is unknown which traffic it allows.
+	FirewallLintCheckInvalidRule = "invalid-rule"
+)
+
+// FirewallLintCheck is a check of FirewallLinter.
+type FirewallLintCheck struct {
+	// ID identifies the check in FirewallLintFinding.CheckID, e.g. to suppress it by FirewallLinterOptionWithoutChecks.
+	ID       string
+	Severity FirewallLintSeverity
+	// Check returns the findings of the rules of a direction. It sets Index and Message of each finding,
+	// and FirewallLinter sets the other fields. The rules may be invalid, see WebArenaIndigoV1NwFirewallRule.Validate.
+	Check func(direction FirewallDirection, rules []FirewallRule) []FirewallLintFinding
+}
+
+// FirewallLintFinding is a risky rule found by FirewallLinter.
+type FirewallLintFinding struct {
+	// CheckID is the ID of the FirewallLintCheck, e.g. FirewallLintCheckRemoteAccessOpen.
+	CheckID  string
+	Severity FirewallLintSeverity
+	// FirewallID and FirewallName are those of the firewall of the rule. They are zero for FirewallLinter.LintRules.
+	FirewallID   int64
+	FirewallName string
+	// Direction and Index locate the rule in the firewall.
+	Direction FirewallDirection
+	Index     int
+	Rule      FirewallRule
+	Message   string
+}
+
+// String returns the finding in a human-readable form, e.g.
+//
+//	high remote-access-open: firewall "web" inbound[0] SSH TCP 22 0.0.0.0: SSH (22) is open to any address
+func (f FirewallLintFinding) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s: ", f.Severity, f.CheckID)
+	if f.FirewallName != "" {
+		fmt.Fprintf(&b, "firewall %q ", f.FirewallName)
+	}
+	fmt.Fprintf(&b, "%s[%d] %s %s %s %s: %s", firewallDirectionName(f.Direction), f.Index, f.Rule.Type, f.Rule.Protocol, f.Rule.Port, f.Rule.Source, f.Message)
+	return b.String()
+}
+
+// FirewallLintFindings are the findings of FirewallLinter, in the order of the rules.
+type FirewallLintFindings []FirewallLintFinding
+
+// AtLeast returns the findings whose severity is severity or higher, e.g. to fail a deployment on FirewallLintSeverityHigh.
+func (f FirewallLintFindings) AtLeast(severity FirewallLintSeverity) FirewallLintFindings {
+	var found FirewallLintFindings
+	for _, finding := range f {
+		if finding.Severity.rank() >= severity.rank() {
+			found = append(found, finding)
+		}
+	}
+	return found
+}
+
+// String returns the findings in a human-readable form, one per line.
+func (f FirewallLintFindings) String() string {
+	var b strings.Builder
+	for _, finding := range f {
+		b.WriteString(finding.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// FirewallLinter checks firewall rules for the ones that are risky, e.g. SSH open to any address.
+// The rules are compared after normalization, as DiffFirewalls does.
+//
+// Example:
+//
+//	linter := indigo.NewFirewallLinter(indigo.FirewallLinterOptionWithoutChecks(indigo.FirewallLintCheckShadowedRule))
+//	if findings := linter.LintRequest(req).AtLeast(indigo.FirewallLintSeverityHigh); len(findings) > 0 {
+//		return fmt.Errorf("risky firewall rules:\n%s", findings)
+//	}
+//	plan, err := client.ApplyFirewall(ctx, req)
+type FirewallLinter struct {
+	checks   []FirewallLintCheck
+	suppress func(finding FirewallLintFinding) bool
+}
+
+// FirewallLinterOption configures a FirewallLinter.
+type FirewallLinterOption interface {
+	apply(l *FirewallLinter)
+}
+
+type firewallLintChecksOption struct{ checks []FirewallLintCheck }
+
+func (o *firewallLintChecksOption) apply(l *FirewallLinter) {
+	for _, check := range o.checks {
+		if i := slices.IndexFunc(l.checks, func(c FirewallLintCheck) bool { return c.ID == check.ID }); i >= 0 {
+			l.checks[i] = check
+			continue
+		}
+		l.checks = append(l.checks, check)
+	}
+}
+
+// FirewallLinterOptionWithChecks adds the checks to the linter. A check replaces the one of the same ID,
+// e.g. to change the severity of a check of DefaultFirewallLintChecks.
+func FirewallLinterOptionWithChecks(checks ...FirewallLintCheck) FirewallLinterOption { //nolint:ireturn
+	return &firewallLintChecksOption{checks: checks}
+}
+
+type firewallLintWithoutChecksOption struct{ ids []string }
+
+func (o *firewallLintWithoutChecksOption) apply(l *FirewallLinter) {
+	l.checks = slices.DeleteFunc(l.checks, func(c FirewallLintCheck) bool { return slices.Contains(o.ids, c.ID) })
+}
+
+// FirewallLinterOptionWithoutChecks removes the checks of the IDs from the linter.
+func FirewallLinterOptionWithoutChecks(ids ...string) FirewallLinterOption { //nolint:ireturn
+	return &firewallLintWithoutChecksOption{ids: ids}
+}
+
+type firewallLintSuppressionOption struct {
+	suppress func(finding FirewallLintFinding) bool
+}
+
+func (o *firewallLintSuppressionOption) apply(l *FirewallLinter) {
+	if previous := l.suppress; previous != nil {
+		l.suppress = func(finding FirewallLintFinding) bool { return previous(finding) || o.suppress(finding) }
+		return
+	}
+	l.suppress = o.suppress
+}
+
+// FirewallLinterOptionWithSuppression suppresses the findings for which suppress returns true,
+// e.g. SSH open to any address only on the firewall of a bastion host.
+func FirewallLinterOptionWithSuppression(suppress func(finding FirewallLintFinding) bool) FirewallLinterOption { //nolint:ireturn
+	return &firewallLintSuppressionOption{suppress: suppress}
+}
+
+// NewFirewallLinter returns a linter with DefaultFirewallLintChecks, configured by the options.
+func NewFirewallLinter(opts ...FirewallLinterOption) *FirewallLinter {
+	l := &FirewallLinter{checks: DefaultFirewallLintChecks()}
+	for _, opt := range opts {
+		opt.apply(l)
+	}
+	return l
+}
+
+// DefaultFirewallLintChecks returns the checks that NewFirewallLinter uses by default:
+//
+//   - FirewallLintCheckRemoteAccessOpen: FirewallLintSeverityHigh.
+//   - FirewallLintCheckDatabaseOpen: FirewallLintSeverityHigh.
+//   - FirewallLintCheckAllPortsOpen: FirewallLintSeverityHigh.
+//   - FirewallLintCheckOutboundAllowAll: FirewallLintSeverityMedium.
+//   - FirewallLintCheckInvalidRule: FirewallLintSeverityMedium.
+//   - FirewallLintCheckDuplicateRule: FirewallLintSeverityLow.
+//   - FirewallLintCheckShadowedRule: FirewallLintSeverityLow.
+func DefaultFirewallLintChecks() []FirewallLintCheck {
+	return []FirewallLintCheck{
+		{ID: FirewallLintCheckRemoteAccessOpen, Severity: FirewallLintSeverityHigh, Check: firewallServicePortsOpenCheck(firewallRemoteAccessPorts)},
+		{ID: FirewallLintCheckDatabaseOpen, Severity: FirewallLintSeverityHigh, Check: firewallServicePortsOpenCheck(firewallDatabasePorts)},
+		{ID: FirewallLintCheckAllPortsOpen, Severity: FirewallLintSeverityHigh, Check: checkFirewallAllPortsOpen},
+		{ID: FirewallLintCheckOutboundAllowAll, Severity: FirewallLintSeverityMedium, Check: checkFirewallOutboundAllowAll},
+		{ID: FirewallLintCheckInvalidRule, Severity: FirewallLintSeverityMedium, Check: checkFirewallInvalidRule},
+		{ID: FirewallLintCheckDuplicateRule, Severity: FirewallLintSeverityLow, Check: checkFirewallDuplicateRule},
+		{ID: FirewallLintCheckShadowedRule, Severity: FirewallLintSeverityLow, Check: checkFirewallShadowedRule},
+	}
+}
+
+// LintRules checks the rules of a direction, e.g. before they are sent.
+func (l *FirewallLinter) LintRules(direction FirewallDirection, rules []FirewallRule) FirewallLintFindings {
+	return l.lint(&FirewallTemplate{}, direction, rules)
+}
+
+// LintTemplate checks the rules of a firewall, e.g. the one returned by FirewallService.Get for an audit.
+func (l *FirewallLinter) LintTemplate(template *FirewallTemplate) FirewallLintFindings {
+	return append(l.lint(template, FirewallDirectionInbound, template.Inbound), l.lint(template, FirewallDirectionOutbound, template.Outbound)...)
+}
+
+// LintRequest checks the rules of the request before it is sent, e.g. to PostWebArenaIndigoV1NwCreateFirewall or ApplyFirewall.
+func (l *FirewallLinter) LintRequest(req *PostWebArenaIndigoV1NwCreateFirewallRequest) FirewallLintFindings {
+	return l.LintTemplate(&FirewallTemplate{Name: req.Name, Inbound: req.Inbound, Outbound: req.Outbound})
+}
+
+func (l *FirewallLinter) lint(template *FirewallTemplate, direction FirewallDirection, rules []FirewallRule) FirewallLintFindings {
+	var findings FirewallLintFindings
+	for _, check := range l.checks {
+		for _, finding := range check.Check(direction, rules) {
+			finding.CheckID, finding.Severity = check.ID, check.Severity
+			finding.FirewallID, finding.FirewallName = template.ID, template.Name
+			finding.Direction = direction
+			if finding.Index >= 0 && finding.Index < len(rules) {
+				finding.Rule = rules[finding.Index]
+			}
+			if l.suppress != nil && l.suppress(finding) {
+				continue
+			}
+			findings = append(findings, finding)
+		}
+	}
+	// NOTE: Stable, so that the findings of a rule keep the order of the checks.
+	slices.SortStableFunc(findings, func(a, b FirewallLintFinding) int { return a.Index - b.Index })
+	return findings
+}
+
+// AuditFirewalls checks every firewall in GetWebArenaIndigoV1NwGetFirewallList by linter, or by NewFirewallLinter() if linter is nil.
+// It calls GetWebArenaIndigoV1NwGetTemplate for each firewall, so it makes as many API calls as the firewalls,
+// which count towards the rate limit of the API.
+func (c *Client) AuditFirewalls(ctx context.Context, linter *FirewallLinter) (FirewallLintFindings, error) {
+	ctx, span := c.start(ctx)
+	defer span.End()
+
+	if linter == nil {
+		linter = NewFirewallLinter()
+	}
+
+	firewalls, err := c.GetWebArenaIndigoV1NwGetFirewallList(ctx)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1NwGetFirewallList: %w", err)
+	}
+	span.SetAttributes(attributeKeyFirewallCount.Int(len(*firewalls)))
+
+	var findings FirewallLintFindings
+	for _, firewall := range *firewalls {
+		rows, err := c.GetWebArenaIndigoV1NwGetTemplate(ctx, firewall.ID)
+		if err != nil {
+			recordError(span, err)
+			return nil, errorz.Errorf("c.GetWebArenaIndigoV1NwGetTemplate: firewallID=%d: %w", firewall.ID, err)
+		}
+		template, err := NewFirewallTemplate(firewall.ID, *rows)
+		if err != nil {
+			recordError(span, err)
+			return nil, errorz.Errorf("NewFirewallTemplate: %w", err)
+		}
+		template.Name = firewall.Name
+		findings = append(findings, linter.LintTemplate(template)...)
+	}
+
+	return findings, nil
+}
+
+type firewallServicePort struct {
+	name string
+	port uint16
+}
+
+//nolint:gochecknoglobals
+var (
+	firewallRemoteAccessPorts = []firewallServicePort{{"SSH", 22}, {"RDP", 3389}}
+	firewallDatabasePorts     = []firewallServicePort{
+		{"MySQL", 3306}, {"PostgreSQL", 5432}, {"SQL Server", 1433}, {"Oracle", 1521}, {"MongoDB", 27017}, {"Redis", 6379},
+	}
+)
+
+// firewallRuleScope is a rule parsed to check which traffic it allows.
+type firewallRuleScope struct {
+	protocol    string
+	first, last uint16
+	source      netip.Prefix
+}
+
+// parseFirewallRuleScope returns the scope of the rule, or false if the rule is invalid. The invalid rule is reported by FirewallLintCheckInvalidRule,
+// so the rule that Validate rejects is not checked by the other checks.
+func parseFirewallRuleScope(rule FirewallRule) (firewallRuleScope, bool) {
+	if rule.Validate() != nil {
+		return firewallRuleScope{}, false
+	}
+	normalized := normalizeFirewallRule(rule)
+	source, ok := firewallSourcePrefix(rule.Source)
+	if !ok {
+		return firewallRuleScope{}, false
+	}
+	scope := firewallRuleScope{protocol: normalized.Protocol, source: source}
+	switch FirewallProtocol(normalized.Protocol) {
+	case FirewallProtocolTCP, FirewallProtocolUDP:
+		if scope.first, scope.last, ok = firewallPortRange(rule.Port); !ok || scope.first > scope.last {
+			return firewallRuleScope{}, false
+		}
+	case FirewallProtocolICMP:
+	default:
+		return firewallRuleScope{}, false
+	}
+	return scope, true
+}
+
+// anySource reports whether the rule allows any address.
+func (s firewallRuleScope) anySource() bool { return s.source.Bits() == 0 }
+
+// allPorts reports whether the rule allows all the ports of TCP or UDP.
+func (s firewallRuleScope) allPorts() bool {
+	return s.protocol != string(FirewallProtocolICMP) && s.first <= 1 && s.last == math.MaxUint16
+}
+
+// covers reports whether s allows all the traffic that other allows.
+func (s firewallRuleScope) covers(other firewallRuleScope) bool {
+	return s.protocol == other.protocol && s.first <= other.first && other.last <= s.last &&
+		s.source.Bits() <= other.source.Bits() && s.source.Contains(other.source.Addr())
+}
+
+func firewallServicePortsOpenCheck(services []firewallServicePort) func(FirewallDirection, []FirewallRule) []FirewallLintFinding {
+	return func(direction FirewallDirection, rules []FirewallRule) []FirewallLintFinding {
+		if direction != FirewallDirectionInbound {
+			return nil
+		}
+		var findings []FirewallLintFinding
+		for i, rule := range rules {
+			scope, ok := parseFirewallRuleScope(rule)
+			// NOTE: A rule that opens all the ports is reported by FirewallLintCheckAllPortsOpen instead.
+			if !ok || scope.protocol != string(FirewallProtocolTCP) || !scope.anySource() || scope.allPorts() {
+				continue
+			}
+			var open []string
+			for _, service := range services {
+				if scope.first <= service.port && service.port <= scope.last {
+					open = append(open, fmt.Sprintf("%s (%d)", service.name, service.port))
+				}
+			}
+			if len(open) > 0 {
+				findings = append(findings, FirewallLintFinding{Index: i, Message: strings.Join(open, ", ") + " is open to any address"})
+			}
+		}
+		return findings
+	}
+}
+
+func checkFirewallAllPortsOpen(direction FirewallDirection, rules []FirewallRule) []FirewallLintFinding {
+	if direction != FirewallDirectionInbound {
+		return nil
+	}
+	var findings []FirewallLintFinding
+	for i, rule := range rules {
+		if scope, ok := parseFirewallRuleScope(rule); ok && scope.anySource() && scope.allPorts() {
+			findings = append(findings, FirewallLintFinding{Index: i, Message: "all the " + scope.protocol + " ports are open to any address"})
+		}
+	}
+	return findings
+}
+
+func checkFirewallOutboundAllowAll(direction FirewallDirection, rules []FirewallRule) []FirewallLintFinding {
+	if direction != FirewallDirectionOutbound {
+		return nil
+	}
+	var findings []FirewallLintFinding
+	for i, rule := range rules {
+		if scope, ok := parseFirewallRuleScope(rule); ok && scope.anySource() && scope.allPorts() {
+			findings = append(findings, FirewallLintFinding{Index: i, Message: "all the " + scope.protocol + " ports to any address are allowed"})
+		}
+	}
+	return findings
+}
+
+func checkFirewallInvalidRule(_ FirewallDirection, rules []FirewallRule) []FirewallLintFinding {
+	var findings []FirewallLintFinding
+	for i, rule := range rules {
+		if ruleErr := (*FirewallRuleError)(nil); errors.As(rule.Validate(), &ruleErr) {
+			findings = append(findings, FirewallLintFinding{Index: i, Message: fmt.Sprintf("%s=%q: %s", ruleErr.Field, ruleErr.Value, ruleErr.Reason)})
+		}
+	}
+	return findings
+}
+
+func checkFirewallDuplicateRule(_ FirewallDirection, rules []FirewallRule) []FirewallLintFinding {
+	var findings []FirewallLintFinding
+	for i, rule := range rules {
+		scope, ok := parseFirewallRuleScope(rule)
+		if !ok {
+			continue
+		}
+		// NOTE: The type is only a label, so the rules of different types for the same traffic are duplicates.
+		if j := slices.IndexFunc(rules[:i], func(r FirewallRule) bool { s, ok := parseFirewallRuleScope(r); return ok && s == scope }); j >= 0 {
+			findings = append(findings, FirewallLintFinding{Index: i, Message: fmt.Sprintf("duplicates [%d]", j)})
+		}
+	}
+	return findings
+}
+
+func checkFirewallShadowedRule(_ FirewallDirection, rules []FirewallRule) []FirewallLintFinding {
+	scopes := make([]firewallRuleScope, len(rules))
+	valid := make([]bool, len(rules))
+	for i, rule := range rules {
+		scopes[i], valid[i] = parseFirewallRuleScope(rule)
+	}
+
+	var findings []FirewallLintFinding
+	for i := range rules {
+		if !valid[i] {
+			continue
+		}
+		for j := range rules {
+			// NOTE: The rules for the same traffic cover each other, and are reported by FirewallLintCheckDuplicateRule instead.
+			if j == i || !valid[j] || scopes[j] == scopes[i] || !scopes[j].covers(scopes[i]) {
+				continue
+			}
+			findings = append(findings, FirewallLintFinding{Index: i, Message: fmt.Sprintf("is shadowed by [%d] %s %s %s %s", j, rules[j].Type, rules[j].Protocol, rules[j].Port, rules[j].Source)})
+			break
+		}
+	}
+	return findings
+}
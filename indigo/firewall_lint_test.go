@@ -0,0 +1,148 @@
+package indigo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+)
+
+func lintCheckIDs(findings FirewallLintFindings) []string {
+	ids := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		ids = append(ids, finding.CheckID)
+	}
+	return ids
+}
+
+//nolint:funlen
+func TestFirewallLinter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,checks", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name      string
+			direction FirewallDirection
+			rules     []FirewallRule
+			want      []string
+		}{
+			{name: "sshAny", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "SSH", Protocol: "TCP", Port: "22", Source: "0.0.0.0"}}, want: []string{FirewallLintCheckRemoteAccessOpen}},
+			{name: "rdpAnyPrefix", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "RDP", Protocol: "TCP", Port: "3389", Source: "0.0.0.0/0"}}, want: []string{FirewallLintCheckRemoteAccessOpen}},
+			{name: "sshInRange", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "Custom", Protocol: "TCP", Port: "20-25", Source: "::/0"}}, want: []string{FirewallLintCheckRemoteAccessOpen}},
+			{name: "sshRestricted", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"}}, want: []string{}},
+			{name: "sshOutbound", direction: FirewallDirectionOutbound, rules: []FirewallRule{{Type: "SSH", Protocol: "TCP", Port: "22", Source: "0.0.0.0"}}, want: []string{}},
+			{name: "database", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "MySQL", Protocol: "TCP", Port: "3306", Source: "0.0.0.0"}, {Type: "Custom", Protocol: "TCP", Port: "5432", Source: "0.0.0.0"}}, want: []string{FirewallLintCheckDatabaseOpen, FirewallLintCheckDatabaseOpen}},
+			{name: "allPorts", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "Custom", Protocol: "TCP", Port: "1-65535", Source: "0.0.0.0"}}, want: []string{FirewallLintCheckAllPortsOpen}},
+			{name: "outboundAllowAll", direction: FirewallDirectionOutbound, rules: []FirewallRule{{Type: "Custom", Protocol: "UDP", Port: "1-65535", Source: "0.0.0.0/0"}}, want: []string{FirewallLintCheckOutboundAllowAll}},
+			{name: "duplicate", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}, {Type: "Custom", Protocol: "TCP", Port: "80-80", Source: "0.0.0.0/0"}}, want: []string{FirewallLintCheckDuplicateRule}},
+			{name: "shadowed", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "Custom", Protocol: "TCP", Port: "8080", Source: "192.0.2.1"}, {Type: "Custom", Protocol: "TCP", Port: "8000-8999", Source: "192.0.2.0/24"}}, want: []string{FirewallLintCheckShadowedRule}},
+			{name: "notShadowed", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "Custom", Protocol: "UDP", Port: "8080", Source: "192.0.2.1"}, {Type: "Custom", Protocol: "TCP", Port: "8000-8999", Source: "192.0.2.0/24"}}, want: []string{}},
+			{name: "invalid", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "SSH", Protocol: "TCP", Port: "ssh", Source: "0.0.0.0"}}, want: []string{FirewallLintCheckInvalidRule}},
+			{name: "invalidOutbound", direction: FirewallDirectionOutbound, rules: []FirewallRule{{Type: "Custom", Protocol: "TCP", Port: "1-65535", Source: "0.0.0.0/33"}}, want: []string{FirewallLintCheckInvalidRule}},
+			{name: "invalidNotChecked", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "", Protocol: "TCP", Port: "22", Source: "0.0.0.0"}, {Type: "Custom", Protocol: " TCP", Port: "22", Source: "0.0.0.0"}, {Type: "Custom", Protocol: "UDP", Port: "0-65535", Source: "0.0.0.0"}}, want: []string{FirewallLintCheckInvalidRule, FirewallLintCheckInvalidRule, FirewallLintCheckInvalidRule}},
+			{name: "lowercaseProtocol", direction: FirewallDirectionInbound, rules: []FirewallRule{{Type: "SSH", Protocol: "tcp", Port: "22", Source: "0.0.0.0"}}, want: []string{FirewallLintCheckRemoteAccessOpen}},
+		}
+		linter := NewFirewallLinter()
+		for _, tt := range tests {
+			requirez.Equal(t, tt.want, lintCheckIDs(linter.LintRules(tt.direction, tt.rules)))
+		}
+	})
+
+	t.Run("success,LintRequest", func(t *testing.T) {
+		t.Parallel()
+
+		req := &PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name: "web",
+			Inbound: []FirewallRule{
+				{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"},
+				{Type: "SSH", Protocol: "TCP", Port: "22", Source: "0.0.0.0"},
+				{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"},
+			},
+			Outbound: []FirewallRule{{Type: "Custom", Protocol: "TCP", Port: "1-65535", Source: "0.0.0.0"}},
+		}
+		findings := NewFirewallLinter().LintRequest(req)
+		requirez.Equal(t, `high remote-access-open: firewall "web" inbound[1] SSH TCP 22 0.0.0.0: SSH (22) is open to any address
+low shadowed-rule: firewall "web" inbound[2] SSH TCP 22 192.0.2.0/24: is shadowed by [1] SSH TCP 22 0.0.0.0
+medium outbound-allow-all: firewall "web" outbound[0] Custom TCP 1-65535 0.0.0.0: all the TCP ports to any address are allowed
+`, findings.String())
+		requirez.Equal(t, []string{FirewallLintCheckRemoteAccessOpen}, lintCheckIDs(findings.AtLeast(FirewallLintSeverityHigh)))
+		requirez.Equal(t, []string{FirewallLintCheckRemoteAccessOpen, FirewallLintCheckOutboundAllowAll}, lintCheckIDs(findings.AtLeast(FirewallLintSeverityMedium)))
+		requirez.Equal(t, FirewallDirectionOutbound, findings[2].Direction)
+		requirez.Equal(t, req.Outbound[0], findings[2].Rule)
+	})
+
+	t.Run("success,options", func(t *testing.T) {
+		t.Parallel()
+
+		rules := []FirewallRule{
+			{Type: "SSH", Protocol: "TCP", Port: "22", Source: "0.0.0.0"},
+			{Type: "Custom", Protocol: "TCP", Port: "8080", Source: "0.0.0.0"},
+			{Type: "SSH", Protocol: "TCP", Port: "22", Source: "0.0.0.0"},
+		}
+		linter := NewFirewallLinter(
+			FirewallLinterOptionWithoutChecks(FirewallLintCheckDuplicateRule),
+			FirewallLinterOptionWithSuppression(func(finding FirewallLintFinding) bool {
+				return finding.CheckID == FirewallLintCheckRemoteAccessOpen && finding.Index == 0
+			}),
+			FirewallLinterOptionWithChecks(
+				FirewallLintCheck{ID: FirewallLintCheckRemoteAccessOpen, Severity: FirewallLintSeverityMedium, Check: DefaultFirewallLintChecks()[0].Check},
+				FirewallLintCheck{ID: "no-custom", Severity: FirewallLintSeverityLow, Check: func(_ FirewallDirection, rules []FirewallRule) []FirewallLintFinding {
+					var findings []FirewallLintFinding
+					for i, rule := range rules {
+						if rule.Type == FirewallRuleTypeCustom {
+							findings = append(findings, FirewallLintFinding{Index: i, Message: "use a preset"})
+						}
+					}
+					return findings
+				}},
+			),
+		)
+		findings := linter.LintRules(FirewallDirectionInbound, rules)
+		requirez.Equal(t, []string{"no-custom", FirewallLintCheckRemoteAccessOpen}, lintCheckIDs(findings))
+		requirez.Equal(t, FirewallLintSeverityMedium, findings[1].Severity)
+		requirez.Equal(t, 2, findings[1].Index)
+		requirez.Equal(t, "medium remote-access-open: inbound[2] SSH TCP 22 0.0.0.0: SSH (22) is open to any address", findings[1].String())
+
+		findings = NewFirewallLinter().LintRules(FirewallDirectionInbound, []FirewallRule{{Type: "SSH", Protocol: "TCP", Port: "ssh", Source: "0.0.0.0"}})
+		requirez.Equal(t, `medium invalid-rule: inbound[0] SSH TCP ssh 0.0.0.0: port="ssh": must be a port from 1 to 65535, or a range of them such as 8000-8080`, findings[0].String())
+	})
+}
+
+func TestClient_AuditFirewalls(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+		webID, err := client.Firewalls.Create(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:      "web",
+			Inbound:   []FirewallRule{{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}, {Type: "MySQL", Protocol: "TCP", Port: "3306", Source: "0.0.0.0"}},
+			Outbound:  []FirewallRule{},
+			Instances: []int64{},
+		})
+		requirez.NoError(t, err)
+		_, err = client.Firewalls.Create(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "empty", Inbound: []FirewallRule{}, Outbound: []FirewallRule{}, Instances: []int64{}})
+		requirez.NoError(t, err)
+
+		findings, err := client.AuditFirewalls(ctx, nil)
+		requirez.NoError(t, err)
+		requirez.Equal(t, FirewallLintFindings{{
+			CheckID:      FirewallLintCheckDatabaseOpen,
+			Severity:     FirewallLintSeverityHigh,
+			FirewallID:   webID,
+			FirewallName: "web",
+			Direction:    FirewallDirectionInbound,
+			Index:        1,
+			Rule:         FirewallRule{Type: "MySQL", Protocol: "TCP", Port: "3306", Source: "0.0.0.0"},
+			Message:      "MySQL (3306) is open to any address",
+		}}, findings)
+
+		findings, err = client.Firewalls.Audit(ctx, NewFirewallLinter(FirewallLinterOptionWithoutChecks(FirewallLintCheckDatabaseOpen)))
+		requirez.NoError(t, err)
+		requirez.Equal(t, 0, len(findings))
+	})
+}
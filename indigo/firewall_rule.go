@@ -0,0 +1,252 @@
+package indigo
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// FirewallProtocol is the protocol of a firewall rule.
+type FirewallProtocol string
+
+const (
+	FirewallProtocolTCP  FirewallProtocol = "TCP"
+	FirewallProtocolUDP  FirewallProtocol = "UDP"
+	FirewallProtocolICMP FirewallProtocol = "ICMP"
+)
+
+// FirewallRuleTypeCustom is the Type of the rules that are not of a well-known service.
+const FirewallRuleTypeCustom = "Custom"
+
+// FirewallSourceAny is the Source of the rules that allow any address.
+const FirewallSourceAny = "0.0.0.0"
+
+// FirewallPreset is the type, the protocol and the port of a well-known service, to start a FirewallRuleBuilder with.
+type FirewallPreset struct {
+	Type     string
+	Protocol FirewallProtocol
+	Port     string
+}
+
+// The presets of the well-known services.
+//
+//nolint:gochecknoglobals
+var (
+	FirewallPresetHTTP       = FirewallPreset{Type: "HTTP", Protocol: FirewallProtocolTCP, Port: "80"}
+	FirewallPresetHTTPS      = FirewallPreset{Type: "HTTPS", Protocol: FirewallProtocolTCP, Port: "443"}
+	FirewallPresetSSH        = FirewallPreset{Type: "SSH", Protocol: FirewallProtocolTCP, Port: "22"}
+	FirewallPresetRDP        = FirewallPreset{Type: "RDP", Protocol: FirewallProtocolTCP, Port: "3389"}
+	FirewallPresetMySQL      = FirewallPreset{Type: "MySQL", Protocol: FirewallProtocolTCP, Port: "3306"}
+	FirewallPresetPostgreSQL = FirewallPreset{Type: "PostgreSQL", Protocol: FirewallProtocolTCP, Port: "5432"}
+	FirewallPresetDNS        = FirewallPreset{Type: "DNS", Protocol: FirewallProtocolUDP, Port: "53"}
+	FirewallPresetICMP       = FirewallPreset{Type: "ICMP", Protocol: FirewallProtocolICMP, Port: ""}
+)
+
+// FirewallRuleBuilder builds a FirewallRule. Each method returns a copy, so a builder can be reused as a template.
+//
+// Example:
+//
+//	ssh, err := indigo.NewFirewallRule(indigo.FirewallPresetSSH).Source("192.0.2.0/24").Build()
+//	app, err := indigo.NewCustomFirewallRule(indigo.FirewallProtocolTCP).PortRange(8000, 8080).AnySource().Build()
+type FirewallRuleBuilder struct {
+	rule FirewallRule
+}
+
+// NewFirewallRule returns a builder of a rule of the preset. The source must be set.
+func NewFirewallRule(preset FirewallPreset) FirewallRuleBuilder {
+	return FirewallRuleBuilder{rule: FirewallRule{Type: preset.Type, Protocol: string(preset.Protocol), Port: preset.Port}}
+}
+
+// NewCustomFirewallRule returns a builder of a FirewallRuleTypeCustom rule of the protocol.
+// The port must be set unless the protocol is FirewallProtocolICMP, and the source must be set.
+func NewCustomFirewallRule(protocol FirewallProtocol) FirewallRuleBuilder {
+	return FirewallRuleBuilder{rule: FirewallRule{Type: FirewallRuleTypeCustom, Protocol: string(protocol)}}
+}
+
+// Port sets the port.
+func (b FirewallRuleBuilder) Port(port uint16) FirewallRuleBuilder {
+	b.rule.Port = strconv.FormatUint(uint64(port), 10)
+	return b
+}
+
+// PortRange sets the ports from from to to, both inclusive.
+func (b FirewallRuleBuilder) PortRange(from, to uint16) FirewallRuleBuilder {
+	if from == to {
+		return b.Port(from)
+	}
+	b.rule.Port = strconv.FormatUint(uint64(from), 10) + "-" + strconv.FormatUint(uint64(to), 10)
+	return b
+}
+
+// Source sets the source, which is an IP address, e.g. "192.0.2.1", or a CIDR, e.g. "192.0.2.0/24".
+func (b FirewallRuleBuilder) Source(source string) FirewallRuleBuilder {
+	b.rule.Source = source
+	return b
+}
+
+// SourcePrefix sets the source to the prefix.
+func (b FirewallRuleBuilder) SourcePrefix(prefix netip.Prefix) FirewallRuleBuilder {
+	return b.Source(prefix.String())
+}
+
+// AnySource sets the source to FirewallSourceAny.
+func (b FirewallRuleBuilder) AnySource() FirewallRuleBuilder {
+	return b.Source(FirewallSourceAny)
+}
+
+// Build validates the rule and returns it. The error is a *FirewallRuleError.
+func (b FirewallRuleBuilder) Build() (FirewallRule, error) {
+	if err := b.rule.Validate(); err != nil {
+		return FirewallRule{}, err
+	}
+	return b.rule, nil
+}
+
+// FirewallRuleError reports the field of a firewall rule that is invalid. It satisfies errors.Is for ErrInvalidArgument.
+type FirewallRuleError struct {
+	// Direction and Index locate the rule in a request. Direction is empty if the rule is validated alone.
+	Direction FirewallDirection
+	Index     int
+	// Field is the invalid field: "type", "protocol", "port" or "source".
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *FirewallRuleError) Error() string {
+	field := e.Field
+	if e.Direction != "" {
+		field = fmt.Sprintf("%s[%d].%s", firewallDirectionName(e.Direction), e.Index, e.Field)
+	}
+	return fmt.Sprintf("indigo: invalid firewall rule %s=%q: %s", field, e.Value, e.Reason)
+}
+
+func (e *FirewallRuleError) Unwrap() error { return ErrInvalidArgument }
+
+func firewallDirectionName(direction FirewallDirection) string {
+	if direction == FirewallDirectionOutbound {
+		return "outbound"
+	}
+	return "inbound"
+}
+
+// Validate reports whether the rule is valid, so that a typo is caught before it is sent. The error is a *FirewallRuleError.
+//
+// Type must not be empty. Protocol must be "TCP", "UDP" or "ICMP", case-insensitively as in DiffFirewallRules. Port must be a port, e.g. "80", or a range, e.g. "8000-8080",
+// for TCP and UDP, and empty for ICMP. Source must be an IP address or a CIDR.
+func (r WebArenaIndigoV1NwFirewallRule) Validate() error {
+	invalid := func(field, value, reason string) error {
+		return &FirewallRuleError{Field: field, Value: value, Reason: reason}
+	}
+
+	if strings.TrimSpace(r.Type) == "" {
+		return invalid("type", r.Type, "must not be empty")
+	}
+
+	switch FirewallProtocol(strings.ToUpper(r.Protocol)) {
+	case FirewallProtocolTCP, FirewallProtocolUDP:
+		if problem := firewallPortProblem(r.Port); problem != "" {
+			return invalid("port", r.Port, problem)
+		}
+	case FirewallProtocolICMP:
+		if r.Port != "" {
+			return invalid("port", r.Port, "must be empty for ICMP")
+		}
+	default:
+		return invalid("protocol", r.Protocol, "must be one of TCP, UDP and ICMP")
+	}
+
+	if problem := firewallSourceProblem(r.Source); problem != "" {
+		return invalid("source", r.Source, problem)
+	}
+
+	return nil
+}
+
+// firewallPortProblem returns why port is invalid, or an empty string if it is valid.
+func firewallPortProblem(port string) string {
+	if port == "" {
+		return "must not be empty"
+	}
+
+	const notPort = "must be a port from 1 to 65535, or a range of them such as 8000-8080"
+	from, to, isRange := strings.Cut(port, "-")
+	first, err := strconv.ParseUint(from, 10, 16)
+	if err != nil || first == 0 {
+		return notPort
+	}
+	if !isRange {
+		return ""
+	}
+	last, err := strconv.ParseUint(to, 10, 16)
+	if err != nil || last == 0 {
+		return notPort
+	}
+	if first > last {
+		return "must not be a range whose start is greater than its end"
+	}
+	return ""
+}
+
+// firewallSourceProblem returns why source is invalid, or an empty string if it is valid.
+func firewallSourceProblem(source string) string {
+	const notSource = "must be an IP address or a CIDR"
+	if !strings.Contains(source, "/") {
+		if _, err := netip.ParseAddr(source); err != nil {
+			return notSource
+		}
+		return ""
+	}
+
+	prefix, err := netip.ParsePrefix(source)
+	if err != nil {
+		return notSource
+	}
+	if masked := prefix.Masked(); masked != prefix {
+		return fmt.Sprintf("must not have the host bits set; did you mean %s?", masked)
+	}
+	return ""
+}
+
+// validateFirewallRules validates the rules of a request, and returns all the errors joined. Each error is a *FirewallRuleError located in the request.
+func validateFirewallRules(inbound, outbound []FirewallRule) error {
+	var errs []error
+	for _, group := range []struct {
+		direction FirewallDirection
+		rules     []FirewallRule
+	}{{FirewallDirectionInbound, inbound}, {FirewallDirectionOutbound, outbound}} {
+		for i, rule := range group.rules {
+			var ruleErr *FirewallRuleError
+			if err := rule.Validate(); errors.As(err, &ruleErr) {
+				ruleErr.Direction, ruleErr.Index = group.direction, i
+				errs = append(errs, ruleErr)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate reports whether the request is valid. PostWebArenaIndigoV1NwCreateFirewall calls it before sending the request.
+// It returns ErrInvalidArgument if r is nil or Name is empty, and the *FirewallRuleError of every invalid rule joined otherwise.
+func (r *PostWebArenaIndigoV1NwCreateFirewallRequest) Validate() error {
+	if r == nil {
+		return errorz.Errorf("request is nil: %w", ErrInvalidArgument)
+	}
+	if r.Name == "" {
+		return errorz.Errorf("name is empty: %w", ErrInvalidArgument)
+	}
+	return validateFirewallRules(r.Inbound, r.Outbound)
+}
+
+// Validate reports whether the request is valid. UpdateWebArenaIndigoV1NwFirewall calls it before sending the request.
+// It returns ErrInvalidArgument if r is nil, and the *FirewallRuleError of every invalid rule joined otherwise.
+func (r *UpdateWebArenaIndigoV1NwFirewallRequest) Validate() error {
+	if r == nil {
+		return errorz.Errorf("request is nil: %w", ErrInvalidArgument)
+	}
+	return validateFirewallRules(r.Inbound, r.Outbound)
+}
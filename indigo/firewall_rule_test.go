@@ -0,0 +1,126 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+)
+
+func TestFirewallRuleBuilder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name    string
+			builder FirewallRuleBuilder
+			want    FirewallRule
+		}{
+			{name: "preset", builder: NewFirewallRule(FirewallPresetSSH).Source("192.0.2.0/24"), want: FirewallRule{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"}},
+			{name: "anySource", builder: NewFirewallRule(FirewallPresetHTTPS).AnySource(), want: FirewallRule{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}},
+			{name: "portRange", builder: NewCustomFirewallRule(FirewallProtocolTCP).PortRange(8000, 8080).SourcePrefix(netip.MustParsePrefix("2001:db8::/32")), want: FirewallRule{Type: "Custom", Protocol: "TCP", Port: "8000-8080", Source: "2001:db8::/32"}},
+			{name: "singlePortRange", builder: NewCustomFirewallRule(FirewallProtocolUDP).PortRange(53, 53).Source("192.0.2.1"), want: FirewallRule{Type: "Custom", Protocol: "UDP", Port: "53", Source: "192.0.2.1"}},
+			{name: "icmp", builder: NewFirewallRule(FirewallPresetICMP).AnySource(), want: FirewallRule{Type: "ICMP", Protocol: "ICMP", Port: "", Source: "0.0.0.0"}},
+		}
+		for _, tt := range tests {
+			got, err := tt.builder.Build()
+			requirez.NoError(t, err)
+			requirez.Equal(t, tt.want, got)
+		}
+
+		// NOTE: A builder is a value, so it can be reused.
+		base := NewCustomFirewallRule(FirewallProtocolTCP).AnySource()
+		a, err := base.Port(80).Build()
+		requirez.NoError(t, err)
+		b, err := base.Port(81).Build()
+		requirez.NoError(t, err)
+		requirez.Equal(t, "80", a.Port)
+		requirez.Equal(t, "81", b.Port)
+
+		// NOTE: The API may return the protocol in lowercase, and such a rule is resent as it is.
+		requirez.NoError(t, FirewallRule{Type: "HTTP", Protocol: "tcp", Port: "80", Source: "0.0.0.0"}.Validate())
+		requirez.NoError(t, FirewallRule{Type: "ICMP", Protocol: "icmp", Source: "0.0.0.0"}.Validate())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name      string
+			rule      FirewallRule
+			wantField string
+		}{
+			{name: "type", rule: FirewallRule{Type: " ", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}, wantField: "type"},
+			{name: "protocol", rule: FirewallRule{Type: "HTTP", Protocol: "SCTP", Port: "80", Source: "0.0.0.0"}, wantField: "protocol"},
+			{name: "emptyPort", rule: FirewallRule{Type: "HTTP", Protocol: "TCP", Source: "0.0.0.0"}, wantField: "port"},
+			{name: "port0", rule: FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "0", Source: "0.0.0.0"}, wantField: "port"},
+			{name: "port65536", rule: FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "65536", Source: "0.0.0.0"}, wantField: "port"},
+			{name: "reversedRange", rule: FirewallRule{Type: "Custom", Protocol: "UDP", Port: "8080-8000", Source: "0.0.0.0"}, wantField: "port"},
+			{name: "icmpPort", rule: FirewallRule{Type: "ICMP", Protocol: "ICMP", Port: "8", Source: "0.0.0.0"}, wantField: "port"},
+			{name: "source", rule: FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "192.0.2.256"}, wantField: "source"},
+			{name: "emptySource", rule: FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "80"}, wantField: "source"},
+			{name: "hostBits", rule: FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "192.0.2.1/24"}, wantField: "source"},
+		}
+		for _, tt := range tests {
+			err := tt.rule.Validate()
+			requirez.ErrorIs(t, err, ErrInvalidArgument)
+			var ruleErr *FirewallRuleError
+			requirez.True(t, errors.As(err, &ruleErr))
+			requirez.Equal(t, tt.wantField, ruleErr.Field)
+		}
+
+		_, err := NewFirewallRule(FirewallPresetHTTP).Build()
+		requirez.ErrorContains(t, err, `indigo: invalid firewall rule source="": must be an IP address or a CIDR`)
+
+		err = FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "192.0.2.1/24"}.Validate()
+		requirez.ErrorContains(t, err, "did you mean 192.0.2.0/24?")
+	})
+}
+
+func TestClient_firewallRequestValidation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, mutated := newRequestCountingClient(ctx, t, isFirewallMutationPath)
+
+		valid := FirewallRule{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}
+		_, err := client.PostWebArenaIndigoV1NwCreateFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:     "web",
+			Inbound:  []FirewallRule{valid, {Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0/33"}},
+			Outbound: []FirewallRule{{Type: "DNS", Protocol: "UDB", Port: "53", Source: "0.0.0.0"}},
+		})
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		requirez.ErrorContains(t, err, `indigo: invalid firewall rule inbound[1].source="0.0.0.0/33": must be an IP address or a CIDR`)
+		requirez.ErrorContains(t, err, `indigo: invalid firewall rule outbound[0].protocol="UDB": must be one of TCP, UDP and ICMP`)
+
+		_, err = client.PostWebArenaIndigoV1NwCreateFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Inbound: []FirewallRule{valid}})
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+
+		_, err = client.UpdateWebArenaIndigoV1NwFirewall(ctx, &UpdateWebArenaIndigoV1NwFirewallRequest{TemplateID: 1, Name: "web", Outbound: []FirewallRule{{Type: "SSH", Protocol: "TCP", Port: "ssh", Source: "0.0.0.0"}}})
+		var ruleErr *FirewallRuleError
+		requirez.True(t, errors.As(err, &ruleErr))
+		requirez.Equal(t, FirewallDirectionOutbound, ruleErr.Direction)
+		requirez.Equal(t, 0, ruleErr.Index)
+		requirez.Equal(t, "port", ruleErr.Field)
+
+		_, err = client.PlanFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "web", Inbound: []FirewallRule{{Type: "HTTP"}}})
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+
+		_, err = client.PostWebArenaIndigoV1NwCreateFirewall(ctx, nil)
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		_, err = client.UpdateWebArenaIndigoV1NwFirewall(ctx, nil)
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		_, err = client.ApplyFirewall(ctx, nil)
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+		requirez.ErrorIs(t, (*UpdateWebArenaIndigoV1NwFirewallRequest)(nil).Validate(), ErrInvalidArgument)
+
+		requirez.Equal(t, int64(0), mutated.Load())
+	})
+}
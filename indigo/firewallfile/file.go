@@ -0,0 +1,265 @@
+// Package firewallfile reads and writes firewall templates of the WebARENA Indigo API as YAML or JSON files,
+// so that firewall policies can be versioned in git.
+//
+// It is a separate package so that the indigo package does not depend on a YAML library.
+//
+// Is used as follows:
+//
+//	data, err := os.ReadFile("firewalls/web.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	req, err := firewallfile.Import(data, firewallfile.FormatOf("firewalls/web.yaml"))
+//	if err != nil {
+//		return err
+//	}
+//	plan, err := client.ApplyFirewall(ctx, req)
+package firewallfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/hakadoriya/z.go/errorz"
+
+	"github.com/hakadoriya/webarena-go/indigo"
+)
+
+// Version is the version of the file format of File.
+const Version = 1
+
+// File is a firewall template in a file.
+//
+// The format is stable: a field is never renamed or removed within a version, and a new version is introduced instead.
+// The file is written in YAML or JSON with the same keys:
+//
+//	version: 1          # required. Version.
+//	name: web           # required. The name of the firewall.
+//	inbound:            # the inbound rules, in order. An empty list if none.
+//	  - type: HTTPS     # the fields of indigo.FirewallRule. See indigo.WebArenaIndigoV1NwFirewallRule.Validate.
+//	    protocol: TCP
+//	    port: "443"
+//	    source: 0.0.0.0
+//	outbound: []        # the outbound rules, in order. An empty list if none.
+//	instances: [16]     # optional. The IDs of the instances to assign the firewall to. See indigo.Client.ApplyFirewall.
+//	                    # If omitted, the instances of the firewall are left as they are. [] detaches all of them.
+//
+// Unknown keys are rejected, so that a typo in a key is not silently ignored.
+type File struct {
+	Version   int                   `json:"version"             yaml:"version"`
+	Name      string                `json:"name"                yaml:"name"`
+	Inbound   []indigo.FirewallRule `json:"inbound"             yaml:"inbound"`
+	Outbound  []indigo.FirewallRule `json:"outbound"            yaml:"outbound"`
+	Instances []int64               `json:"instances,omitempty" yaml:"instances,omitempty,flow"`
+}
+
+// Format is the encoding of a File.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// FormatOf returns the format of the file at path by its extension: ".json" for JSON, and YAML otherwise.
+func FormatOf(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// New returns the file of the firewall template, e.g. the one returned by indigo.FirewallService.Get.
+func New(template *indigo.FirewallTemplate) *File {
+	return &File{
+		Version:  Version,
+		Name:     template.Name,
+		Inbound:  append([]indigo.FirewallRule{}, template.Inbound...),
+		Outbound: append([]indigo.FirewallRule{}, template.Outbound...),
+	}
+}
+
+// ExportTemplate returns the file of the output of indigo.Client.GetWebArenaIndigoV1NwGetTemplate,
+// grouping its rows, one per rule, into the inbound and the outbound rules by their direction.
+// The name is empty if the firewall has no rules, because the output has no rows then. Use Export to fill it.
+// It returns indigo.ErrUnknownFirewallDirection if a row is neither inbound nor outbound.
+func ExportTemplate(resp indigo.GetWebArenaIndigoV1NwGetTemplateResponse) (*File, error) {
+	template, err := indigo.NewFirewallTemplate(0, resp)
+	if err != nil {
+		return nil, errorz.Errorf("indigo.NewFirewallTemplate: %w", err)
+	}
+	return New(template), nil
+}
+
+// Export returns the file of the firewall of id, e.g. of client.Firewalls. It returns indigo.ErrNotFound if the firewall does not exist.
+func Export(ctx context.Context, firewalls indigo.FirewallService, id int64) (*File, error) {
+	template, err := firewalls.Get(ctx, id)
+	if err != nil {
+		return nil, errorz.Errorf("firewalls.Get: %w", err)
+	}
+	file := New(template)
+
+	if file.Name == "" {
+		list, err := firewalls.List(ctx)
+		if err != nil {
+			return nil, errorz.Errorf("firewalls.List: %w", err)
+		}
+		if i := slices.IndexFunc(list, func(firewall indigo.Firewall) bool { return firewall.ID == id }); i >= 0 {
+			file.Name = list[i].Name
+		}
+	}
+
+	return file, nil
+}
+
+// encoding is File as it is encoded, to tell an omitted instances key from an empty one.
+type encoding struct {
+	Version   int      `json:"version"             yaml:"version"`
+	Name      string   `json:"name"                yaml:"name"`
+	Inbound   []rule   `json:"inbound"             yaml:"inbound"`
+	Outbound  []rule   `json:"outbound"            yaml:"outbound"`
+	Instances *[]int64 `json:"instances,omitempty" yaml:"instances,omitempty,flow"`
+}
+
+// rule is indigo.FirewallRule as it is encoded, so that the keys of the file do not depend on the tags of indigo.FirewallRule.
+type rule struct {
+	Type     string `json:"type"     yaml:"type"`
+	Protocol string `json:"protocol" yaml:"protocol"`
+	Port     string `json:"port"     yaml:"port"`
+	Source   string `json:"source"   yaml:"source"`
+}
+
+// Marshal encodes the file in the format. The rules keep their order, so that the file is stable under version control.
+// The instances key is omitted if Instances is nil, and written as [] if it is empty.
+func (f *File) Marshal(format Format) ([]byte, error) {
+	normalized := encoding{
+		Version:  f.Version,
+		Name:     f.Name,
+		Inbound:  encodeRules(f.Inbound),
+		Outbound: encodeRules(f.Outbound),
+	}
+	if f.Instances != nil {
+		normalized.Instances = &f.Instances
+	}
+
+	switch format {
+	case FormatJSON:
+		b, err := json.MarshalIndent(&normalized, "", "  ")
+		if err != nil {
+			return nil, errorz.Errorf("json.MarshalIndent: %w", err)
+		}
+		return append(b, '\n'), nil
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2) //nolint:mnd
+		if err := enc.Encode(&normalized); err != nil {
+			return nil, errorz.Errorf("enc.Encode: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, errorz.Errorf("enc.Close: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, errorz.Errorf("format=%q: %w", format, indigo.ErrInvalidArgument)
+	}
+}
+
+// Unmarshal decodes a file in the format. It returns indigo.ErrInvalidArgument if the file has an unknown key or an unsupported version.
+// It does not validate the rules. Use Import to do so.
+func Unmarshal(data []byte, format Format) (*File, error) {
+	var file encoding
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&file); err != nil {
+			return nil, errorz.Errorf("dec.Decode: %w", fmt.Errorf("%w: %w", indigo.ErrInvalidArgument, err))
+		}
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&file); err != nil {
+			return nil, errorz.Errorf("dec.Decode: %w", fmt.Errorf("%w: %w", indigo.ErrInvalidArgument, err))
+		}
+	default:
+		return nil, errorz.Errorf("format=%q: %w", format, indigo.ErrInvalidArgument)
+	}
+
+	if file.Version != Version {
+		return nil, errorz.Errorf("version=%d: unsupported version, want %d: %w", file.Version, Version, indigo.ErrInvalidArgument)
+	}
+	decoded := &File{
+		Version:  file.Version,
+		Name:     file.Name,
+		Inbound:  decodeRules(file.Inbound),
+		Outbound: decodeRules(file.Outbound),
+	}
+	if file.Instances != nil {
+		decoded.Instances = append([]int64{}, *file.Instances...)
+	}
+
+	return decoded, nil
+}
+
+// Template returns the firewall of the file, e.g. to compare it with the one of the API by indigo.DiffFirewalls.
+func (f *File) Template() *indigo.FirewallTemplate {
+	return &indigo.FirewallTemplate{
+		Name:     f.Name,
+		Inbound:  append([]indigo.FirewallRule{}, f.Inbound...),
+		Outbound: append([]indigo.FirewallRule{}, f.Outbound...),
+	}
+}
+
+// CreateRequest returns the request to create the firewall of the file.
+// Instances is nil if the file has no instances, so that indigo.Client.ApplyFirewall leaves the instances of the firewall as they are.
+func (f *File) CreateRequest() *indigo.PostWebArenaIndigoV1NwCreateFirewallRequest {
+	return &indigo.PostWebArenaIndigoV1NwCreateFirewallRequest{
+		Name:      f.Name,
+		Inbound:   append([]indigo.FirewallRule{}, f.Inbound...),
+		Outbound:  append([]indigo.FirewallRule{}, f.Outbound...),
+		Instances: slices.Clone(f.Instances),
+	}
+}
+
+// Import decodes a file in the format, and returns the request to create the firewall of it.
+// It returns indigo.ErrInvalidArgument if the file cannot be decoded or the request is invalid
+// (see indigo.PostWebArenaIndigoV1NwCreateFirewallRequest.Validate). The request can also be passed to indigo.Client.ApplyFirewall.
+func Import(data []byte, format Format) (*indigo.PostWebArenaIndigoV1NwCreateFirewallRequest, error) {
+	file, err := Unmarshal(data, format)
+	if err != nil {
+		return nil, errorz.Errorf("Unmarshal: %w", err)
+	}
+
+	req := file.CreateRequest()
+	if err := req.Validate(); err != nil {
+		return nil, errorz.Errorf("req.Validate: %w", err)
+	}
+
+	return req, nil
+}
+
+// encodeRules returns the rules to encode. It returns an empty slice if rules is nil, so that it is written as [] instead of null.
+func encodeRules(rules []indigo.FirewallRule) []rule {
+	encoded := make([]rule, 0, len(rules))
+	for _, r := range rules {
+		encoded = append(encoded, rule{Type: r.Type, Protocol: r.Protocol, Port: r.Port, Source: r.Source})
+	}
+	return encoded
+}
+
+// decodeRules returns the decoded rules. It returns an empty slice if rules is nil.
+func decodeRules(rules []rule) []indigo.FirewallRule {
+	decoded := make([]indigo.FirewallRule, 0, len(rules))
+	for _, r := range rules {
+		decoded = append(decoded, indigo.FirewallRule{Type: r.Type, Protocol: r.Protocol, Port: r.Port, Source: r.Source})
+	}
+	return decoded
+}
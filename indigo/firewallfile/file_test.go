@@ -0,0 +1,236 @@
+package firewallfile_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo"
+	"github.com/hakadoriya/webarena-go/indigo/firewallfile"
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+//nolint:gochecknoglobals
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// testFile is the content of the golden files testdata/file.golden.{yaml,json}.
+func testFile() *firewallfile.File {
+	return &firewallfile.File{
+		Version: firewallfile.Version,
+		Name:    "web",
+		Inbound: []indigo.FirewallRule{
+			{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"},
+			{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"},
+			{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"},
+			{Type: "Custom", Protocol: "UDP", Port: "60000-61000", Source: "2001:db8::/32"},
+		},
+		Outbound: []indigo.FirewallRule{},
+	}
+}
+
+func newTestClient(ctx context.Context, tb testing.TB, srv *indigotest.Server) *indigo.Client {
+	tb.Helper()
+	tb.Cleanup(srv.Close)
+
+	client, err := indigo.NewClient(ctx,
+		indigo.ClientOptionWithEndpoint(srv.URL),
+		indigo.ClientOptionWithClientID(srv.ClientID()),
+		indigo.ClientOptionWithClientSecret(srv.ClientSecret()),
+		indigo.ClientOptionWithoutRateLimiter(),
+	)
+	requirez.NoError(tb, err)
+
+	return client
+}
+
+func firewallInstances(tb testing.TB, srv *indigotest.Server, firewallID int64) []int64 {
+	tb.Helper()
+
+	instances, found := srv.FirewallInstances(firewallID)
+	requirez.True(tb, found)
+	return instances
+}
+
+func readGolden(tb testing.TB, name string, got []byte) []byte {
+	tb.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		requirez.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+		requirez.NoError(tb, os.WriteFile(path, got, 0o600))
+	}
+	want, err := os.ReadFile(path)
+	requirez.NoError(tb, err)
+
+	return want
+}
+
+//nolint:funlen
+func TestFirewallFile(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []firewallfile.Format{firewallfile.FormatYAML, firewallfile.FormatJSON} {
+		t.Run("success,golden,"+string(format), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := testFile().Marshal(format)
+			requirez.NoError(t, err)
+			want := readGolden(t, "file.golden."+string(format), got)
+			requirez.Equal(t, string(want), string(got))
+
+			file, err := firewallfile.Unmarshal(want, format)
+			requirez.NoError(t, err)
+			requirez.Equal(t, testFile(), file)
+			again, err := file.Marshal(format)
+			requirez.NoError(t, err)
+			requirez.Equal(t, string(want), string(again))
+		})
+
+		t.Run("success,roundTrip,"+string(format), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			client := newTestClient(ctx, t, indigotest.NewServer())
+			want, err := os.ReadFile(filepath.Join("testdata", "file.golden."+string(format)))
+			requirez.NoError(t, err)
+
+			req, err := firewallfile.Import(want, format)
+			requirez.NoError(t, err)
+			resp, err := client.PostWebArenaIndigoV1NwCreateFirewall(ctx, req)
+			requirez.NoError(t, err)
+
+			rows, err := client.GetWebArenaIndigoV1NwGetTemplate(ctx, resp.FirewallID)
+			requirez.NoError(t, err)
+			file, err := firewallfile.ExportTemplate(*rows)
+			requirez.NoError(t, err)
+			got, err := file.Marshal(format)
+			requirez.NoError(t, err)
+			requirez.Equal(t, string(want), string(got))
+		})
+	}
+
+	t.Run("success,firewallfile.FormatOf", func(t *testing.T) {
+		t.Parallel()
+
+		requirez.Equal(t, firewallfile.FormatJSON, firewallfile.FormatOf("firewalls/web.JSON"))
+		requirez.Equal(t, firewallfile.FormatYAML, firewallfile.FormatOf("firewalls/web.yml"))
+		requirez.Equal(t, firewallfile.FormatYAML, firewallfile.FormatOf("firewalls/web.yaml"))
+	})
+
+	t.Run("success,ExportFirewall", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestClient(ctx, t, indigotest.NewServer())
+		resp, err := client.PostWebArenaIndigoV1NwCreateFirewall(ctx, &indigo.PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "empty", Inbound: []indigo.FirewallRule{}, Outbound: []indigo.FirewallRule{}, Instances: []int64{}})
+		requirez.NoError(t, err)
+
+		// NOTE: The template of a firewall without rules has no rows, so the name comes from the firewall list.
+		file, err := firewallfile.Export(ctx, client.Firewalls, resp.FirewallID)
+		requirez.NoError(t, err)
+		requirez.Equal(t, &firewallfile.File{Version: firewallfile.Version, Name: "empty", Inbound: []indigo.FirewallRule{}, Outbound: []indigo.FirewallRule{}}, file)
+
+		_, err = firewallfile.Export(ctx, client.Firewalls, resp.FirewallID+1)
+		requirez.ErrorIs(t, err, indigo.ErrNotFound)
+	})
+
+	t.Run("success,instances", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := firewallfile.Import([]byte("version: 1\nname: web\ninbound: []\noutbound:\n  - {type: HTTPS, protocol: TCP, port: 443, source: 0.0.0.0}\ninstances: [6, 5]\n"), firewallfile.FormatYAML)
+		requirez.NoError(t, err)
+		requirez.Equal(t, &indigo.PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:      "web",
+			Inbound:   []indigo.FirewallRule{},
+			Outbound:  []indigo.FirewallRule{{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}},
+			Instances: []int64{6, 5},
+		}, req)
+	})
+
+	t.Run("success,omittedInstances", func(t *testing.T) {
+		t.Parallel()
+
+		file, err := firewallfile.Unmarshal([]byte("version: 1\nname: web\ninbound: []\noutbound: []\n"), firewallfile.FormatYAML)
+		requirez.NoError(t, err)
+		requirez.True(t, file.Instances == nil)
+		requirez.True(t, file.CreateRequest().Instances == nil)
+
+		for _, format := range []firewallfile.Format{firewallfile.FormatYAML, firewallfile.FormatJSON} {
+			data, err := (&firewallfile.File{Version: firewallfile.Version, Name: "web", Instances: []int64{}}).Marshal(format)
+			requirez.NoError(t, err)
+			file, err := firewallfile.Unmarshal(data, format)
+			requirez.NoError(t, err)
+			requirez.Equal(t, []int64{}, file.Instances)
+		}
+	})
+
+	t.Run("success,ApplyFirewall", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		srv := indigotest.NewServer()
+		client := newTestClient(ctx, t, srv)
+		created, err := client.PostWebArenaIndigoV1VmCreateInstance(ctx, &indigo.PostWebArenaIndigoV1VmCreateInstanceRequest{RegionID: 1, OsID: 1, InstancePlan: 1, InstanceName: "test-instance"})
+		requirez.NoError(t, err)
+		instanceID := created.Vms.ID
+		firewallID, err := client.Firewalls.Create(ctx, &indigo.PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:      "web",
+			Inbound:   []indigo.FirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}},
+			Outbound:  []indigo.FirewallRule{},
+			Instances: []int64{instanceID},
+		})
+		requirez.NoError(t, err)
+
+		// NOTE: Without the instances key, ApplyFirewall refuses to update the rules rather than detach the instance.
+		req, err := firewallfile.Import([]byte("version: 1\nname: web\ninbound:\n  - {type: HTTPS, protocol: TCP, port: 443, source: 0.0.0.0}\noutbound: []\n"), firewallfile.FormatYAML)
+		requirez.NoError(t, err)
+		_, err = client.ApplyFirewall(ctx, req)
+		requirez.ErrorIs(t, err, indigo.ErrInvalidArgument)
+		requirez.Equal(t, []int64{instanceID}, firewallInstances(t, srv, firewallID))
+
+		req, err = firewallfile.Import([]byte("version: 1\nname: web\ninbound:\n  - {type: HTTP, protocol: TCP, port: 80, source: 0.0.0.0/0}\noutbound: []\n"), firewallfile.FormatYAML)
+		requirez.NoError(t, err)
+		plan, err := client.ApplyFirewall(ctx, req)
+		requirez.NoError(t, err)
+		requirez.False(t, plan.HasChanges())
+		requirez.Equal(t, []int64{instanceID}, firewallInstances(t, srv, firewallID))
+
+		req, err = firewallfile.Import([]byte(fmt.Sprintf("version: 1\nname: web\ninbound:\n  - {type: HTTPS, protocol: TCP, port: 443, source: 0.0.0.0}\noutbound: []\ninstances: [%d]\n", instanceID)), firewallfile.FormatYAML)
+		requirez.NoError(t, err)
+		plan, err = client.ApplyFirewall(ctx, req)
+		requirez.NoError(t, err)
+		requirez.Equal(t, indigo.FirewallPlanActionUpdate, plan.Action)
+		requirez.Equal(t, []int64{instanceID}, firewallInstances(t, srv, firewallID))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name   string
+			data   string
+			format firewallfile.Format
+		}{
+			{name: "unknownKeyYAML", data: "version: 1\nname: web\ninbound: []\noutbound: []\ninstance: [1]\n", format: firewallfile.FormatYAML},
+			{name: "unknownKeyJSON", data: `{"version":1,"name":"web","inbound":[{"type":"HTTP","protocol":"TCP","port":"80","sources":"0.0.0.0"}],"outbound":[]}`, format: firewallfile.FormatJSON},
+			{name: "version", data: "version: 2\nname: web\ninbound: []\noutbound: []\n", format: firewallfile.FormatYAML},
+			{name: "noVersion", data: `{"name":"web","inbound":[],"outbound":[]}`, format: firewallfile.FormatJSON},
+			{name: "noName", data: "version: 1\ninbound: []\noutbound: []\n", format: firewallfile.FormatYAML},
+			{name: "invalidRule", data: "version: 1\nname: web\ninbound:\n  - {type: HTTP, protocol: TCP, port: 80, source: 0.0.0.0/0/0}\noutbound: []\n", format: firewallfile.FormatYAML},
+			{name: "syntax", data: `{"version":1,`, format: firewallfile.FormatJSON},
+			{name: "format", data: `version = 1`, format: "toml"},
+		}
+		for _, tt := range tests {
+			_, err := firewallfile.Import([]byte(tt.data), tt.format)
+			requirez.ErrorIs(t, err, indigo.ErrInvalidArgument)
+		}
+
+		_, err := testFile().Marshal("toml")
+		requirez.ErrorIs(t, err, indigo.ErrInvalidArgument)
+	})
+}
@@ -0,0 +1,288 @@
+package indigotest
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type accessTokensRequest struct {
+	GrantType    string `json:"grantType"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	Code         string `json:"code"`
+}
+
+type accessTokensResponse struct {
+	AccessToken string `json:"accessToken"`
+	TokenType   string `json:"tokenType"`
+	ExpiresIn   string `json:"expiresIn"`
+	Scope       string `json:"scope"`
+	IssuedAt    string `json:"issuedAt"`
+}
+
+func (s *Server) handleAccessTokens(w http.ResponseWriter, r *http.Request) {
+	var req accessTokensRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.GrantType != "client_credentials" || req.ClientID != s.clientID || req.ClientSecret != s.clientSecret {
+		s.writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid client credentials")
+		return
+	}
+
+	now := s.now()
+	token := randomHex(14) //nolint:mnd
+
+	s.mu.Lock()
+	s.accessTokens[token] = now.Add(s.tokenTTL)
+	s.mu.Unlock()
+
+	s.writeJSON(w, http.StatusCreated, &accessTokensResponse{
+		AccessToken: token,
+		TokenType:   "BearerToken",
+		ExpiresIn:   strconv.FormatInt(int64(s.tokenTTL/time.Second), 10),
+		Scope:       "",
+		IssuedAt:    strconv.FormatInt(now.UnixMilli(), 10),
+	})
+}
+
+// RevokeAccessTokens invalidates every access token issued so far, as if the server revoked them.
+func (s *Server) RevokeAccessTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.accessTokens)
+}
+
+type sshKey struct {
+	ID        int64  `json:"id"`
+	ServiceID string `json:"service_id"`
+	UserID    int64  `json:"user_id"`
+	Name      string `json:"name"`
+	SSHKey    string `json:"sshkey"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func (s *Server) sortedSSHKeys(filter func(k *sshKey) bool) []*sshKey {
+	keys := make([]*sshKey, 0, len(s.sshKeys))
+	for _, k := range s.sshKeys {
+		if filter == nil || filter(k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b *sshKey) int { return cmp.Compare(a.ID, b.ID) })
+	return keys
+}
+
+func (s *Server) handleListSSHKeys(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := s.sortedSSHKeys(nil)
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"total":   len(keys),
+		"sshkeys": keys,
+	})
+}
+
+func (s *Server) handleListActiveSSHKeys(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := s.sortedSSHKeys(func(k *sshKey) bool { return k.Status == "ACTIVE" })
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"total":   len(keys),
+		"sshkeys": keys,
+	})
+}
+
+type sshKeyRequest struct {
+	SSHName      string `json:"sshName"`
+	SSHKey       string `json:"sshKey"`
+	SSHKeyState  string `json:"sshKeyState"`
+	SSHKeyStatus string `json:"sshKeyStatus"`
+}
+
+func (s *Server) handleCreateSSHKey(w http.ResponseWriter, r *http.Request) {
+	var req sshKeyRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.SSHName == "" || req.SSHKey == "" {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "sshName and sshKey are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.formatTime(s.now())
+	k := &sshKey{
+		ID:        s.nextID("sshkey"),
+		ServiceID: serviceID,
+		UserID:    userID,
+		Name:      req.SSHName,
+		SSHKey:    req.SSHKey,
+		Status:    "ACTIVE",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.sshKeys[k.ID] = k
+
+	s.writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"message": "SSH key has been added successfully",
+		"sshKey":  k,
+	})
+}
+
+func (s *Server) handleRetrieveSSHKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, found := s.sshKeys[id]
+	if !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "SSH key not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"sshKey":  []*sshKey{k},
+	})
+}
+
+func (s *Server) handleUpdateSSHKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathID(w, r)
+	if !ok {
+		return
+	}
+	var req sshKeyRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, found := s.sshKeys[id]
+	if !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "SSH key not found")
+		return
+	}
+
+	if req.SSHName != "" {
+		k.Name = req.SSHName
+	}
+	if req.SSHKey != "" {
+		k.SSHKey = req.SSHKey
+	}
+	// NOTE: The API document names the field "sshKeyStatus", but the client sends "sshKeyState". Accept both.
+	switch {
+	case req.SSHKeyStatus != "":
+		k.Status = req.SSHKeyStatus
+	case req.SSHKeyState != "":
+		k.Status = req.SSHKeyState
+	}
+	k.UpdatedAt = s.formatTime(s.now())
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "SSH key has been updated successfully",
+	})
+}
+
+func (s *Server) handleDestroySSHKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.sshKeys[id]; !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "SSH key not found")
+		return
+	}
+	delete(s.sshKeys, id)
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "SSH key has been removed successfully",
+	})
+}
+
+type apiKey struct {
+	ID        int64  `json:"id"`
+	APIKey    string `json:"apiKey"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := &apiKey{
+		ID:        s.nextID("apikey"),
+		APIKey:    randomHex(16), //nolint:mnd
+		CreatedAt: s.formatTime(s.now()),
+	}
+	s.apiKeys[k.ID] = k
+
+	s.writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"apiKey":    k.APIKey,
+		"apiSecret": randomHex(8), //nolint:mnd
+	})
+}
+
+func (s *Server) handleListAPIKeys(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]*apiKey, 0, len(s.apiKeys))
+	for _, k := range s.apiKeys {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b *apiKey) int { return cmp.Compare(a.ID, b.ID) })
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":      true,
+		"total":        len(keys),
+		"accesstokens": keys,
+	})
+}
+
+func (s *Server) handleDeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.apiKeys[id]; !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "API Key not found")
+		return
+	}
+	delete(s.apiKeys, id)
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "API Key is removed successfully",
+	})
+}
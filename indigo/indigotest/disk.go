@@ -0,0 +1,262 @@
+package indigotest
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type snapshot struct {
+	id          int64
+	name        string
+	instanceID  int64
+	regionID    int64
+	os          *osEntry
+	volume      int64
+	slotNumber  int64
+	status      string
+	size        int64
+	completedAt time.Time
+
+	// targetStatus is the status that the snapshot reaches at settleAt, or empty if the snapshot is not in transition.
+	targetStatus string
+	settleAt     time.Time
+}
+
+// setStatus sets the status of the snapshot, and completes it if the status is "created".
+func (snap *snapshot) setStatus(status string, at time.Time) {
+	snap.status = status
+	snap.targetStatus = ""
+	snap.settleAt = time.Time{}
+	if status == "created" {
+		snap.completedAt = at
+	}
+}
+
+// transitSnapshot starts the transition of the snapshot to "created" via the given status, or completes it immediately if the server has no transition delay.
+// s.mu must be held.
+func (s *Server) transitSnapshot(snap *snapshot, via string) {
+	now := s.now()
+	if s.transitionDelay <= 0 {
+		snap.setStatus("created", now)
+		return
+	}
+	snap.status = via
+	snap.targetStatus = "created"
+	snap.settleAt = now.Add(s.transitionDelay)
+}
+
+// SetSnapshotStatus forces the status of the snapshot, e.g. to simulate a snapshot that failed on the API side.
+// It reports whether the snapshot exists.
+func (s *Server) SetSnapshotStatus(id int64, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, found := s.snapshots[id]
+	if !found {
+		return false
+	}
+	snap.setStatus(status, s.now())
+	return true
+}
+
+// stoppedInstance checks that the instance is not running, because the API operates on the disk of a stopped instance. s.mu must be held.
+func (s *Server) stoppedInstance(w http.ResponseWriter, i *instance) bool {
+	if i.status != "UNUSED" && i.status != "shutoff" {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "Instance must be stopped: "+i.status)
+		return false
+	}
+	return true
+}
+
+// settledSnapshot checks that the snapshot is not in transition. s.mu must be held.
+func (s *Server) settledSnapshot(w http.ResponseWriter, snap *snapshot) bool {
+	if snap.targetStatus != "" {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "Snapshot is in progress: "+snap.status)
+		return false
+	}
+	return true
+}
+
+func (s *Server) renderSnapshot(snap *snapshot) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  snap.id,
+		"name":                snap.name,
+		"service_id":          serviceID,
+		"user_id":             strconv.Itoa(userID), // NOTE: The API returns user_id of snapshots as a string.
+		"disk_id":             snap.instanceID,
+		"volume":              snap.volume,
+		"slot_number":         snap.slotNumber,
+		"status":              snap.status,
+		"size":                strconv.FormatInt(snap.size, 10), // NOTE: The API returns size of snapshots as a string.
+		"deleted":             0,
+		"completed_timestamp": s.formatTime(snap.completedAt),
+		"deleted_timestamp":   s.formatTime(time.Time{}),
+	}
+}
+
+type snapshotRequest struct {
+	Name       string  `json:"name"`
+	InstanceID flexInt `json:"instanceid"`
+	SnapshotID flexInt `json:"snapshotid"`
+	SlotNum    flexInt `json:"slotnum"`
+}
+
+func (s *Server) writeSnapshotStatus(w http.ResponseWriter) {
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"STATUS": 0})
+}
+
+func (s *Server) handleTakeSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req snapshotRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "name is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settle()
+	i, found := s.instances[int64(req.InstanceID)]
+	if !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Instance not found")
+		return
+	}
+	if !s.stoppedInstance(w, i) {
+		return
+	}
+
+	var volume int64
+	for _, snap := range s.snapshots {
+		if snap.instanceID == i.id {
+			volume = max(volume, snap.volume)
+		}
+	}
+
+	const sizeMB = 2000
+	snap := &snapshot{
+		id:         s.nextID("snapshot"),
+		name:       req.Name,
+		instanceID: i.id,
+		regionID:   i.regionID,
+		os:         i.os,
+		volume:     volume + 1,
+		slotNumber: int64(req.SlotNum),
+		size:       sizeMB,
+	}
+	s.snapshots[snap.id] = snap
+	s.transitSnapshot(snap, "pending")
+
+	s.writeSnapshotStatus(w)
+}
+
+func (s *Server) handleSnapshotList(w http.ResponseWriter, r *http.Request) {
+	instanceID, ok := s.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settle()
+	if _, found := s.instances[instanceID]; !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Instance not found")
+		return
+	}
+
+	snapshots := make([]*snapshot, 0)
+	for _, snap := range s.snapshots {
+		if snap.instanceID == instanceID {
+			snapshots = append(snapshots, snap)
+		}
+	}
+	slices.SortFunc(snapshots, func(a, b *snapshot) int { return cmp.Compare(a.id, b.id) })
+
+	resp := make([]map[string]interface{}, 0, len(snapshots))
+	for _, snap := range snapshots {
+		resp = append(resp, s.renderSnapshot(snap))
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// instanceSnapshot looks up the snapshot in the request and checks that it belongs to the instance in the request,
+// that the instance is stopped, and that the snapshot is not in transition. s.mu must be held.
+func (s *Server) instanceSnapshot(w http.ResponseWriter, req *snapshotRequest) (*instance, *snapshot, bool) {
+	s.settle()
+	i, found := s.instances[int64(req.InstanceID)]
+	if !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Instance not found")
+		return nil, nil, false
+	}
+	snap, found := s.snapshots[int64(req.SnapshotID)]
+	if !found || snap.instanceID != i.id {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Snapshot not found")
+		return nil, nil, false
+	}
+	if !s.stoppedInstance(w, i) || !s.settledSnapshot(w, snap) {
+		return nil, nil, false
+	}
+	return i, snap, true
+}
+
+func (s *Server) handleRetakeSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req snapshotRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, snap, ok := s.instanceSnapshot(w, &req)
+	if !ok {
+		return
+	}
+	s.transitSnapshot(snap, "pending")
+
+	s.writeSnapshotStatus(w)
+}
+
+func (s *Server) handleRestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req snapshotRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, snap, ok := s.instanceSnapshot(w, &req)
+	if !ok {
+		return
+	}
+	i.os = snap.os
+	s.transitSnapshot(snap, "restoring")
+
+	s.writeSnapshotStatus(w)
+}
+
+func (s *Server) handleDeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.snapshots[id]; !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Snapshot not found")
+		return
+	}
+	delete(s.snapshots, id)
+
+	s.writeSnapshotStatus(w)
+}
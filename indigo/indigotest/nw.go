@@ -0,0 +1,272 @@
+package indigotest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type firewallRule struct {
+	Type     string `json:"type"`
+	Protocol string `json:"protocol"`
+	Port     string `json:"port"`
+	Source   string `json:"source"`
+}
+
+type firewall struct {
+	id        int64
+	name      string
+	inbound   []firewallRule
+	outbound  []firewallRule
+	instances []int64
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+type firewallRequest struct {
+	TemplateID flexInt        `json:"templateid"`
+	Name       string         `json:"name"`
+	Inbound    []firewallRule `json:"inbound"`
+	Outbound   []firewallRule `json:"outbound"`
+	Instances  []flexInt      `json:"instances"`
+}
+
+// instanceIDs validates and converts the instance IDs in the request. s.mu must be held.
+func (s *Server) instanceIDs(w http.ResponseWriter, ids []flexInt) ([]int64, bool) {
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, found := s.instances[int64(id)]; !found {
+			s.writeError(w, http.StatusBadRequest, "Bad Request", "instances contains an invalid instance id")
+			return nil, false
+		}
+		out = append(out, int64(id))
+	}
+	return out, true
+}
+
+// FirewallInstances returns the IDs of the instances that the firewall is assigned to, which the API does not report.
+// It reports whether the firewall exists.
+func (s *Server) FirewallInstances(id int64) ([]int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fw, found := s.firewalls[id]
+	if !found {
+		return nil, false
+	}
+	instances := slices.Clone(fw.instances)
+	slices.Sort(instances)
+	return instances, true
+}
+
+func (s *Server) handleCreateFirewall(w http.ResponseWriter, r *http.Request) {
+	var req firewallRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "name is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instances, ok := s.instanceIDs(w, req.Instances)
+	if !ok {
+		return
+	}
+
+	now := s.now()
+	fw := &firewall{
+		id:        s.nextID("firewall"),
+		name:      req.Name,
+		inbound:   req.Inbound,
+		outbound:  req.Outbound,
+		instances: instances,
+		createdAt: now,
+		updatedAt: now,
+	}
+	s.firewalls[fw.id] = fw
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"message":    "Firewall template has been created successfully.",
+		"sucessCode": "F60002",
+		"firewallId": fw.id,
+	})
+}
+
+func (s *Server) handleGetFirewallList(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	firewalls := make([]*firewall, 0, len(s.firewalls))
+	for _, fw := range s.firewalls {
+		firewalls = append(firewalls, fw)
+	}
+	slices.SortFunc(firewalls, func(a, b *firewall) int { return cmp.Compare(b.id, a.id) })
+
+	resp := make([]map[string]interface{}, 0, len(firewalls))
+	for _, fw := range firewalls {
+		resp = append(resp, map[string]interface{}{
+			"id":         fw.id,
+			"service_id": serviceID,
+			"user_id":    userID,
+			"name":       fw.name,
+			"status":     1,
+			"created_at": s.formatTime(fw.createdAt),
+			"updated_at": s.formatTime(fw.updatedAt),
+		})
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type templateRule struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Direction string `json:"direction"`
+	Type      string `json:"type"`
+	Protocol  string `json:"protocol"`
+	Port      string `json:"port"`
+	Source    string `json:"source"`
+}
+
+// handleGetTemplate reproduces the response of the real API, which is a comma-separated sequence of JSON objects without the enclosing brackets.
+func (s *Server) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fw, found := s.firewalls[id]
+	if !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Firewall template not found")
+		return
+	}
+
+	objects := make([][]byte, 0, len(fw.inbound)+len(fw.outbound))
+	for _, d := range []struct {
+		direction string
+		rules     []firewallRule
+	}{{"in", fw.inbound}, {"out", fw.outbound}} {
+		for _, rule := range d.rules {
+			b, _ := json.MarshalIndent(&templateRule{
+				ID:        fw.id,
+				Name:      fw.name,
+				Direction: d.direction,
+				Type:      rule.Type,
+				Protocol:  rule.Protocol,
+				Port:      rule.Port,
+				Source:    rule.Source,
+			}, "", "    ")
+			objects = append(objects, b)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(bytes.Join(objects, []byte(",\n")))
+}
+
+func (s *Server) handleUpdateFirewall(w http.ResponseWriter, r *http.Request) {
+	var req firewallRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fw, found := s.firewalls[int64(req.TemplateID)]
+	if !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Firewall template not found")
+		return
+	}
+	instances, ok := s.instanceIDs(w, req.Instances)
+	if !ok {
+		return
+	}
+
+	if req.Name != "" {
+		fw.name = req.Name
+	}
+	fw.inbound = req.Inbound
+	fw.outbound = req.Outbound
+	fw.instances = instances
+	fw.updatedAt = s.now()
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"message":    "Firewall template is updated successfully.",
+		"sucessCode": "F6004",
+		"firewallId": fw.id,
+	})
+}
+
+type assignRequest struct {
+	InstanceID flexInt `json:"instanceid"`
+	TemplateID flexInt `json:"templateid"`
+}
+
+func (s *Server) handleAssignFirewall(w http.ResponseWriter, r *http.Request) {
+	var req assignRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fw, found := s.firewalls[int64(req.TemplateID)]
+	if !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Firewall template not found")
+		return
+	}
+	if _, found := s.instances[int64(req.InstanceID)]; !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Instance not found")
+		return
+	}
+
+	// NOTE: An instance belongs to at most one firewall template.
+	for _, other := range s.firewalls {
+		other.instances = slices.DeleteFunc(other.instances, func(id int64) bool { return id == int64(req.InstanceID) })
+	}
+	fw.instances = append(fw.instances, int64(req.InstanceID))
+	fw.updatedAt = s.now()
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"message":    "Firewall template is assigned successfully.",
+		"sucessCode": "F60003",
+	})
+}
+
+func (s *Server) handleDeleteFirewall(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.firewalls[id]; !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Firewall template not found")
+		return
+	}
+	delete(s.firewalls, id)
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"message":    "Firewall template has been deleted successfully.",
+		"sucessCode": "F6005",
+	})
+}
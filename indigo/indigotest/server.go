@@ -0,0 +1,382 @@
+// Package indigotest provides an in-process fake of the WebARENA Indigo API for offline testing.
+//
+// The fake server keeps instances, SSH keys, firewalls, snapshots and API keys in memory
+// and answers with the same response shapes as the real API, including its quirks
+// (e.g. the bracketless response body of /webarenaIndigo/v1/nw/gettemplate).
+//
+// Is used as follows:
+//
+//	srv := indigotest.NewServer()
+//	defer srv.Close()
+//
+//	client, err := indigo.NewClient(ctx,
+//		indigo.ClientOptionWithEndpoint(srv.URL),
+//		indigo.ClientOptionWithClientID(srv.ClientID()),
+//		indigo.ClientOptionWithClientSecret(srv.ClientSecret()),
+//		indigo.ClientOptionWithoutRateLimiter(),
+//	)
+package indigotest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+const (
+	DefaultClientID     = "indigotest-client-id"
+	DefaultClientSecret = "indigotest-client-secret" //nolint:gosec
+	DefaultTokenTTL     = 3599 * time.Second
+
+	// NOTE: Duplicated from the indigo package so that the package's own tests can import indigotest without an import cycle.
+	pathOAuthV1AccessTokens                    = "/oauth/v1/accesstokens" //nolint:gosec
+	pathWebArenaIndigoV1VmSSHKey               = "/webarenaIndigo/v1/vm/sshkey"
+	pathWebArenaIndigoV1VmSSHKeyActiveStatus   = "/webarenaIndigo/v1/vm/sshkey/active/status"
+	pathWebArenaIndigoV1AuthCreateAPIKey       = "/webarenaIndigo/v1/auth/create/apikey"
+	pathWebArenaIndigoV1AuthAPIKey             = "/webarenaIndigo/v1/auth/apikey"
+	pathWebArenaIndigoV1VmInstanceTypes        = "/webarenaIndigo/v1/vm/instancetypes"
+	pathWebArenaIndigoV1VmGetRegion            = "/webarenaIndigo/v1/vm/getregion"
+	pathWebArenaIndigoV1VmOSList               = "/webarenaIndigo/v1/vm/oslist"
+	pathWebArenaIndigoV1VmInstanceSpec         = "/webarenaIndigo/v1/vm/getinstancespec"
+	pathWebArenaIndigoV1VmCreateInstance       = "/webarenaIndigo/v1/vm/createinstance"
+	pathWebArenaIndigoV1VmGetInstanceList      = "/webarenaIndigo/v1/vm/getinstancelist"
+	pathWebArenaIndigoV1VmInstanceStatusUpdate = "/webarenaIndigo/v1/vm/instance/statusupdate"
+	pathWebArenaIndigoV1NwCreateFirewall       = "/webarenaIndigo/v1/nw/createfirewall"
+	pathWebArenaIndigoV1NwGetFirewallList      = "/webarenaIndigo/v1/nw/getfirewalllist"
+	pathWebArenaIndigoV1NwGetTemplate          = "/webarenaIndigo/v1/nw/gettemplate"
+	pathWebArenaIndigoV1NwUpdateFirewall       = "/webarenaIndigo/v1/nw/updatefirewall"
+	pathWebArenaIndigoV1NwAssign               = "/webarenaIndigo/v1/nw/assign"
+	pathWebArenaIndigoV1NwDeleteFirewall       = "/webarenaIndigo/v1/nw/deletefirewall"
+	pathWebArenaIndigoV1DiskTakeSnapshot       = "/webarenaIndigo/v1/disk/takesnapshot"
+	pathWebArenaIndigoV1DiskSnapshotList       = "/webarenaIndigo/v1/disk/snapshotlist"
+	pathWebArenaIndigoV1DiskRetakeSnapshot     = "/webarenaIndigo/v1/disk/retakesnapshot"
+	pathWebArenaIndigoV1DiskRestoreSnapshot    = "/webarenaIndigo/v1/disk/restoresnapshot"
+	pathWebArenaIndigoV1DiskDeleteSnapshot     = "/webarenaIndigo/v1/disk/deletesnapshot"
+
+	dateTimeLayout = "2006-01-02 15:04:05"
+	serviceID      = "wsi-000001"
+	userID         = 431
+)
+
+type (
+	Server struct {
+		*httptest.Server
+
+		clientID     string
+		clientSecret string
+		tokenTTL     time.Duration
+		now          func() time.Time
+
+		mu           sync.Mutex
+		requestSeq   int64
+		accessTokens map[string]time.Time
+		sshKeys      map[int64]*sshKey
+		apiKeys      map[int64]*apiKey
+		instances    map[int64]*instance
+		firewalls    map[int64]*firewall
+		snapshots    map[int64]*snapshot
+		sequences    map[string]int64
+		catalog      *catalog
+
+		quotaAllowed int
+		quotaWindow  time.Duration
+		quotaUsed    int
+		quotaResetAt time.Time
+
+		transitionDelay time.Duration
+	}
+
+	ServerOption interface {
+		apply(s *Server)
+	}
+)
+
+type clientCredentialsOption struct{ clientID, clientSecret string }
+
+func (o *clientCredentialsOption) apply(s *Server) {
+	s.clientID = o.clientID
+	s.clientSecret = o.clientSecret
+}
+
+// ServerOptionWithClientCredentials sets the client credentials accepted by /oauth/v1/accesstokens.
+func ServerOptionWithClientCredentials(clientID, clientSecret string) ServerOption { //nolint:ireturn
+	return &clientCredentialsOption{clientID: clientID, clientSecret: clientSecret}
+}
+
+type tokenTTLOption struct{ tokenTTL time.Duration }
+
+func (o *tokenTTLOption) apply(s *Server) { s.tokenTTL = o.tokenTTL }
+
+// ServerOptionWithTokenTTL sets the lifetime of the access tokens the server issues.
+func ServerOptionWithTokenTTL(tokenTTL time.Duration) ServerOption { //nolint:ireturn
+	return &tokenTTLOption{tokenTTL: tokenTTL}
+}
+
+type nowOption struct{ now func() time.Time }
+
+func (o *nowOption) apply(s *Server) { s.now = o.now }
+
+// ServerOptionWithNow replaces the clock of the server.
+func ServerOptionWithNow(now func() time.Time) ServerOption { //nolint:ireturn
+	return &nowOption{now: now}
+}
+
+type quotaOption struct {
+	allowed int
+	window  time.Duration
+}
+
+func (o *quotaOption) apply(s *Server) {
+	s.quotaAllowed = o.allowed
+	s.quotaWindow = o.window
+}
+
+// ServerOptionWithQuota enables the rate limit quota of the API.
+// The server then allows up to `allowed` requests per `window`, answers with 429 Too Many Requests beyond that,
+// and sends the X-Quota-Allowed, X-Quota-Available and X-Quota-Reset headers on every response.
+func ServerOptionWithQuota(allowed int, window time.Duration) ServerOption { //nolint:ireturn
+	return &quotaOption{allowed: allowed, window: window}
+}
+
+type transitionDelayOption struct{ delay time.Duration }
+
+func (o *transitionDelayOption) apply(s *Server) { s.transitionDelay = o.delay }
+
+// ServerOptionWithTransitionDelay makes the status changes of the server take delay, as the real API does.
+// While an instance is being started, stopped, reset or destroyed, it is reported in a transitional status
+// such as "starting", and it reaches the requested status once delay has elapsed.
+// Likewise, a snapshot being taken is reported as "pending" and a snapshot being restored as "restoring" until it becomes "created".
+// By default, the status changes are applied immediately.
+func ServerOptionWithTransitionDelay(delay time.Duration) ServerOption { //nolint:ireturn
+	return &transitionDelayOption{delay: delay}
+}
+
+// NewServer starts and returns a new fake server. The caller should call Close when finished, to shut it down.
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{
+		clientID:     DefaultClientID,
+		clientSecret: DefaultClientSecret,
+		tokenTTL:     DefaultTokenTTL,
+		now:          time.Now,
+		accessTokens: make(map[string]time.Time),
+		sshKeys:      make(map[int64]*sshKey),
+		apiKeys:      make(map[int64]*apiKey),
+		instances:    make(map[int64]*instance),
+		firewalls:    make(map[int64]*firewall),
+		snapshots:    make(map[int64]*snapshot),
+		sequences:    make(map[string]int64),
+		catalog:      newDefaultCatalog(),
+	}
+
+	for _, opt := range opts {
+		opt.apply(s)
+	}
+
+	s.Server = httptest.NewServer(s.routes())
+
+	return s
+}
+
+func (s *Server) ClientID() string { return s.clientID }
+
+func (s *Server) ClientSecret() string { return s.clientSecret }
+
+//nolint:funlen
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST "+pathOAuthV1AccessTokens, s.handleAccessTokens)
+
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1VmSSHKey, s.authorized(s.handleListSSHKeys))
+	mux.HandleFunc("POST "+pathWebArenaIndigoV1VmSSHKey, s.authorized(s.handleCreateSSHKey))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1VmSSHKeyActiveStatus, s.authorized(s.handleListActiveSSHKeys))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1VmSSHKey+"/{id}", s.authorized(s.handleRetrieveSSHKey))
+	mux.HandleFunc("PUT "+pathWebArenaIndigoV1VmSSHKey+"/{id}", s.authorized(s.handleUpdateSSHKey))
+	mux.HandleFunc("DELETE "+pathWebArenaIndigoV1VmSSHKey+"/{id}", s.authorized(s.handleDestroySSHKey))
+
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1AuthCreateAPIKey, s.authorized(s.handleCreateAPIKey))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1AuthAPIKey, s.authorized(s.handleListAPIKeys))
+	mux.HandleFunc("DELETE "+pathWebArenaIndigoV1AuthAPIKey+"/{id}", s.authorized(s.handleDeleteAPIKey))
+
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1VmInstanceTypes, s.authorized(s.handleInstanceTypes))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1VmGetRegion, s.authorized(s.handleGetRegion))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1VmOSList, s.authorized(s.handleOSList))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1VmInstanceSpec, s.authorized(s.handleInstanceSpec))
+
+	mux.HandleFunc("POST "+pathWebArenaIndigoV1VmCreateInstance, s.authorized(s.handleCreateInstance))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1VmGetInstanceList, s.authorized(s.handleGetInstanceList))
+	mux.HandleFunc("POST "+pathWebArenaIndigoV1VmInstanceStatusUpdate, s.authorized(s.handleInstanceStatusUpdate))
+
+	mux.HandleFunc("POST "+pathWebArenaIndigoV1NwCreateFirewall, s.authorized(s.handleCreateFirewall))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1NwGetFirewallList, s.authorized(s.handleGetFirewallList))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1NwGetTemplate+"/{id}", s.authorized(s.handleGetTemplate))
+	mux.HandleFunc("PUT "+pathWebArenaIndigoV1NwUpdateFirewall, s.authorized(s.handleUpdateFirewall))
+	mux.HandleFunc("POST "+pathWebArenaIndigoV1NwAssign, s.authorized(s.handleAssignFirewall))
+	mux.HandleFunc("DELETE "+pathWebArenaIndigoV1NwDeleteFirewall+"/{id}", s.authorized(s.handleDeleteFirewall))
+
+	mux.HandleFunc("POST "+pathWebArenaIndigoV1DiskTakeSnapshot, s.authorized(s.handleTakeSnapshot))
+	mux.HandleFunc("GET "+pathWebArenaIndigoV1DiskSnapshotList+"/{id}", s.authorized(s.handleSnapshotList))
+	mux.HandleFunc("POST "+pathWebArenaIndigoV1DiskRetakeSnapshot, s.authorized(s.handleRetakeSnapshot))
+	mux.HandleFunc("POST "+pathWebArenaIndigoV1DiskRestoreSnapshot, s.authorized(s.handleRestoreSnapshot))
+	mux.HandleFunc("DELETE "+pathWebArenaIndigoV1DiskDeleteSnapshot+"/{id}", s.authorized(s.handleDeleteSnapshot))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, http.StatusNotFound, "Not Found", "No route matches "+r.Method+" "+r.URL.Path)
+	})
+
+	return s.withRequestID(s.withQuota(mux))
+}
+
+func (s *Server) withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requestSeq++
+		seq := s.requestSeq
+		s.mu.Unlock()
+
+		w.Header().Set("X-Request-Id", fmt.Sprintf("00000000-0000-4000-8000-%012d", seq))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) withQuota(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.quotaAllowed <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		s.mu.Lock()
+		now := s.now()
+		if !now.Before(s.quotaResetAt) {
+			s.quotaUsed = 0
+			// NOTE: X-Quota-Reset has millisecond precision.
+			s.quotaResetAt = now.Add(s.quotaWindow).Truncate(time.Millisecond)
+		}
+		exceeded := s.quotaUsed >= s.quotaAllowed
+		if !exceeded {
+			s.quotaUsed++
+		}
+		available, resetAt := s.quotaAllowed-s.quotaUsed, s.quotaResetAt
+		s.mu.Unlock()
+
+		w.Header().Set("X-Quota-Allowed", strconv.Itoa(s.quotaAllowed))
+		w.Header().Set("X-Quota-Available", strconv.Itoa(available))
+		w.Header().Set("X-Quota-Reset", strconv.FormatInt(resetAt.UnixMilli(), 10))
+
+		if exceeded {
+			s.writeError(w, http.StatusTooManyRequests, "Too Many Request.", "Rate limit quota violation. Quota limit  exceeded.")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			s.writeError(w, http.StatusUnauthorized, "Unauthorized", "Authorization header is missing or malformed")
+			return
+		}
+
+		s.mu.Lock()
+		expiresAt, found := s.accessTokens[token]
+		s.mu.Unlock()
+
+		if !found || !s.now().Before(expiresAt) {
+			s.writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid Access Token")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// nextID returns the next ID of the given kind of resource. s.mu must be held.
+func (s *Server) nextID(kind string) int64 {
+	s.sequences[kind]++
+	return s.sequences[kind]
+}
+
+type errorResponse struct {
+	ErrorCode        string  `json:"errorCode"`
+	ErrorMessage     string  `json:"errorMessage"`
+	DeveloperMessage string  `json:"developerMessage"`
+	MoreInfo         *string `json:"moreInfo"`
+	RequestID        string  `json:"requestId"`
+}
+
+func (s *Server) writeError(w http.ResponseWriter, statusCode int, errorMessage, developerMessage string) {
+	s.writeJSON(w, statusCode, &errorResponse{
+		ErrorCode:        strconv.Itoa(statusCode),
+		ErrorMessage:     errorMessage,
+		DeveloperMessage: developerMessage,
+		MoreInfo:         nil,
+		RequestID:        w.Header().Get("X-Request-Id"),
+	})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "json.Decode: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func (s *Server) pathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "Not Found", "invalid id: "+r.PathValue("id"))
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *Server) formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "0000-00-00 00:00:00"
+	}
+	return t.UTC().Format(dateTimeLayout)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// flexInt accepts both a JSON number and a JSON string that contains a number,
+// because the API documentation sends IDs in either form depending on the endpoint.
+type flexInt int64
+
+func (i *flexInt) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return errorz.Errorf("strconv.ParseInt: %w", err)
+	}
+	*i = flexInt(v)
+	return nil
+}
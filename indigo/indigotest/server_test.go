@@ -0,0 +1,341 @@
+package indigotest_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo"
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func newClient(ctx context.Context, tb testing.TB, srv *indigotest.Server) *indigo.Client {
+	tb.Helper()
+
+	client, err := indigo.NewClient(ctx,
+		indigo.ClientOptionWithEndpoint(srv.URL),
+		indigo.ClientOptionWithClientID(srv.ClientID()),
+		indigo.ClientOptionWithClientSecret(srv.ClientSecret()),
+		indigo.ClientOptionWithoutRateLimiter(),
+	)
+	requirez.NoError(tb, err)
+
+	return client
+}
+
+func TestServer_auth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		client := newClient(ctx, t, srv)
+
+		accessToken, err := client.IssueAccessToken(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, indigotest.DefaultTokenTTL, accessToken.ExpiresIn)
+	})
+
+	t.Run("failure,invalidClientCredentials", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		_, err := indigo.NewClient(context.Background(),
+			indigo.ClientOptionWithEndpoint(srv.URL),
+			indigo.ClientOptionWithClientID(srv.ClientID()),
+			indigo.ClientOptionWithClientSecret("invalid"),
+			indigo.ClientOptionWithoutRateLimiter(),
+		)
+		requirez.ErrorIs(t, err, indigo.ErrAPIReturnsUnauthorized)
+	})
+
+	t.Run("failure,noAuthorizationHeader", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		resp, err := http.Get(srv.URL + indigo.PathWebArenaIndigoV1VmSSHKey)
+		requirez.NoError(t, err)
+		defer resp.Body.Close()
+		requirez.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		requirez.True(t, resp.Header.Get("X-Request-Id") != "")
+	})
+}
+
+func TestServer_sshKey(t *testing.T) {
+	t.Parallel()
+
+	srv := indigotest.NewServer()
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	client := newClient(ctx, t, srv)
+
+	created, err := client.CreateWebArenaIndigoV1VmSSHKey(ctx, &indigo.CreateWebArenaIndigoV1VmSSHKeyRequest{SshName: "test", SshKey: "ssh-ed25519 AAAA"})
+	requirez.NoError(t, err)
+	requirez.Equal(t, "ACTIVE", created.SshKey.Status)
+
+	_, err = client.UpdateWebArenaIndigoV1VmSSHKey(ctx, created.SshKey.Id, &indigo.UpdateWebArenaIndigoV1VmSSHKeyRequest{SshName: "renamed", SshKey: "ssh-ed25519 BBBB", SshKeyState: "DEACTIVE"})
+	requirez.NoError(t, err)
+
+	retrieved, err := client.RetrieveWebArenaIndigoV1VmSSHKey(ctx, created.SshKey.Id)
+	requirez.NoError(t, err)
+	requirez.Equal(t, 1, len(retrieved.SshKey))
+	requirez.Equal(t, "renamed", retrieved.SshKey[0].Name)
+
+	active, err := client.GetWebArenaIndigoV1VmSSHKeyActiveStatus(ctx)
+	requirez.NoError(t, err)
+	requirez.Equal(t, int64(0), active.Total)
+
+	_, err = client.DestroyWebArenaIndigoV1VmSSHKey(ctx, created.SshKey.Id)
+	requirez.NoError(t, err)
+
+	_, err = client.RetrieveWebArenaIndigoV1VmSSHKey(ctx, created.SshKey.Id)
+	requirez.ErrorIs(t, err, indigo.ErrUnexpectedStatusCode)
+}
+
+func TestServer_apiKey(t *testing.T) {
+	t.Parallel()
+
+	srv := indigotest.NewServer()
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	client := newClient(ctx, t, srv)
+
+	created, err := client.CreateWebArenaIndigoV1AuthCreateAPIKey(ctx)
+	requirez.NoError(t, err)
+	requirez.True(t, created.APISecret != "")
+
+	list, err := client.GetWebArenaIndigoV1AuthAPIKey(ctx)
+	requirez.NoError(t, err)
+	requirez.Equal(t, int64(1), list.Total)
+	requirez.Equal(t, created.APIKey, list.AccessTokens[0].APIKey)
+
+	_, err = client.DeleteWebArenaIndigoV1AuthAPIKey(ctx, list.AccessTokens[0].ID)
+	requirez.NoError(t, err)
+}
+
+//nolint:funlen
+func TestServer_instance(t *testing.T) {
+	t.Parallel()
+
+	srv := indigotest.NewServer()
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	client := newClient(ctx, t, srv)
+
+	instanceTypes, err := client.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+	requirez.NoError(t, err)
+	instanceTypeID := instanceTypes.InstanceTypes[0].ID
+
+	regions, err := client.GetWebArenaIndigoV1VmGetRegion(ctx, instanceTypeID)
+	requirez.NoError(t, err)
+	requirez.Equal(t, "Tokyo", regions.RegionList[0].Name)
+
+	osList, err := client.GetWebArenaIndigoV1VmOSList(ctx, instanceTypeID)
+	requirez.NoError(t, err)
+	requirez.True(t, osList.Total > 0)
+
+	const osID = 1
+	specs, err := client.GetWebArenaIndigoV1VmInstanceSpec(ctx, instanceTypeID, osID)
+	requirez.NoError(t, err)
+	requirez.True(t, specs.Total > 0)
+
+	key, err := client.CreateWebArenaIndigoV1VmSSHKey(ctx, &indigo.CreateWebArenaIndigoV1VmSSHKeyRequest{SshName: "test", SshKey: "ssh-ed25519 AAAA"})
+	requirez.NoError(t, err)
+
+	created, err := client.PostWebArenaIndigoV1VmCreateInstance(ctx, &indigo.PostWebArenaIndigoV1VmCreateInstanceRequest{
+		SshKeyID:     key.SshKey.Id,
+		RegionID:     regions.RegionList[0].ID,
+		OsID:         osID,
+		InstancePlan: specs.SpecList[0].ID,
+		InstanceName: "test-instance",
+	})
+	requirez.NoError(t, err)
+	requirez.Equal(t, indigo.InstanceStatusUnused, created.Vms.Status)
+
+	list, err := client.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+	requirez.NoError(t, err)
+	requirez.Equal(t, 1, len(list))
+	requirez.Equal(t, "test-instance", list[0].InstanceName)
+	requirez.True(t, list[0].IP != "")
+
+	started, err := client.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &indigo.PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{InstanceID: 1, Status: "start"})
+	requirez.NoError(t, err)
+	requirez.Equal(t, indigo.InstanceStatusRunning, started.InstanceStatus)
+
+	_, err = client.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &indigo.PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{InstanceID: 1, Status: "start"})
+	requirez.ErrorIs(t, err, indigo.ErrUnexpectedStatusCode)
+
+	stopped, err := client.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &indigo.PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{InstanceID: 1, Status: "stop"})
+	requirez.NoError(t, err)
+	requirez.Equal(t, indigo.InstanceStatusShutoff, stopped.InstanceStatus)
+
+	_, err = client.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &indigo.PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{InstanceID: 1, Status: "destroy"})
+	requirez.NoError(t, err)
+
+	list, err = client.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+	requirez.NoError(t, err)
+	requirez.Equal(t, 0, len(list))
+}
+
+func TestServer_instanceTransitionDelay(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 12, 13, 41, 0, 0, time.UTC)
+	var mu sync.Mutex
+	srv := indigotest.NewServer(
+		indigotest.ServerOptionWithTransitionDelay(time.Minute),
+		indigotest.ServerOptionWithNow(func() time.Time { mu.Lock(); defer mu.Unlock(); return now }),
+	)
+	t.Cleanup(srv.Close)
+	advance := func(d time.Duration) { mu.Lock(); defer mu.Unlock(); now = now.Add(d) }
+
+	ctx := context.Background()
+	client := newClient(ctx, t, srv)
+
+	instanceID := createInstance(ctx, t, client, "test-instance")
+	statusOf := func() indigo.InstanceStatus {
+		list, err := client.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 1, len(list))
+		return list[0].Status
+	}
+
+	started, err := client.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &indigo.PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{InstanceID: instanceID, Status: "start"})
+	requirez.NoError(t, err)
+	requirez.Equal(t, indigo.InstanceStatusRunning, started.InstanceStatus)
+	requirez.Equal(t, indigo.InstanceStatus("starting"), statusOf())
+
+	_, err = client.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &indigo.PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{InstanceID: instanceID, Status: "stop"})
+	requirez.ErrorIs(t, err, indigo.ErrUnexpectedStatusCode)
+
+	advance(time.Minute)
+	requirez.Equal(t, indigo.InstanceStatusRunning, statusOf())
+
+	requirez.True(t, srv.SetInstanceStatus(instanceID, "error"))
+	requirez.Equal(t, indigo.InstanceStatus("error"), statusOf())
+	requirez.False(t, srv.SetInstanceStatus(999, "error"))
+}
+
+func createInstance(ctx context.Context, tb testing.TB, client *indigo.Client, name string) int64 {
+	tb.Helper()
+
+	created, err := client.PostWebArenaIndigoV1VmCreateInstance(ctx, &indigo.PostWebArenaIndigoV1VmCreateInstanceRequest{
+		RegionID:     1,
+		OsID:         1,
+		InstancePlan: 1,
+		InstanceName: name,
+	})
+	requirez.NoError(tb, err)
+
+	return created.Vms.ID
+}
+
+//nolint:funlen
+func TestServer_firewall(t *testing.T) {
+	t.Parallel()
+
+	srv := indigotest.NewServer()
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	client := newClient(ctx, t, srv)
+
+	instanceID := createInstance(ctx, t, client, "test-instance")
+
+	created, err := client.PostWebArenaIndigoV1NwCreateFirewall(ctx, &indigo.PostWebArenaIndigoV1NwCreateFirewallRequest{
+		Name: "Example",
+		Inbound: []indigo.WebArenaIndigoV1NwFirewallRule{
+			{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"},
+			{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"},
+		},
+		Outbound: []indigo.WebArenaIndigoV1NwFirewallRule{
+			{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"},
+		},
+		Instances: []int64{},
+	})
+	requirez.NoError(t, err)
+
+	list, err := client.GetWebArenaIndigoV1NwGetFirewallList(ctx)
+	requirez.NoError(t, err)
+	requirez.Equal(t, 1, len(*list))
+	requirez.Equal(t, "Example", (*list)[0].Name)
+
+	template, err := client.GetWebArenaIndigoV1NwGetTemplate(ctx, created.FirewallID)
+	requirez.NoError(t, err)
+	requirez.Equal(t, 3, len(*template))
+	requirez.Equal(t, "in", (*template)[0].Direction)
+	requirez.Equal(t, "out", (*template)[2].Direction)
+
+	_, err = client.UpdateWebArenaIndigoV1NwFirewall(ctx, &indigo.UpdateWebArenaIndigoV1NwFirewallRequest{
+		TemplateID: created.FirewallID,
+		Name:       "Example",
+		Inbound:    []indigo.WebArenaIndigoV1NwFirewallRule{{Type: "SSH", Protocol: "TCP", Port: "22", Source: "192.0.2.0/24"}},
+		Outbound:   []indigo.WebArenaIndigoV1NwFirewallRule{},
+		Instances:  []int64{},
+	})
+	requirez.NoError(t, err)
+
+	template, err = client.GetWebArenaIndigoV1NwGetTemplate(ctx, created.FirewallID)
+	requirez.NoError(t, err)
+	requirez.Equal(t, 1, len(*template))
+	requirez.Equal(t, "22", (*template)[0].Port)
+
+	_, err = client.PostWebArenaIndigoV1NwAssign(ctx, &indigo.PostWebArenaIndigoV1NwAssignRequest{InstanceID: instanceID, TemplateID: created.FirewallID})
+	requirez.NoError(t, err)
+
+	_, err = client.DeleteWebArenaIndigoV1NwDeleteFirewall(ctx, created.FirewallID)
+	requirez.NoError(t, err)
+
+	_, err = client.GetWebArenaIndigoV1NwGetTemplate(ctx, created.FirewallID)
+	requirez.ErrorIs(t, err, indigo.ErrUnexpectedStatusCode)
+}
+
+func TestServer_snapshot(t *testing.T) {
+	t.Parallel()
+
+	srv := indigotest.NewServer()
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	client := newClient(ctx, t, srv)
+
+	instanceID := createInstance(ctx, t, client, "test-instance")
+
+	_, err := client.PostWebArenaIndigoV1DiskTakeSnapshot(ctx, &indigo.PostWebArenaIndigoV1DiskTakeSnapshotRequest{Name: "snap", InstanceID: instanceID, SlotNum: 0})
+	requirez.NoError(t, err)
+
+	list, err := client.GetWebArenaIndigoV1DiskSnapshotList(ctx, instanceID)
+	requirez.NoError(t, err)
+	requirez.Equal(t, 1, len(*list))
+	snap := (*list)[0]
+	requirez.Equal(t, "created", snap.Status)
+	requirez.False(t, snap.CompletedTimestamp.IsZero())
+	requirez.True(t, snap.DeletedTimestamp.IsZero())
+
+	_, err = client.PostWebArenaIndigoV1DiskRetakeSnapshot(ctx, &indigo.PostWebArenaIndigoV1DiskRetakeSnapshotRequest{InstanceID: instanceID, SnapshotID: 1})
+	requirez.NoError(t, err)
+
+	_, err = client.PostWebArenaIndigoV1DiskRestoreSnapshot(ctx, &indigo.PostWebArenaIndigoV1DiskRestoreSnapshotRequest{InstanceID: instanceID, SnapshotID: 1})
+	requirez.NoError(t, err)
+
+	_, err = client.DeleteWebArenaIndigoV1DiskDeleteSnapshot(ctx, snap.ID)
+	requirez.NoError(t, err)
+
+	_, err = client.DeleteWebArenaIndigoV1DiskDeleteSnapshot(ctx, snap.ID)
+	requirez.ErrorIs(t, err, indigo.ErrUnexpectedStatusCode)
+}
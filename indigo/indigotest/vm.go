@@ -0,0 +1,517 @@
+package indigotest
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type instanceType struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type region struct {
+	ID              int64  `json:"id"`
+	Name            string `json:"name"`
+	UsePossibleDate string `json:"use_possible_date"`
+}
+
+type osEntry struct {
+	ID             int64  `json:"id"`
+	CategoryID     int64  `json:"categoryid"`
+	Name           string `json:"name"`
+	ViewName       string `json:"viewname"`
+	InstanceTypeID int64  `json:"instancetype_id"`
+}
+
+type osCategory struct {
+	ID      int64      `json:"id"`
+	Name    string     `json:"name"`
+	Logo    string     `json:"logo"`
+	OSLists []*osEntry `json:"osLists"`
+}
+
+type spec struct {
+	ID              int64         `json:"id"`
+	Name            string        `json:"name"`
+	Description     string        `json:"description"`
+	UsePossibleDate string        `json:"use_possible_date"`
+	InstanceTypeID  int64         `json:"instancetype_id"`
+	CreatedAt       string        `json:"created_at"`
+	UpdatedAt       string        `json:"updated_at"`
+	InstanceType    *instanceType `json:"instance_type"`
+
+	// plan is the short plan code that instances report, e.g. "2CR2GB".
+	plan      string
+	cpus      int64
+	memSize   int64
+	diskPoint int64
+}
+
+type catalog struct {
+	instanceTypes []*instanceType
+	regions       map[int64][]*region
+	osCategories  map[int64][]*osCategory
+	specs         map[int64][]*spec
+}
+
+//nolint:funlen
+func newDefaultCatalog() *catalog {
+	const catalogDate = "2019-02-12 22:46:35"
+
+	kvm := &instanceType{ID: 1, Name: "instance", DisplayName: "KVM Instance", CreatedAt: catalogDate, UpdatedAt: catalogDate}
+	win := &instanceType{ID: 2, Name: "winserver", DisplayName: "Windows Server Instance", CreatedAt: catalogDate, UpdatedAt: catalogDate} //nolint:mnd
+
+	newSpec := func(id int64, it *instanceType, cpus, memSize, diskPoint int64) *spec {
+		name := fmt.Sprintf("%d CPU & %d GB RAM plan", cpus, memSize)
+		return &spec{
+			ID:              id,
+			Name:            name,
+			Description:     name,
+			UsePossibleDate: "2019-01-03 08:50:00",
+			InstanceTypeID:  it.ID,
+			CreatedAt:       "2019-01-04 08:40:57",
+			UpdatedAt:       "2019-01-04 08:40:57",
+			InstanceType:    it,
+			plan:            fmt.Sprintf("%dCR%dGB", cpus, memSize),
+			cpus:            cpus,
+			memSize:         memSize,
+			diskPoint:       diskPoint,
+		}
+	}
+
+	return &catalog{
+		instanceTypes: []*instanceType{kvm, win},
+		regions: map[int64][]*region{
+			kvm.ID: {
+				{ID: 1, Name: "Tokyo", UsePossibleDate: "2018-09-30 12:00:00"},
+				{ID: 2, Name: "Tokyo1", UsePossibleDate: "2018-12-09 00:00:00"}, //nolint:mnd
+			},
+			win.ID: {
+				{ID: 1, Name: "Tokyo", UsePossibleDate: "2018-09-30 12:00:00"},
+			},
+		},
+		osCategories: map[int64][]*osCategory{
+			kvm.ID: {
+				{ID: 1, Name: "Ubuntu", Logo: "Ubudu.png", OSLists: []*osEntry{
+					{ID: 1, CategoryID: 1, Name: "Ubuntu18.04", ViewName: "Ubuntu 18.04", InstanceTypeID: kvm.ID},
+					{ID: 2, CategoryID: 1, Name: "Ubuntu22.04", ViewName: "Ubuntu 22.04", InstanceTypeID: kvm.ID}, //nolint:mnd
+				}},
+				{ID: 2, Name: "CentOS", Logo: "CentOS.png", OSLists: []*osEntry{ //nolint:mnd
+					{ID: 3, CategoryID: 2, Name: "CentOS7.6", ViewName: "CentOS 7.6", InstanceTypeID: kvm.ID}, //nolint:mnd
+				}},
+			},
+			win.ID: {
+				{ID: 3, Name: "Windows", Logo: "Windows.png", OSLists: []*osEntry{ //nolint:mnd
+					{ID: 10, CategoryID: 3, Name: "WindowsServer2019", ViewName: "Windows Server 2019", InstanceTypeID: win.ID}, //nolint:mnd
+				}},
+			},
+		},
+		specs: map[int64][]*spec{
+			kvm.ID: {
+				newSpec(1, kvm, 1, 1, 12),  //nolint:mnd
+				newSpec(2, kvm, 2, 2, 12),  //nolint:mnd
+				newSpec(3, kvm, 4, 4, 24),  //nolint:mnd
+				newSpec(4, kvm, 6, 8, 48),  //nolint:mnd
+				newSpec(5, kvm, 8, 16, 96), //nolint:mnd
+			},
+			win.ID: {
+				newSpec(11, win, 4, 8, 48), //nolint:mnd
+			},
+		},
+	}
+}
+
+func (c *catalog) findOS(osID int64) *osEntry {
+	for _, categories := range c.osCategories {
+		for _, category := range categories {
+			for _, os := range category.OSLists {
+				if os.ID == osID {
+					return os
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *catalog) findSpec(specID int64) *spec {
+	for _, specs := range c.specs {
+		for _, sp := range specs {
+			if sp.ID == specID {
+				return sp
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) queryInt(w http.ResponseWriter, r *http.Request, key string) (int64, bool) {
+	v, err := strconv.ParseInt(r.URL.Query().Get(key), 10, 64)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "invalid "+key+": "+r.URL.Query().Get(key))
+		return 0, false
+	}
+	return v, true
+}
+
+func (s *Server) handleInstanceTypes(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":       true,
+		"total":         len(s.catalog.instanceTypes),
+		"instanceTypes": s.catalog.instanceTypes,
+	})
+}
+
+func (s *Server) handleGetRegion(w http.ResponseWriter, r *http.Request) {
+	instanceTypeID, ok := s.queryInt(w, r, "instanceTypeId")
+	if !ok {
+		return
+	}
+
+	regions := s.catalog.regions[instanceTypeID]
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"total":      len(regions),
+		"regionlist": append([]*region{}, regions...),
+	})
+}
+
+func (s *Server) handleOSList(w http.ResponseWriter, r *http.Request) {
+	instanceTypeID, ok := s.queryInt(w, r, "instanceTypeId")
+	if !ok {
+		return
+	}
+
+	categories := s.catalog.osCategories[instanceTypeID]
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"total":      len(categories),
+		"osCategory": append([]*osCategory{}, categories...),
+	})
+}
+
+func (s *Server) handleInstanceSpec(w http.ResponseWriter, r *http.Request) {
+	instanceTypeID, ok := s.queryInt(w, r, "instanceTypeId")
+	if !ok {
+		return
+	}
+	osID, ok := s.queryInt(w, r, "osId")
+	if !ok {
+		return
+	}
+
+	specs := []*spec{}
+	if os := s.catalog.findOS(osID); os != nil && os.InstanceTypeID == instanceTypeID {
+		specs = append(specs, s.catalog.specs[instanceTypeID]...)
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"total":    len(specs),
+		"speclist": specs,
+	})
+}
+
+type instance struct {
+	id               int64
+	name             string
+	status           string
+	sshKeyID         int64
+	regionID         int64
+	spec             *spec
+	os               *osEntry
+	uuid             string
+	vncPasswd        string
+	startDate        time.Time
+	statusChangeDate time.Time
+
+	// targetStatus is the status that the instance reaches at settleAt, or empty if the instance is not in transition.
+	targetStatus string
+	settleAt     time.Time
+}
+
+func (i *instance) ip() string { return fmt.Sprintf("192.168.%d.%d", i.id/256, i.id%256) } //nolint:mnd
+
+func (i *instance) common() map[string]interface{} {
+	const vpsKind = "10"
+	return map[string]interface{}{
+		"id":            i.id,
+		"instance_name": i.name,
+		"set_no":        10, //nolint:mnd
+		"vps_kind":      vpsKind,
+		"sequence_id":   i.id,
+		"user_id":       userID,
+		"service_id":    serviceID,
+		"status":        i.status,
+		"sshkey_id":     i.sshKeyID,
+		"host_id":       1,
+		"plan":          i.spec.plan,
+		"disk_point":    i.spec.diskPoint,
+		"memsize":       i.spec.memSize,
+		"cpus":          i.spec.cpus,
+		"os_id":         i.os.ID,
+		"otherstatus":   10, //nolint:mnd
+		"uuid":          i.uuid,
+		"uidgid":        100000 + i.id, //nolint:mnd
+		"vnc_port":      10000 + i.id,  //nolint:mnd
+		"vnc_passwd":    i.vncPasswd,
+		"arpaname":      fmt.Sprintf("192-168-%d-%d.pro.static.arena.ne.jp", i.id/256, i.id%256), //nolint:mnd
+		"arpadate":      "",
+		"updated_at":    nil,
+		"vm_revert":     0,
+	}
+}
+
+// createdResponse renders the instance the way /vm/createinstance does, with the dates as PHP DateTime objects.
+func (i *instance) createdResponse() map[string]interface{} {
+	dateTime := func(t time.Time) map[string]interface{} {
+		return map[string]interface{}{
+			"date":          t.UTC().Format("2006-01-02 15:04:05.000000"),
+			"timezone_type": 3, //nolint:mnd
+			"timezone":      "UTC",
+		}
+	}
+
+	m := i.common()
+	m["start_date"] = dateTime(i.startDate)
+	m["status_change_date"] = dateTime(i.statusChangeDate)
+	return m
+}
+
+// listResponse renders the instance the way /vm/getinstancelist does, with the dates as plain strings.
+func (i *instance) listResponse() map[string]interface{} {
+	m := i.common()
+	m["start_date"] = i.startDate.UTC().Format(dateTimeLayout)
+	m["status_change_date"] = i.statusChangeDate.UTC().Format(dateTimeLayout)
+	m["VEID"] = fmt.Sprintf("101%011d", i.id)
+	m["os"] = map[string]interface{}{
+		"id":       i.os.ID,
+		"name":     i.os.Name,
+		"viewname": i.os.ViewName,
+	}
+	m["ip"] = i.ip()
+	return m
+}
+
+type createInstanceRequest struct {
+	SSHKeyID     flexInt `json:"sshKeyId"`
+	WinPassword  string  `json:"winPassword"`
+	ImportURL    string  `json:"importUrl"`
+	SnapshotID   flexInt `json:"snapshotId"`
+	RegionID     flexInt `json:"regionId"`
+	OSID         flexInt `json:"osId"`
+	InstancePlan flexInt `json:"instancePlan"`
+	InstanceName string  `json:"instanceName"`
+}
+
+//nolint:cyclop,funlen
+func (s *Server) handleCreateInstance(w http.ResponseWriter, r *http.Request) {
+	var req createInstanceRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.InstanceName == "" {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "instanceName is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sp := s.catalog.findSpec(int64(req.InstancePlan))
+	if sp == nil {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "instancePlan is invalid")
+		return
+	}
+	if req.SSHKeyID != 0 {
+		if _, found := s.sshKeys[int64(req.SSHKeyID)]; !found {
+			s.writeError(w, http.StatusBadRequest, "Bad Request", "sshKeyId is invalid")
+			return
+		}
+	}
+
+	regionID := int64(req.RegionID)
+	var os *osEntry
+	if req.SnapshotID != 0 {
+		snap, found := s.snapshots[int64(req.SnapshotID)]
+		if !found {
+			s.writeError(w, http.StatusBadRequest, "Bad Request", "snapshotId is invalid")
+			return
+		}
+		os, regionID = snap.os, snap.regionID
+	} else {
+		os = s.catalog.findOS(int64(req.OSID))
+		if os == nil || os.InstanceTypeID != sp.InstanceTypeID {
+			s.writeError(w, http.StatusBadRequest, "Bad Request", "osId is invalid")
+			return
+		}
+		if !slices.ContainsFunc(s.catalog.regions[sp.InstanceTypeID], func(rg *region) bool { return rg.ID == regionID }) {
+			s.writeError(w, http.StatusBadRequest, "Bad Request", "regionId is invalid")
+			return
+		}
+	}
+
+	now := s.now()
+	i := &instance{
+		id:               s.nextID("instance"),
+		name:             req.InstanceName,
+		status:           "UNUSED",
+		sshKeyID:         int64(req.SSHKeyID),
+		regionID:         regionID,
+		spec:             sp,
+		os:               os,
+		uuid:             fmt.Sprintf("%s-%s-%s-%s-%s", randomHex(4), randomHex(2), randomHex(2), randomHex(2), randomHex(6)), //nolint:mnd
+		vncPasswd:        randomHex(8),                                                                                        //nolint:mnd
+		startDate:        now,
+		statusChangeDate: now,
+	}
+	s.instances[i.id] = i
+
+	s.writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"message": "Instance created  successfully",
+		"vms":     i.createdResponse(),
+	})
+}
+
+func (s *Server) sortedInstances() []*instance {
+	instances := make([]*instance, 0, len(s.instances))
+	for _, i := range s.instances {
+		instances = append(instances, i)
+	}
+	// NOTE: The API returns the newest instance first.
+	slices.SortFunc(instances, func(a, b *instance) int { return cmp.Compare(b.id, a.id) })
+	return instances
+}
+
+// settle completes the transitions of the instances and the snapshots whose transition delay has elapsed. s.mu must be held.
+func (s *Server) settle() {
+	now := s.now()
+	for _, i := range s.instances {
+		if i.targetStatus == "" || now.Before(i.settleAt) {
+			continue
+		}
+		s.setInstanceStatus(i, i.targetStatus, i.settleAt)
+	}
+	for _, snap := range s.snapshots {
+		if snap.targetStatus == "" || now.Before(snap.settleAt) {
+			continue
+		}
+		snap.setStatus(snap.targetStatus, snap.settleAt)
+	}
+}
+
+// setInstanceStatus sets the status of the instance, and removes the instance if it is destroyed. s.mu must be held.
+func (s *Server) setInstanceStatus(i *instance, status string, at time.Time) {
+	i.status = status
+	i.statusChangeDate = at
+	i.targetStatus = ""
+	i.settleAt = time.Time{}
+	if status == "destroyed" {
+		delete(s.instances, i.id)
+		for _, fw := range s.firewalls {
+			fw.instances = slices.DeleteFunc(fw.instances, func(id int64) bool { return id == i.id })
+		}
+	}
+}
+
+// SetInstanceStatus forces the status of the instance, e.g. to simulate an instance that failed on the API side.
+// It reports whether the instance exists.
+func (s *Server) SetInstanceStatus(id int64, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, found := s.instances[id]
+	if !found {
+		return false
+	}
+	s.setInstanceStatus(i, status, s.now())
+	return true
+}
+
+func (s *Server) handleGetInstanceList(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settle()
+
+	resp := make([]map[string]interface{}, 0, len(s.instances))
+	for _, i := range s.sortedInstances() {
+		resp = append(resp, i.listResponse())
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type instanceStatusTransition struct {
+	from        []string
+	to          string
+	via         string
+	message     string
+	successCode string
+}
+
+//nolint:gochecknoglobals
+var instanceStatusTransitions = map[string]instanceStatusTransition{
+	"start":     {from: []string{"UNUSED", "shutoff"}, to: "running", via: "starting", message: "Instance has started successfully ", successCode: "I20008"},
+	"stop":      {from: []string{"running"}, to: "shutoff", via: "stopping", message: "Instance has stopped successfully ", successCode: "I20009"},
+	"forcestop": {from: []string{"running"}, to: "shutoff", via: "stopping", message: "Instance has been force stopped successfully ", successCode: "I20010"},
+	"reset":     {from: []string{"running"}, to: "running", via: "resetting", message: "Instance has been reset successfully ", successCode: "I20011"},
+	"destroy":   {from: []string{"UNUSED", "running", "shutoff"}, to: "destroyed", via: "destroying", message: "Instance has been destroyed successfully ", successCode: "I20012"},
+}
+
+type instanceStatusUpdateRequest struct {
+	InstanceID flexInt `json:"instanceId"`
+	Status     string  `json:"status"`
+}
+
+func (s *Server) handleInstanceStatusUpdate(w http.ResponseWriter, r *http.Request) {
+	var req instanceStatusUpdateRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	transition, ok := instanceStatusTransitions[req.Status]
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", "status is invalid: "+req.Status)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settle()
+	i, found := s.instances[int64(req.InstanceID)]
+	if !found {
+		s.writeError(w, http.StatusNotFound, "Not Found", "Instance not found")
+		return
+	}
+	if !slices.Contains(transition.from, i.status) {
+		s.writeError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("cannot %s instance in status %s", req.Status, i.status))
+		return
+	}
+
+	now := s.now()
+	if s.transitionDelay > 0 {
+		i.status = transition.via
+		i.statusChangeDate = now
+		i.targetStatus = transition.to
+		i.settleAt = now.Add(s.transitionDelay)
+	} else {
+		s.setInstanceStatus(i, transition.to, now)
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":        true,
+		"message":        transition.message,
+		"sucessCode":     transition.successCode,
+		"instanceStatus": transition.to,
+	})
+}
@@ -0,0 +1,268 @@
+package indigo
+
+import (
+	"context"
+	"net/netip"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// InstanceFilter selects instances for ListInstances. The zero value matches all instances.
+// An instance matches if it satisfies all the conditions that are set.
+//
+// Example:
+//
+//	instances, err := client.ListInstances(ctx, &indigo.InstanceFilter{
+//		Statuses: []indigo.InstanceStatus{indigo.InstanceStatusRunning},
+//		NameGlob: "web-*",
+//		IP:       "192.0.2.0/24",
+//	})
+type InstanceFilter struct {
+	// Statuses matches the instances in one of the statuses.
+	Statuses []InstanceStatus
+	// Plans matches the instances of one of the plans, e.g. "2CR2GB".
+	Plans []string
+	// OsIDs matches the instances of one of the OS IDs.
+	OsIDs []int64
+	// NameGlob matches InstanceName with the pattern syntax of path.Match, e.g. "web-*".
+	NameGlob string
+	// NameRegexp matches InstanceName.
+	NameRegexp *regexp.Regexp
+	// IP matches the IP of the instances. It is either an address, e.g. "192.0.2.1", or a prefix, e.g. "192.0.2.0/24".
+	IP string
+}
+
+// matcher validates the filter and returns the function that reports whether an instance matches it.
+func (f *InstanceFilter) matcher() (func(instance *Instance) bool, error) {
+	if f == nil {
+		return func(*Instance) bool { return true }, nil
+	}
+
+	if f.NameGlob != "" {
+		if _, err := path.Match(f.NameGlob, ""); err != nil {
+			return nil, errorz.Errorf("nameGlob=%q: %w", f.NameGlob, ErrInvalidArgument)
+		}
+	}
+
+	var prefix netip.Prefix
+	if f.IP != "" {
+		var err error
+		if strings.Contains(f.IP, "/") {
+			prefix, err = netip.ParsePrefix(f.IP)
+		} else {
+			var addr netip.Addr
+			addr, err = netip.ParseAddr(f.IP)
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		if err != nil {
+			return nil, errorz.Errorf("ip=%q: %w", f.IP, ErrInvalidArgument)
+		}
+	}
+
+	return func(instance *Instance) bool {
+		if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, instance.Status) {
+			return false
+		}
+		if len(f.Plans) > 0 && !slices.Contains(f.Plans, instance.Plan) {
+			return false
+		}
+		if len(f.OsIDs) > 0 && !slices.Contains(f.OsIDs, instance.OsID) {
+			return false
+		}
+		if f.NameGlob != "" {
+			if ok, _ := path.Match(f.NameGlob, instance.InstanceName); !ok {
+				return false
+			}
+		}
+		if f.NameRegexp != nil && !f.NameRegexp.MatchString(instance.InstanceName) {
+			return false
+		}
+		if prefix.IsValid() {
+			addr, err := netip.ParseAddr(instance.IP)
+			if err != nil || !prefix.Contains(addr) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// GetInstance returns the instance of id, or ErrNotFound if it does not exist.
+//
+// The API has no endpoint to get an instance, so it looks up GetWebArenaIndigoV1VmGetInstanceList,
+// which may be cached by ClientOptionWithInstanceCache.
+func (c *Client) GetInstance(ctx context.Context, id int64) (*Instance, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(id)))
+	defer span.End()
+
+	instances, err := c.listInstances(ctx)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.listInstances: %w", err)
+	}
+
+	i := slices.IndexFunc(instances, func(instance Instance) bool { return instance.ID == id })
+	if i < 0 {
+		err := errorz.Errorf("instanceID=%d: %w", id, ErrNotFound)
+		recordError(span, err)
+		return nil, err
+	}
+
+	return &instances[i], nil
+}
+
+// FindInstanceByName returns the instance whose InstanceName is name.
+// It returns ErrNotFound if no instance has the name, and ErrMultipleFound if more than one instance has it.
+func (c *Client) FindInstanceByName(ctx context.Context, name string) (*Instance, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceName.String(name)))
+	defer span.End()
+
+	instances, err := c.listInstances(ctx)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.listInstances: %w", err)
+	}
+
+	var found *Instance
+	for i := range instances {
+		if instances[i].InstanceName != name {
+			continue
+		}
+		if found != nil {
+			err := errorz.Errorf("instanceName=%q instanceIDs=[%d %d]: %w", name, found.ID, instances[i].ID, ErrMultipleFound)
+			recordError(span, err)
+			return nil, err
+		}
+		found = &instances[i]
+	}
+	if found == nil {
+		err := errorz.Errorf("instanceName=%q: %w", name, ErrNotFound)
+		recordError(span, err)
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// ListInstances returns the instances that match filter. A nil filter matches all instances.
+// It returns ErrInvalidArgument if filter has an invalid NameGlob or IP.
+func (c *Client) ListInstances(ctx context.Context, filter *InstanceFilter) ([]Instance, error) {
+	ctx, span := c.start(ctx)
+	defer span.End()
+
+	match, err := filter.matcher()
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("filter.matcher: %w", err)
+	}
+
+	instances, err := c.listInstances(ctx)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.listInstances: %w", err)
+	}
+
+	matched := make([]Instance, 0, len(instances))
+	for i := range instances {
+		if match(&instances[i]) {
+			matched = append(matched, instances[i])
+		}
+	}
+	span.SetAttributes(attributeKeyInstanceCount.Int(len(matched)))
+
+	return matched, nil
+}
+
+// listInstances returns the instance list from the cache if ClientOptionWithInstanceCache is set, or from the API otherwise.
+// The returned slice is owned by the caller.
+func (c *Client) listInstances(ctx context.Context) ([]Instance, error) {
+	if c.instanceCache == nil {
+		instances, err := c.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+		if err != nil {
+			return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmGetInstanceList: %w", err)
+		}
+		return instances, nil
+	}
+
+	instances, err := c.instanceCache.get(ctx, bypassCache(ctx), c.GetWebArenaIndigoV1VmGetInstanceList)
+	if err != nil {
+		return nil, errorz.Errorf("c.instanceCache.get: %w", err)
+	}
+	return instances, nil
+}
+
+// invalidateInstanceCache drops the cached instance list. It is called after the requests that change instances.
+func (c *Client) invalidateInstanceCache() {
+	if c.instanceCache != nil {
+		c.instanceCache.invalidate()
+	}
+}
+
+// instanceListCache caches the instance list for a short time,
+// so that the lookups in a reconcile loop do not each spend the quota of the API, which allows only 6 requests per minute.
+type instanceListCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	// mu is held while the list is fetched, so that concurrent lookups share one request.
+	mu        sync.Mutex
+	instances []Instance
+	expiresAt time.Time
+}
+
+func newInstanceListCache(ttl time.Duration) *instanceListCache {
+	return &instanceListCache{ttl: ttl, now: time.Now}
+}
+
+// get returns the cached list, or fetches it if the cache has expired or refresh is true.
+func (c *instanceListCache) get(ctx context.Context, refresh bool, fetch func(ctx context.Context) (GetWebArenaIndigoV1VmGetInstanceListResponse, error)) ([]Instance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if refresh || c.instances == nil || !c.now().Before(c.expiresAt) {
+		instances, err := fetch(ctx)
+		if err != nil {
+			return nil, errorz.Errorf("fetch: %w", err)
+		}
+		if instances == nil {
+			instances = GetWebArenaIndigoV1VmGetInstanceListResponse{}
+		}
+		c.instances, c.expiresAt = instances, c.now().Add(c.ttl)
+	}
+
+	return slices.Clone(c.instances), nil
+}
+
+func (c *instanceListCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.instances = nil
+}
+
+type instanceCacheOption struct{ ttl time.Duration }
+
+func (o *instanceCacheOption) apply(c *Client) {
+	if o.ttl <= 0 {
+		c.instanceCache = nil
+		return
+	}
+	c.instanceCache = newInstanceListCache(o.ttl)
+}
+
+// ClientOptionWithInstanceCache makes GetInstance, FindInstanceByName and ListInstances share the instance list for ttl.
+// The cache is dropped when an instance is created or its status is updated through the client.
+// Use ContextWithoutCache to fetch the list for a call, and Client.InvalidateCache to drop the cache.
+// The waiters such as WaitForInstanceStatus and the status checks of StartInstance and the like always fetch the list.
+// By default, the list is not cached.
+func ClientOptionWithInstanceCache(ttl time.Duration) ClientOption { //nolint:ireturn
+	return &instanceCacheOption{ttl: ttl}
+}
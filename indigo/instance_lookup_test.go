@@ -0,0 +1,214 @@
+package indigo
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+)
+
+// isInstanceListPath reports whether path is of the instance list.
+func isInstanceListPath(path string) bool { return path == PathWebArenaIndigoV1VmGetInstanceList }
+
+func createNamedTestInstance(ctx context.Context, tb testing.TB, client *Client, name string, plan int64) int64 {
+	tb.Helper()
+
+	created, err := client.PostWebArenaIndigoV1VmCreateInstance(ctx, &PostWebArenaIndigoV1VmCreateInstanceRequest{
+		RegionID:     1,
+		OsID:         1,
+		InstancePlan: plan,
+		InstanceName: name,
+	})
+	requirez.NoError(tb, err)
+
+	return created.Vms.ID
+}
+
+//nolint:funlen
+func TestClient_instanceLookup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,getAndFind", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, _ := newRequestCountingClient(ctx, t, isInstanceListPath)
+		webID := createNamedTestInstance(ctx, t, client, "web-1", 1)
+		dbID := createNamedTestInstance(ctx, t, client, "db-1", 1)
+
+		got, err := client.GetInstance(ctx, dbID)
+		requirez.NoError(t, err)
+		requirez.Equal(t, "db-1", got.InstanceName)
+
+		found, err := client.FindInstanceByName(ctx, "web-1")
+		requirez.NoError(t, err)
+		requirez.Equal(t, webID, found.ID)
+	})
+
+	t.Run("failure,notFound", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, _ := newRequestCountingClient(ctx, t, isInstanceListPath)
+		createNamedTestInstance(ctx, t, client, "web-1", 1)
+
+		_, err := client.GetInstance(ctx, 999)
+		requirez.ErrorIs(t, err, ErrNotFound)
+
+		_, err = client.FindInstanceByName(ctx, "web")
+		requirez.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("failure,multipleFound", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, _ := newRequestCountingClient(ctx, t, isInstanceListPath)
+		createNamedTestInstance(ctx, t, client, "web", 1)
+		createNamedTestInstance(ctx, t, client, "web", 1)
+
+		_, err := client.FindInstanceByName(ctx, "web")
+		requirez.ErrorIs(t, err, ErrMultipleFound)
+	})
+
+	t.Run("success,filter", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, _ := newRequestCountingClient(ctx, t, isInstanceListPath)
+		web1 := createNamedTestInstance(ctx, t, client, "web-1", 1)
+		web2 := createNamedTestInstance(ctx, t, client, "web-2", 2)
+		db1 := createNamedTestInstance(ctx, t, client, "db-1", 1)
+		updateTestInstanceStatus(ctx, t, client, web2, InstanceActionStart)
+		web2Instance, err := client.GetInstance(ctx, web2)
+		requirez.NoError(t, err)
+
+		ids := func(instances []Instance) []int64 {
+			ids := make([]int64, 0, len(instances))
+			for _, instance := range instances {
+				ids = append(ids, instance.ID)
+			}
+			slices.Sort(ids)
+			return ids
+		}
+
+		tests := []struct {
+			name   string
+			filter *InstanceFilter
+			want   []int64
+		}{
+			{name: "nil", filter: nil, want: []int64{web1, web2, db1}},
+			{name: "zero", filter: &InstanceFilter{}, want: []int64{web1, web2, db1}},
+			{name: "status", filter: &InstanceFilter{Statuses: []InstanceStatus{InstanceStatusRunning}}, want: []int64{web2}},
+			{name: "plan", filter: &InstanceFilter{Plans: []string{web2Instance.Plan}}, want: []int64{web2}},
+			{name: "osID", filter: &InstanceFilter{OsIDs: []int64{web2Instance.OsID}}, want: []int64{web1, web2, db1}},
+			{name: "glob", filter: &InstanceFilter{NameGlob: "web-*"}, want: []int64{web1, web2}},
+			{name: "regexp", filter: &InstanceFilter{NameRegexp: regexp.MustCompile(`^(web-1|db-1)$`)}, want: []int64{web1, db1}},
+			{name: "ip", filter: &InstanceFilter{IP: web2Instance.IP}, want: []int64{web2}},
+			{name: "prefix", filter: &InstanceFilter{IP: web2Instance.IP + "/16"}, want: []int64{web1, web2, db1}},
+			{name: "and", filter: &InstanceFilter{NameGlob: "web-*", Plans: []string{web2Instance.Plan}}, want: []int64{web2}},
+			{name: "none", filter: &InstanceFilter{NameGlob: "app-*"}, want: []int64{}},
+		}
+		for _, tt := range tests {
+			got, err := client.ListInstances(ctx, tt.filter)
+			requirez.NoError(t, err)
+			requirez.Equal(t, tt.want, ids(got))
+		}
+	})
+
+	t.Run("failure,invalidFilter", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, listed := newRequestCountingClient(ctx, t, isInstanceListPath)
+
+		_, err := client.ListInstances(ctx, &InstanceFilter{NameGlob: "["})
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+
+		_, err = client.ListInstances(ctx, &InstanceFilter{IP: "192.0.2"})
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+
+		requirez.Equal(t, int64(0), listed.Load())
+	})
+
+	t.Run("success,withoutCache", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, listed := newRequestCountingClient(ctx, t, isInstanceListPath)
+		id := createNamedTestInstance(ctx, t, client, "web-1", 1)
+
+		for range 3 {
+			_, err := client.GetInstance(ctx, id)
+			requirez.NoError(t, err)
+		}
+		requirez.Equal(t, int64(3), listed.Load())
+	})
+
+	t.Run("success,cache", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client, _, listed := newRequestCountingClient(ctx, t, isInstanceListPath, ClientOptionWithInstanceCache(time.Minute))
+		id := createNamedTestInstance(ctx, t, client, "web-1", 1)
+
+		_, err := client.GetInstance(ctx, id)
+		requirez.NoError(t, err)
+		_, err = client.FindInstanceByName(ctx, "web-1")
+		requirez.NoError(t, err)
+		_, err = client.ListInstances(ctx, nil)
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(1), listed.Load())
+
+		// NOTE: Creating an instance drops the cache, so the new instance is found.
+		newID := createNamedTestInstance(ctx, t, client, "web-2", 1)
+		got, err := client.GetInstance(ctx, newID)
+		requirez.NoError(t, err)
+		requirez.Equal(t, "web-2", got.InstanceName)
+		requirez.Equal(t, int64(2), listed.Load())
+
+		// NOTE: Modifying the returned instance does not modify the cache.
+		got.InstanceName = "modified"
+		again, err := client.GetInstance(ctx, newID)
+		requirez.NoError(t, err)
+		requirez.Equal(t, "web-2", again.InstanceName)
+		requirez.Equal(t, int64(2), listed.Load())
+	})
+
+	t.Run("success,cacheExpires", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2024, 5, 12, 13, 41, 52, 0, time.UTC)
+		cache := newInstanceListCache(time.Minute)
+		cache.now = func() time.Time { return now }
+
+		fetched := 0
+		fetch := func(context.Context) (GetWebArenaIndigoV1VmGetInstanceListResponse, error) {
+			fetched++
+			return GetWebArenaIndigoV1VmGetInstanceListResponse{{ID: int64(fetched)}}, nil
+		}
+
+		ctx := context.Background()
+		for _, tt := range []struct {
+			advance time.Duration
+			want    int64
+		}{
+			{0, 1},
+			{59 * time.Second, 1},
+			{time.Second, 2},
+		} {
+			now = now.Add(tt.advance)
+			got, err := cache.get(ctx, false, fetch)
+			requirez.NoError(t, err)
+			requirez.Equal(t, tt.want, got[0].ID)
+		}
+
+		cache.invalidate()
+		got, err := cache.get(ctx, false, fetch)
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(3), got[0].ID)
+	})
+}
@@ -0,0 +1,155 @@
+package indigo
+
+import (
+	"context"
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// InstanceStatus is the status of an instance, reported by WebArenaIndigoV1VmInstance.Status.
+type InstanceStatus string
+
+const (
+	// InstanceStatusUnused is the status of an instance that has been created and never started.
+	InstanceStatusUnused InstanceStatus = "UNUSED"
+	// InstanceStatusRunning is the status of a running instance.
+	InstanceStatusRunning InstanceStatus = "running"
+	// InstanceStatusShutoff is the status of a stopped instance.
+	InstanceStatusShutoff InstanceStatus = "shutoff"
+	// InstanceStatusDestroyed is the status that a destroyed instance reaches.
+	// The API removes a destroyed instance from GetWebArenaIndigoV1VmGetInstanceList instead of reporting this status.
+	InstanceStatusDestroyed InstanceStatus = "destroyed"
+)
+
+// InstanceAction is the action of PostWebArenaIndigoV1VmInstanceStatusUpdateRequest.Status.
+type InstanceAction string
+
+const (
+	// InstanceActionStart boots an instance that is "UNUSED" or "shutoff".
+	InstanceActionStart InstanceAction = "start"
+	// InstanceActionStop shuts a running instance down gracefully, as the OS is asked to shut down.
+	InstanceActionStop InstanceAction = "stop"
+	// InstanceActionForceStop powers a running instance off without waiting for the OS to shut down, like pulling the plug.
+	// Data not yet written to the disk may be lost. Use it only if InstanceActionStop does not stop the instance.
+	InstanceActionForceStop InstanceAction = "forcestop"
+	// InstanceActionReset hard-resets a running instance, like pressing the reset button. The OS is restarted without shutting down.
+	InstanceActionReset InstanceAction = "reset"
+	// InstanceActionDestroy deletes an instance and its disk permanently. It cannot be undone.
+	InstanceActionDestroy InstanceAction = "destroy"
+)
+
+type instanceTransition struct {
+	from []InstanceStatus
+	to   InstanceStatus
+}
+
+// instanceTransitions is the state machine of the instance status:
+//
+//	action    | from                     | to
+//	----------+--------------------------+----------
+//	start     | UNUSED, shutoff          | running
+//	stop      | running                  | shutoff
+//	forcestop | running                  | shutoff
+//	reset     | running                  | running
+//	destroy   | UNUSED, running, shutoff | destroyed
+//
+// While an instance is in transition, it may be reported in a status other than the above, and no action is valid.
+//
+//nolint:gochecknoglobals
+var instanceTransitions = map[InstanceAction]instanceTransition{
+	InstanceActionStart:     {from: []InstanceStatus{InstanceStatusUnused, InstanceStatusShutoff}, to: InstanceStatusRunning},
+	InstanceActionStop:      {from: []InstanceStatus{InstanceStatusRunning}, to: InstanceStatusShutoff},
+	InstanceActionForceStop: {from: []InstanceStatus{InstanceStatusRunning}, to: InstanceStatusShutoff},
+	InstanceActionReset:     {from: []InstanceStatus{InstanceStatusRunning}, to: InstanceStatusRunning},
+	InstanceActionDestroy:   {from: []InstanceStatus{InstanceStatusUnused, InstanceStatusRunning, InstanceStatusShutoff}, to: InstanceStatusDestroyed},
+}
+
+// instanceFailureStatuses is the statuses from which an instance never reaches the other statuses by itself.
+//
+//nolint:gochecknoglobals
+var instanceFailureStatuses = []InstanceStatus{"error", "failed"}
+
+// ValidFrom reports whether the action is valid for an instance in status.
+func (a InstanceAction) ValidFrom(status InstanceStatus) bool {
+	t, ok := instanceTransitions[a]
+	return ok && slices.Contains(t.from, status)
+}
+
+// Target returns the status that an instance reaches by the action, or "" if the action is unknown.
+func (a InstanceAction) Target() InstanceStatus {
+	return instanceTransitions[a].to
+}
+
+// Actions returns the actions that are valid for an instance in the status.
+func (s InstanceStatus) Actions() []InstanceAction {
+	var actions []InstanceAction
+	for _, a := range []InstanceAction{InstanceActionStart, InstanceActionStop, InstanceActionForceStop, InstanceActionReset, InstanceActionDestroy} {
+		if a.ValidFrom(s) {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// StartInstance starts the instance. It returns ErrInvalidInstanceTransition without calling the API if the instance is not UNUSED or shutoff.
+func (c *Client) StartInstance(ctx context.Context, id int64) (*PostWebArenaIndigoV1VmInstanceStatusUpdateResponse, error) {
+	return c.updateInstanceStatus(ctx, id, InstanceActionStart)
+}
+
+// StopInstance stops the instance. It returns ErrInvalidInstanceTransition without calling the API if the instance is not running.
+func (c *Client) StopInstance(ctx context.Context, id int64) (*PostWebArenaIndigoV1VmInstanceStatusUpdateResponse, error) {
+	return c.updateInstanceStatus(ctx, id, InstanceActionStop)
+}
+
+// ForceStopInstance forces the instance to stop. It returns ErrInvalidInstanceTransition without calling the API if the instance is not running.
+func (c *Client) ForceStopInstance(ctx context.Context, id int64) (*PostWebArenaIndigoV1VmInstanceStatusUpdateResponse, error) {
+	return c.updateInstanceStatus(ctx, id, InstanceActionForceStop)
+}
+
+// ResetInstance resets the instance. It returns ErrInvalidInstanceTransition without calling the API if the instance is not running.
+func (c *Client) ResetInstance(ctx context.Context, id int64) (*PostWebArenaIndigoV1VmInstanceStatusUpdateResponse, error) {
+	return c.updateInstanceStatus(ctx, id, InstanceActionReset)
+}
+
+// DestroyInstance destroys the instance. It returns ErrInvalidInstanceTransition without calling the API if the instance is in transition.
+func (c *Client) DestroyInstance(ctx context.Context, id int64) (*PostWebArenaIndigoV1VmInstanceStatusUpdateResponse, error) {
+	return c.updateInstanceStatus(ctx, id, InstanceActionDestroy)
+}
+
+// updateInstanceStatus looks up the status of the instance, checks that the action is valid for it, and requests the action.
+// The returned response reports the status that the instance reaches, but the instance may still be in transition. Use WaitForInstanceStatus to wait for it.
+func (c *Client) updateInstanceStatus(ctx context.Context, id int64, action InstanceAction) (*PostWebArenaIndigoV1VmInstanceStatusUpdateResponse, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(id)))
+	defer span.End()
+
+	instances, err := c.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmGetInstanceList: %w", err)
+	}
+	i := slices.IndexFunc(instances, func(instance WebArenaIndigoV1VmInstance) bool { return instance.ID == id })
+	if i < 0 {
+		err := errorz.Errorf("instanceID=%d: %w", id, ErrNotFound)
+		recordError(span, err)
+		return nil, err
+	}
+	if status := instances[i].Status; !action.ValidFrom(status) {
+		err := errorz.Errorf("instanceID=%d status=%s action=%s: %w", id, status, action, ErrInvalidInstanceTransition)
+		recordError(span, err)
+		return nil, err
+	}
+
+	resp, err := c.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{
+		InstanceID: id,
+		Status:     action,
+	})
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.PostWebArenaIndigoV1VmInstanceStatusUpdate: %w", err)
+	}
+
+	return resp, nil
+}
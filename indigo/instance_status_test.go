@@ -0,0 +1,140 @@
+package indigo
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func TestInstanceAction_ValidFrom(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		action InstanceAction
+		from   InstanceStatus
+		want   bool
+	}{
+		{action: InstanceActionStart, from: InstanceStatusUnused, want: true},
+		{action: InstanceActionStart, from: InstanceStatusShutoff, want: true},
+		{action: InstanceActionStart, from: InstanceStatusRunning, want: false},
+		{action: InstanceActionStop, from: InstanceStatusRunning, want: true},
+		{action: InstanceActionStop, from: InstanceStatusShutoff, want: false},
+		{action: InstanceActionForceStop, from: InstanceStatusRunning, want: true},
+		{action: InstanceActionReset, from: InstanceStatusRunning, want: true},
+		{action: InstanceActionReset, from: InstanceStatusUnused, want: false},
+		{action: InstanceActionDestroy, from: InstanceStatusShutoff, want: true},
+		{action: InstanceActionDestroy, from: "starting", want: false},
+		{action: "unknown", from: InstanceStatusRunning, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.action)+","+string(tt.from), func(t *testing.T) {
+			t.Parallel()
+
+			requirez.Equal(t, tt.want, tt.action.ValidFrom(tt.from))
+		})
+	}
+
+	t.Run("success,Target", func(t *testing.T) {
+		t.Parallel()
+
+		requirez.Equal(t, InstanceStatusRunning, InstanceActionStart.Target())
+		requirez.Equal(t, InstanceStatusShutoff, InstanceActionForceStop.Target())
+		requirez.Equal(t, InstanceStatusDestroyed, InstanceActionDestroy.Target())
+		requirez.Equal(t, InstanceStatus(""), InstanceAction("unknown").Target())
+	})
+
+	t.Run("success,Actions", func(t *testing.T) {
+		t.Parallel()
+
+		requirez.Equal(t, []InstanceAction{InstanceActionStop, InstanceActionForceStop, InstanceActionReset, InstanceActionDestroy}, InstanceStatusRunning.Actions())
+		requirez.Equal(t, []InstanceAction{InstanceActionStart, InstanceActionDestroy}, InstanceStatusShutoff.Actions())
+		requirez.Equal(t, 0, len(InstanceStatus("starting").Actions()))
+	})
+}
+
+//nolint:funlen
+func TestClient_updateInstanceStatus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		id := createTestInstance(ctx, t, client)
+
+		resp, err := client.StartInstance(ctx, id)
+		requirez.NoError(t, err)
+		requirez.Equal(t, InstanceStatusRunning, resp.InstanceStatus)
+
+		resp, err = client.ResetInstance(ctx, id)
+		requirez.NoError(t, err)
+		requirez.Equal(t, InstanceStatusRunning, resp.InstanceStatus)
+
+		resp, err = client.StopInstance(ctx, id)
+		requirez.NoError(t, err)
+		requirez.Equal(t, InstanceStatusShutoff, resp.InstanceStatus)
+
+		_, err = client.StartInstance(ctx, id)
+		requirez.NoError(t, err)
+		resp, err = client.ForceStopInstance(ctx, id)
+		requirez.NoError(t, err)
+		requirez.Equal(t, InstanceStatusShutoff, resp.InstanceStatus)
+
+		resp, err = client.DestroyInstance(ctx, id)
+		requirez.NoError(t, err)
+		requirez.Equal(t, InstanceStatusDestroyed, resp.InstanceStatus)
+	})
+
+	t.Run("failure,invalidTransition", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		var updates atomic.Int64
+		stub := newStubServer(t, srv, func(_ http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path == PathWebArenaIndigoV1VmInstanceStatusUpdate {
+				updates.Add(1)
+			}
+			return false
+		})
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithEndpoint(stub.URL))...)
+		requirez.NoError(t, err)
+
+		id := createTestInstance(ctx, t, client)
+
+		_, err = client.StopInstance(ctx, id)
+		requirez.ErrorIs(t, err, ErrInvalidInstanceTransition)
+		requirez.ErrorContains(t, err, "status=UNUSED action=stop")
+		_, err = client.ResetInstance(ctx, id)
+		requirez.ErrorIs(t, err, ErrInvalidInstanceTransition)
+		requirez.Equal(t, int64(0), updates.Load())
+	})
+
+	t.Run("failure,notFound", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		_, err = client.StartInstance(ctx, 999)
+		requirez.ErrorIs(t, err, ErrNotFound)
+	})
+}
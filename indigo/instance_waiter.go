@@ -0,0 +1,72 @@
+package indigo
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// WaitForInstanceStatus polls GetWebArenaIndigoV1VmGetInstanceList until the instance of id is in one of targets,
+// and returns the instance in that status.
+//
+// PostWebArenaIndigoV1VmInstanceStatusUpdate returns before the instance actually changes its status,
+// and PostWebArenaIndigoV1VmCreateInstance returns the instance as "UNUSED", so call this to wait for "running" or "shutoff".
+// If targets contains InstanceStatusDestroyed, the wait succeeds once the instance disappears from the list, and the returned instance is nil.
+//
+// The polls follow the WaitPolicy of the client, or the one set by ContextWithWaitPolicy.
+// It fails fast with ErrNotFound if the instance disappears, and with ErrWaitTerminalStatus if the instance is in a failure status such as "error".
+// It returns ErrWaitTimeout if WaitPolicy.Timeout elapses.
+//
+// Example:
+//
+//	if _, err := client.StartInstance(ctx, 16); err != nil {
+//		return err
+//	}
+//	instance, err := client.WaitForInstanceStatus(ctx, 16, indigo.InstanceStatusRunning)
+func (c *Client) WaitForInstanceStatus(ctx context.Context, id int64, targets ...InstanceStatus) (*WebArenaIndigoV1VmInstance, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(id)))
+	defer span.End()
+
+	if len(targets) == 0 {
+		err := errorz.Errorf("instanceID=%d: targets must not be empty: %w", id, ErrInvalidArgument)
+		recordError(span, err)
+		return nil, err
+	}
+
+	var found *WebArenaIndigoV1VmInstance
+	if err := c.wait(ctx, func(ctx context.Context) (string, bool, error) {
+		instances, err := c.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+		if err != nil {
+			return "", false, errorz.Errorf("c.GetWebArenaIndigoV1VmGetInstanceList: %w", err)
+		}
+
+		i := slices.IndexFunc(instances, func(instance WebArenaIndigoV1VmInstance) bool { return instance.ID == id })
+		if i < 0 {
+			if slices.Contains(targets, InstanceStatusDestroyed) {
+				found = nil
+				return string(InstanceStatusDestroyed), true, nil
+			}
+			return "", false, errorz.Errorf("instanceID=%d: %w", id, ErrNotFound)
+		}
+
+		found = &instances[i]
+		status := found.Status
+		if slices.Contains(targets, status) {
+			return string(status), true, nil
+		}
+		if slices.ContainsFunc(instanceFailureStatuses, func(s InstanceStatus) bool { return strings.EqualFold(string(s), string(status)) }) {
+			return string(status), false, errorz.Errorf("instanceID=%d status=%s: %w", id, status, ErrWaitTerminalStatus)
+		}
+		return string(status), false, nil
+	}); err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.wait: %w", err)
+	}
+
+	// NOTE: found is nil if the instance has been destroyed.
+	return found, nil
+}
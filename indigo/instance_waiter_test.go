@@ -0,0 +1,209 @@
+package indigo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+type progressRecorder struct {
+	mu       sync.Mutex
+	progress []WaitProgress
+}
+
+func (r *progressRecorder) record(p WaitProgress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = append(r.progress, p)
+}
+
+func (r *progressRecorder) statuses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statuses := make([]string, 0, len(r.progress))
+	for _, p := range r.progress {
+		statuses = append(statuses, p.Status)
+	}
+	return statuses
+}
+
+func newTestWaitPolicy(onProgress func(WaitProgress)) *WaitPolicy {
+	return &WaitPolicy{
+		Timeout:         5 * time.Second,
+		InitialInterval: 5 * time.Millisecond,
+		MaxInterval:     20 * time.Millisecond,
+		OnProgress:      onProgress,
+	}
+}
+
+func createTestInstance(ctx context.Context, tb testing.TB, client *Client) int64 {
+	tb.Helper()
+
+	created, err := client.PostWebArenaIndigoV1VmCreateInstance(ctx, &PostWebArenaIndigoV1VmCreateInstanceRequest{
+		RegionID:     1,
+		OsID:         1,
+		InstancePlan: 1,
+		InstanceName: "test-instance",
+	})
+	requirez.NoError(tb, err)
+
+	return created.Vms.ID
+}
+
+func updateTestInstanceStatus(ctx context.Context, tb testing.TB, client *Client, id int64, action InstanceAction) {
+	tb.Helper()
+
+	_, err := client.PostWebArenaIndigoV1VmInstanceStatusUpdate(ctx, &PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{InstanceID: id, Status: action})
+	requirez.NoError(tb, err)
+}
+
+//nolint:funlen
+func TestClient_WaitForInstanceStatus(t *testing.T) {
+	t.Parallel()
+
+	const transitionDelay = 50 * time.Millisecond
+
+	t.Run("success,start", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(transitionDelay))
+		t.Cleanup(srv.Close)
+
+		recorder := &progressRecorder{}
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithWaitPolicy(newTestWaitPolicy(recorder.record)))...)
+		requirez.NoError(t, err)
+
+		id := createTestInstance(ctx, t, client)
+		updateTestInstanceStatus(ctx, t, client, id, "start")
+
+		instance, err := client.WaitForInstanceStatus(ctx, id, "running")
+		requirez.NoError(t, err)
+		requirez.Equal(t, id, instance.ID)
+		requirez.Equal(t, InstanceStatusRunning, instance.Status)
+
+		statuses := recorder.statuses()
+		requirez.Equal(t, "starting", statuses[0])
+		requirez.Equal(t, "running", statuses[len(statuses)-1])
+		last := recorder.progress[len(recorder.progress)-1]
+		requirez.True(t, last.Done)
+		requirez.Equal(t, time.Duration(0), last.Next)
+		requirez.Equal(t, len(statuses), last.Poll)
+	})
+
+	t.Run("success,destroyed", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(transitionDelay))
+		t.Cleanup(srv.Close)
+
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(nil))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		id := createTestInstance(ctx, t, client)
+		updateTestInstanceStatus(ctx, t, client, id, "destroy")
+
+		instance, err := client.WaitForInstanceStatus(ctx, id, "destroyed")
+		requirez.NoError(t, err)
+		requirez.Nil(t, instance)
+	})
+
+	t.Run("failure,terminalStatus", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		recorder := &progressRecorder{}
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(recorder.record))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		id := createTestInstance(ctx, t, client)
+		requirez.True(t, srv.SetInstanceStatus(id, "ERROR"))
+
+		_, err = client.WaitForInstanceStatus(ctx, id, "running")
+		requirez.ErrorIs(t, err, ErrWaitTerminalStatus)
+		requirez.Equal(t, 0, len(recorder.statuses()))
+	})
+
+	t.Run("failure,notFound", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(nil))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		_, err = client.WaitForInstanceStatus(ctx, 999, "running")
+		requirez.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("failure,noTargets", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		_, err = client.WaitForInstanceStatus(ctx, 1)
+		requirez.ErrorIs(t, err, ErrInvalidArgument)
+	})
+
+	t.Run("failure,timeout", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		policy := newTestWaitPolicy(nil)
+		policy.Timeout = 50 * time.Millisecond
+		ctx := ContextWithWaitPolicy(context.Background(), policy)
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		id := createTestInstance(ctx, t, client)
+
+		_, err = client.WaitForInstanceStatus(ctx, id, "running")
+		requirez.ErrorIs(t, err, ErrWaitTimeout)
+		requirez.ErrorIs(t, err, context.DeadlineExceeded)
+		requirez.ErrorContains(t, err, `status="UNUSED"`)
+	})
+
+	t.Run("success,quotaAware", func(t *testing.T) {
+		t.Parallel()
+
+		const window = time.Second
+		srv := indigotest.NewServer(indigotest.ServerOptionWithQuota(4, window))
+		t.Cleanup(srv.Close)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		recorder := &progressRecorder{}
+		ctx = ContextWithWaitPolicy(ctx, newTestWaitPolicy(func(p WaitProgress) {
+			recorder.record(p)
+			cancel()
+		}))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		id := createTestInstance(ctx, t, client)
+
+		// NOTE: The access token, the instance and the first poll leave 1 request of the quota, so the next poll waits for about the rest of the window.
+		_, err = client.WaitForInstanceStatus(ctx, id, "running")
+		requirez.ErrorIs(t, err, context.Canceled)
+		requirez.Equal(t, 1, len(recorder.progress))
+		requirez.True(t, recorder.progress[0].Next > window/2)
+	})
+}
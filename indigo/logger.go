@@ -0,0 +1,142 @@
+package indigo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type slogLoggerOption struct{ logger *slog.Logger }
+
+func (o *slogLoggerOption) apply(c *Client) { c.logger = o.logger }
+
+// ClientOptionWithSlogLogger makes the client emit one structured record per attempt to logger.
+//
+// The record has the attributes method, path (the path template such as /webarenaIndigo/v1/vm/sshkey/{id}), status, latency,
+// attempt, quota.allowed, quota.available, quota.reset, request_id and error.
+// If the API responded with an error, error is the kind of the error, e.g. ErrAPIReturnsTooManyRequest, and error_code is APIError.ErrorCode.
+// The record is logged at LevelInfo if the attempt succeeded, otherwise at LevelWarn.
+//
+// If logger is enabled for LevelDebug, the client also emits a LevelDebug record per attempt with request_body and response_body,
+// which are redacted by the Redactor of the client. It has method, path, attempt and request_id to correlate it with the record of the attempt,
+// so that the bodies are never written by a handler that keeps only LevelInfo and above.
+func ClientOptionWithSlogLogger(logger *slog.Logger) ClientOption { //nolint:ireturn
+	return &slogLoggerOption{logger: logger}
+}
+
+// pathTemplate returns the path template of p by replacing the trailing ID segment with {id}, e.g. /webarenaIndigo/v1/vm/sshkey/{id}.
+func pathTemplate(p string) string {
+	i := strings.LastIndexByte(p, '/')
+	if i < 0 || i == len(p)-1 {
+		return p
+	}
+	for _, r := range p[i+1:] {
+		if r < '0' || '9' < r {
+			return p
+		}
+	}
+	return p[:i+1] + "{id}"
+}
+
+type attemptLog struct {
+	req     *http.Request
+	resp    *http.Response
+	attempt int
+	latency time.Duration
+	// reqBody and respBody are set only if the logger is enabled for LevelDebug.
+	reqBody  []byte
+	respBody []byte
+	err      error
+}
+
+func (c *Client) logBodies(ctx context.Context) bool {
+	return c.logger != nil && c.logger.Enabled(ctx, slog.LevelDebug)
+}
+
+// readBody reads the body of req or resp for logging, leaving it readable.
+func readBody(getBody func() (io.ReadCloser, error)) []byte {
+	if getBody == nil {
+		return nil
+	}
+	body, err := getBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	b, _ := io.ReadAll(body)
+	return b
+}
+
+func readResponseBody(resp *http.Response) []byte {
+	b, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return b
+}
+
+func (c *Client) logAttempt(ctx context.Context, l *attemptLog) {
+	if c.logger == nil || l.req == nil {
+		return
+	}
+
+	level := slog.LevelInfo
+	if l.err != nil {
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", l.req.Method),
+		slog.String("path", pathTemplate(l.req.URL.Path)),
+		slog.Int("attempt", l.attempt),
+		slog.Duration("latency", l.latency),
+	}
+	if l.resp != nil {
+		attrs = append(attrs,
+			slog.Int("status", l.resp.StatusCode),
+			slog.String("request_id", l.resp.Header.Get("X-Request-Id")),
+		)
+		if allowed := l.resp.Header.Get("X-Quota-Allowed"); allowed != "" {
+			attrs = append(attrs, slog.Group("quota",
+				slog.String("allowed", allowed),
+				slog.String("available", l.resp.Header.Get("X-Quota-Available")),
+				slog.String("reset", l.resp.Header.Get("X-Quota-Reset")),
+			))
+		}
+	}
+	if l.err != nil {
+		// NOTE: The message of APIError has the response body, which is logged only in the record of the bodies, redacted.
+		var apiErr *APIError
+		if errors.As(l.err, &apiErr) {
+			attrs = append(attrs, slog.String("error", apiErr.err.Error()), slog.String("error_code", apiErr.ErrorCode))
+		} else {
+			attrs = append(attrs, slog.String("error", l.err.Error()))
+		}
+	}
+
+	c.logger.LogAttrs(ctx, level, "indigo: request", attrs...)
+
+	if l.reqBody == nil && l.respBody == nil {
+		return
+	}
+	bodyAttrs := []slog.Attr{
+		slog.String("method", l.req.Method),
+		slog.String("path", pathTemplate(l.req.URL.Path)),
+		slog.Int("attempt", l.attempt),
+	}
+	if l.resp != nil {
+		bodyAttrs = append(bodyAttrs, slog.String("request_id", l.resp.Header.Get("X-Request-Id")))
+	}
+	if l.reqBody != nil {
+		bodyAttrs = append(bodyAttrs, slog.String("request_body", string(c.redactor.redactBody(l.reqBody))))
+	}
+	if l.respBody != nil {
+		bodyAttrs = append(bodyAttrs, slog.String("response_body", string(c.redactor.redactBody(l.respBody))))
+	}
+
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "indigo: request body", bodyAttrs...)
+}
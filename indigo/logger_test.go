@@ -0,0 +1,179 @@
+package indigo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func TestPathTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{PathWebArenaIndigoV1VmSSHKey, PathWebArenaIndigoV1VmSSHKey},
+		{PathWebArenaIndigoV1VmSSHKey + "/123", PathWebArenaIndigoV1VmSSHKey + "/{id}"},
+		{PathWebArenaIndigoV1NwGetTemplate + "/1", PathWebArenaIndigoV1NwGetTemplate + "/{id}"},
+		{PathWebArenaIndigoV1VmSSHKeyActiveStatus, PathWebArenaIndigoV1VmSSHKeyActiveStatus},
+		{"/", "/"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run("success,"+tt.path, func(t *testing.T) {
+			t.Parallel()
+			requirez.Equal(t, tt.want, pathTemplate(tt.path))
+		})
+	}
+}
+
+// decodeLogRecords decodes the records written by slog.JSONHandler.
+func decodeLogRecords(tb testing.TB, s string) []map[string]interface{} {
+	tb.Helper()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		var record map[string]interface{}
+		requirez.NoError(tb, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+//nolint:funlen
+func TestClient_slogLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,info", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithQuota(100, time.Minute))
+		t.Cleanup(srv.Close)
+
+		var buf syncBuffer
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv),
+			ClientOptionWithSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))),
+		)...)
+		requirez.NoError(t, err)
+
+		created, err := client.CreateWebArenaIndigoV1VmSSHKey(ctx, &CreateWebArenaIndigoV1VmSSHKeyRequest{SshName: "test", SshKey: "ssh-ed25519 AAAA"})
+		requirez.NoError(t, err)
+		_, err = client.RetrieveWebArenaIndigoV1VmSSHKey(ctx, created.SshKey.Id)
+		requirez.NoError(t, err)
+
+		records := decodeLogRecords(t, buf.String())
+		requirez.Equal(t, 3, len(records))
+
+		// accesstokens, sshkey, sshkey/{id}
+		record := records[2]
+		requirez.Equal(t, "INFO", record["level"])
+		requirez.Equal(t, "indigo: request", record["msg"])
+		requirez.Equal(t, http.MethodGet, record["method"])
+		requirez.Equal(t, PathWebArenaIndigoV1VmSSHKey+"/{id}", record["path"])
+		requirez.Equal(t, float64(http.StatusOK), record["status"])
+		requirez.Equal(t, float64(1), record["attempt"])
+		_, hasLatency := record["latency"]
+		requirez.True(t, hasLatency)
+		requirez.StringHasPrefix(t, record["request_id"].(string), "00000000-0000-4000-8000-")
+		quota := record["quota"].(map[string]interface{})
+		requirez.Equal(t, "100", quota["allowed"])
+		requirez.Equal(t, "97", quota["available"])
+		requirez.NotEqual(t, "", quota["reset"])
+		requirez.Nil(t, record["request_body"])
+		requirez.Nil(t, record["response_body"])
+	})
+
+	t.Run("success,debug", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		var buf syncBuffer
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv),
+			ClientOptionWithSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		)...)
+		requirez.NoError(t, err)
+
+		_, err = client.CreateWebArenaIndigoV1VmSSHKey(ctx, &CreateWebArenaIndigoV1VmSSHKeyRequest{SshName: "test", SshKey: "ssh-ed25519 AAAA"})
+		requirez.NoError(t, err)
+
+		records := decodeLogRecords(t, buf.String())
+		requirez.Equal(t, 4, len(records))
+
+		// NOTE: The bodies are in a separate LevelDebug record that follows the record of the attempt.
+		for _, i := range []int{0, 2} {
+			requirez.Equal(t, "INFO", records[i]["level"])
+			requirez.Nil(t, records[i]["request_body"])
+			requirez.Nil(t, records[i]["response_body"])
+			requirez.Equal(t, "DEBUG", records[i+1]["level"])
+			requirez.Equal(t, "indigo: request body", records[i+1]["msg"])
+			for _, key := range []string{"method", "path", "attempt", "request_id"} {
+				requirez.Equal(t, records[i][key], records[i+1][key])
+			}
+		}
+		requirez.StringContains(t, records[1]["request_body"].(string), `"clientSecret":"[REDACTED]"`)
+		requirez.StringContains(t, records[1]["response_body"].(string), `"accessToken":"[REDACTED]"`)
+		requirez.False(t, strings.Contains(buf.String(), srv.ClientSecret()))
+		requirez.StringContains(t, records[3]["request_body"].(string), `"sshName":"test"`)
+		requirez.StringContains(t, records[3]["response_body"].(string), `"success":true`)
+	})
+
+	t.Run("success,retry", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		var calls int
+		stub := newStubServer(t, srv, func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path != PathWebArenaIndigoV1VmSSHKey {
+				return false
+			}
+			calls++
+			if calls == 1 {
+				writeStubError(w, http.StatusTooManyRequests, "Too Many Request.")
+				return true
+			}
+			return false
+		})
+
+		var buf syncBuffer
+		ctx := context.Background()
+		policy := DefaultRetryPolicy()
+		policy.InitialInterval = time.Millisecond
+		client, err := NewClient(ctx, append(testServerClientOptions(srv),
+			ClientOptionWithEndpoint(stub.URL),
+			ClientOptionWithRetryPolicy(policy),
+			ClientOptionWithSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+		)...)
+		requirez.NoError(t, err)
+
+		_, err = client.GetWebArenaIndigoV1VmSSHKey(ctx)
+		requirez.NoError(t, err)
+
+		records := decodeLogRecords(t, buf.String())
+		requirez.Equal(t, 3, len(records))
+		requirez.Equal(t, "WARN", records[1]["level"])
+		requirez.Equal(t, float64(1), records[1]["attempt"])
+		requirez.Equal(t, float64(http.StatusTooManyRequests), records[1]["status"])
+		requirez.Equal(t, ErrAPIReturnsTooManyRequest.Error(), records[1]["error"])
+		requirez.Equal(t, "429", records[1]["error_code"])
+		// NOTE: The response body is not logged at LevelWarn.
+		requirez.False(t, strings.Contains(buf.String(), "developerMessage"))
+		requirez.Equal(t, "INFO", records[2]["level"])
+		requirez.Equal(t, float64(2), records[2]["attempt"])
+	})
+}
@@ -0,0 +1,91 @@
+package indigo
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// flexInt64 is an int64 that the API returns either as a JSON number or as a JSON string that contains a number,
+// e.g. vps_kind is 10 in the instance list and "10" in the created instance. It is encoded as a JSON number.
+//
+// The structs keep such fields as int64, and decode them through flexInt64 in their UnmarshalJSON.
+type flexInt64 int64
+
+// UnmarshalJSON implements json.Unmarshaler. It decodes null and "" as 0.
+func (i *flexInt64) UnmarshalJSON(b []byte) error {
+	v, err := parseFlexInt64(b)
+	if err != nil {
+		return errorz.Errorf("parseFlexInt64: %w", err)
+	}
+	*i = flexInt64(v)
+	return nil
+}
+
+// quotedInt64 is a flexInt64 that is encoded as a JSON string, for the endpoints that require numbers as strings,
+// e.g. "instances":["6","5"] of PostWebArenaIndigoV1NwCreateFirewall.
+type quotedInt64 int64
+
+// UnmarshalJSON implements json.Unmarshaler. It decodes null and "" as 0.
+func (i *quotedInt64) UnmarshalJSON(b []byte) error {
+	v, err := parseFlexInt64(b)
+	if err != nil {
+		return errorz.Errorf("parseFlexInt64: %w", err)
+	}
+	*i = quotedInt64(v)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (i quotedInt64) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatInt(int64(i), 10))), nil
+}
+
+func parseFlexInt64(b []byte) (int64, error) {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return 0, nil
+	}
+
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return 0, errorz.Errorf("json.Unmarshal: %w", err)
+		}
+		if s == "" {
+			return 0, nil
+		}
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errorz.Errorf("strconv.ParseInt: number=%q: %w", s, err)
+	}
+	return v, nil
+}
+
+// quoteInt64s returns ids as quotedInt64s. It returns nil for nil, so that nil is still encoded as null.
+func quoteInt64s(ids []int64) []quotedInt64 {
+	if ids == nil {
+		return nil
+	}
+	quoted := make([]quotedInt64, len(ids))
+	for i, id := range ids {
+		quoted[i] = quotedInt64(id)
+	}
+	return quoted
+}
+
+// unquoteInt64s is the inverse of quoteInt64s.
+func unquoteInt64s(quoted []quotedInt64) []int64 {
+	if quoted == nil {
+		return nil
+	}
+	ids := make([]int64, len(quoted))
+	for i, id := range quoted {
+		ids[i] = int64(id)
+	}
+	return ids
+}
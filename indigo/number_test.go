@@ -0,0 +1,105 @@
+package indigo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+)
+
+func TestFlexInt64_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		json string
+		want int64
+	}{
+		{name: "success,number", json: `10`, want: 10},
+		{name: "success,string", json: `"10"`, want: 10},
+		{name: "success,negative", json: `"-1"`, want: -1},
+		{name: "success,null", json: `null`},
+		{name: "success,empty", json: `""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := flexInt64(42)
+			requirez.NoError(t, json.Unmarshal([]byte(tt.json), &got))
+			requirez.Equal(t, tt.want, int64(got))
+		})
+	}
+
+	t.Run("failure,invalid", func(t *testing.T) {
+		t.Parallel()
+
+		var got flexInt64
+		requirez.Error(t, json.Unmarshal([]byte(`"ten"`), &got))
+		requirez.Error(t, json.Unmarshal([]byte(`1.5`), &got))
+	})
+}
+
+func TestNumericFields(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		var snapshots GetWebArenaIndigoV1DiskSnapshotListResponse
+		requirez.NoError(t, json.Unmarshal([]byte(`[{"id": 3, "user_id": "134", "size": "2000", "status": "created"}, {"id": 8, "user_id": 134, "size": 2000}]`), &snapshots))
+		requirez.Equal(t, 2, len(snapshots))
+		for _, s := range snapshots {
+			requirez.Equal(t, int64(134), s.UserID)
+			requirez.Equal(t, int64(2000), s.Size)
+		}
+		requirez.Equal(t, "created", snapshots[0].Status)
+	})
+
+	t.Run("success,instance", func(t *testing.T) {
+		t.Parallel()
+
+		var instances GetWebArenaIndigoV1VmGetInstanceListResponse
+		requirez.NoError(t, json.Unmarshal([]byte(`[{"id": 1, "vps_kind": 10, "status": "running"}, {"id": 2, "vps_kind": "10"}]`), &instances))
+		requirez.Equal(t, int64(10), instances[0].VpsKind)
+		requirez.Equal(t, int64(10), instances[1].VpsKind)
+		requirez.Equal(t, InstanceStatusRunning, instances[0].Status)
+	})
+
+	t.Run("success,accessToken", func(t *testing.T) {
+		t.Parallel()
+
+		var resp PostOAuthV1AccessTokensResponse
+		requirez.NoError(t, json.Unmarshal([]byte(`{"accessToken": "token", "expiresIn": "3599"}`), &resp))
+		requirez.Equal(t, int64(3599), resp.ExpiresIn)
+		requirez.Equal(t, "token", resp.AccessToken)
+	})
+
+	t.Run("success,requests", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tt := range []struct {
+			req  any
+			want string
+		}{
+			{req: &PostWebArenaIndigoV1VmInstanceStatusUpdateRequest{InstanceID: 16, Status: InstanceActionStop}, want: `{"instanceId":"16","status":"stop"}`},
+			{req: &PostWebArenaIndigoV1DiskTakeSnapshotRequest{Name: "Example", InstanceID: 12}, want: `{"name":"Example","instanceid":12,"slotnum":"0"}`},
+			{req: &PostWebArenaIndigoV1DiskRestoreSnapshotRequest{InstanceID: 12, SnapshotID: 11}, want: `{"instanceid":12,"snapshotid":"11"}`},
+			{req: &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "fw", Instances: []int64{6, 5}}, want: `{"name":"fw","inbound":null,"outbound":null,"instances":["6","5"]}`},
+			{req: &UpdateWebArenaIndigoV1NwFirewallRequest{TemplateID: 1, Instances: []int64{}}, want: `{"templateid":1,"name":"","inbound":null,"outbound":null,"instances":[]}`},
+		} {
+			b, err := json.Marshal(tt.req)
+			requirez.NoError(t, err)
+			requirez.Equal(t, tt.want, string(b))
+		}
+	})
+
+	t.Run("success,firewallRoundTrip", func(t *testing.T) {
+		t.Parallel()
+
+		var req PostWebArenaIndigoV1NwCreateFirewallRequest
+		requirez.NoError(t, json.Unmarshal([]byte(`{"name":"fw","instances":["6",5]}`), &req))
+		requirez.Equal(t, "fw", req.Name)
+		requirez.Equal(t, []int64{6, 5}, req.Instances)
+	})
+}
@@ -2,12 +2,184 @@ package indigo
 
 import (
 	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/hakadoriya/webarena-go/util"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// Attribute keys of the Indigo resource IDs set to the span of each API call.
+const (
+	attributeKeyInstanceID    = attribute.Key("indigo.instance.id")
+	attributeKeyInstanceName  = attribute.Key("indigo.instance.name")
+	attributeKeyInstanceCount = attribute.Key("indigo.instance.count")
+	attributeKeyCacheHit      = attribute.Key("indigo.cache.hit")
+	attributeKeyFirewallID    = attribute.Key("indigo.firewall.id")
+	attributeKeyFirewallName  = attribute.Key("indigo.firewall.name")
+	attributeKeyFirewallCount = attribute.Key("indigo.firewall.count")
+	attributeKeySnapshotID    = attribute.Key("indigo.snapshot.id")
+	attributeKeySSHKeyID      = attribute.Key("indigo.sshkey.id")
+	attributeKeyAPIKeyID      = attribute.Key("indigo.apikey.id")
+)
+
+// Metric names exported through the meter of the client.
+const (
+	metricNameRequests        = "indigo.client.requests"
+	metricNameRequestDuration = "http.client.request.duration"
+	metricNameTooManyRequests = "indigo.client.too_many_requests"
+	metricNameQuotaAvailable  = "indigo.client.quota.available"
 )
 
-func start(ctx context.Context, opts ...trace.SpanStartOption) (context.Context, trace.Span) { //nolint:ireturn,unparam
-	return otel.GetTracerProvider().Tracer(pkgPath).Start(ctx, util.FuncName(1), opts...) //nolint:spancheck
+type tracerProviderOption struct{ tracerProvider trace.TracerProvider }
+
+func (o *tracerProviderOption) apply(c *Client) { c.tracerProvider = o.tracerProvider }
+
+// ClientOptionWithTracerProvider sets the TracerProvider of the client. The default is the global TracerProvider.
+//
+// The client creates a span for each API call, a child span of kind client for each attempt with the HTTP semantic convention attributes,
+// and a child span of the attempt for each wait of the rate limiter.
+func ClientOptionWithTracerProvider(tracerProvider trace.TracerProvider) ClientOption { //nolint:ireturn
+	return &tracerProviderOption{tracerProvider: tracerProvider}
+}
+
+type meterProviderOption struct{ meterProvider metric.MeterProvider }
+
+func (o *meterProviderOption) apply(c *Client) { c.meterProvider = o.meterProvider }
+
+// ClientOptionWithMeterProvider sets the MeterProvider of the client. The default is the global MeterProvider.
+//
+// The client exports the following metrics:
+//
+//   - indigo.client.requests: the number of attempts.
+//   - http.client.request.duration: the latency of each attempt in seconds.
+//   - indigo.client.too_many_requests: the number of 429 Too Many Requests responses.
+//   - indigo.client.quota.available: the last X-Quota-Available response header.
+func ClientOptionWithMeterProvider(meterProvider metric.MeterProvider) ClientOption { //nolint:ireturn
+	return &meterProviderOption{meterProvider: meterProvider}
+}
+
+type telemetry struct {
+	tracer          trace.Tracer
+	requests        metric.Int64Counter
+	requestDuration metric.Float64Histogram
+	tooManyRequests metric.Int64Counter
+	quotaAvailable  metric.Int64Gauge
+}
+
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*telemetry, error) {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+
+	meter := meterProvider.Meter(pkgPath)
+	t := &telemetry{tracer: tracerProvider.Tracer(pkgPath)}
+
+	var err error
+	if t.requests, err = meter.Int64Counter(metricNameRequests, metric.WithUnit("{request}"), metric.WithDescription("The number of attempts of the API requests.")); err != nil {
+		return nil, errorz.Errorf("meter.Int64Counter: %w", err)
+	}
+	if t.requestDuration, err = meter.Float64Histogram(metricNameRequestDuration, metric.WithUnit("s"), metric.WithDescription("The duration of the attempts of the API requests.")); err != nil {
+		return nil, errorz.Errorf("meter.Float64Histogram: %w", err)
+	}
+	if t.tooManyRequests, err = meter.Int64Counter(metricNameTooManyRequests, metric.WithUnit("{response}"), metric.WithDescription("The number of 429 Too Many Requests responses.")); err != nil {
+		return nil, errorz.Errorf("meter.Int64Counter: %w", err)
+	}
+	if t.quotaAvailable, err = meter.Int64Gauge(metricNameQuotaAvailable, metric.WithUnit("{request}"), metric.WithDescription("The remaining quota reported by the X-Quota-Available response header.")); err != nil {
+		return nil, errorz.Errorf("meter.Int64Gauge: %w", err)
+	}
+
+	return t, nil
+}
+
+// start starts the span of an API call named after the caller.
+func (c *Client) start(ctx context.Context, opts ...trace.SpanStartOption) (context.Context, trace.Span) { //nolint:ireturn
+	return c.telemetry.tracer.Start(ctx, util.FuncName(1), opts...) //nolint:spancheck
+}
+
+// recordError records err to span and sets the status of span to error.
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// startAttempt starts the client span of an attempt of req, following the HTTP semantic conventions.
+func (c *Client) startAttempt(req *http.Request, attempt int) (context.Context, trace.Span) { //nolint:ireturn
+	template := pathTemplate(req.URL.Path)
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLFull(req.URL.String()),
+		semconv.URLTemplate(template),
+		semconv.ServerAddress(req.URL.Hostname()),
+	}
+	if port, err := strconv.Atoi(req.URL.Port()); err == nil {
+		attrs = append(attrs, semconv.ServerPort(port))
+	} else if req.URL.Scheme == "https" {
+		attrs = append(attrs, semconv.ServerPort(443)) //nolint:mnd
+	}
+	if attempt > 1 {
+		attrs = append(attrs, semconv.HTTPRequestResendCount(attempt-1))
+	}
+
+	return c.telemetry.tracer.Start(req.Context(), req.Method+" "+template, //nolint:spancheck
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endAttempt records the result of an attempt to span and the metrics, and ends span.
+func (c *Client) endAttempt(ctx context.Context, span trace.Span, req *http.Request, resp *http.Response, latency time.Duration, err error) {
+	defer span.End()
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLTemplate(pathTemplate(req.URL.Path)),
+	}
+	if resp != nil {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(resp.StatusCode))
+		if resp.StatusCode == http.StatusTooManyRequests {
+			c.telemetry.tooManyRequests.Add(ctx, 1, metric.WithAttributes(attrs...))
+		}
+		if available, err := strconv.ParseInt(resp.Header.Get("X-Quota-Available"), 10, 64); err == nil {
+			c.telemetry.quotaAvailable.Record(ctx, available)
+		}
+	}
+	if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(errorType(resp, err)))
+		recordError(span, err)
+	}
+	span.SetAttributes(attrs...)
+
+	c.telemetry.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+	if latency > 0 {
+		c.telemetry.requestDuration.Record(ctx, latency.Seconds(), metric.WithAttributes(attrs...))
+	}
+}
+
+// errorType returns the value of the error.type attribute: the status code if the API responded, otherwise the kind of the error.
+func errorType(resp *http.Response, err error) string {
+	var netErr net.Error
+	switch {
+	case resp != nil:
+		return strconv.Itoa(resp.StatusCode)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "context"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "_OTHER"
+	}
 }
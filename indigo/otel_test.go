@@ -0,0 +1,185 @@
+package indigo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func findMetric(rm *metricdata.ResourceMetrics, name string) (metricdata.Metrics, bool) {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+	return metricdata.Metrics{}, false
+}
+
+//nolint:funlen
+func TestClient_telemetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithQuota(100, time.Minute))
+		t.Cleanup(srv.Close)
+
+		var calls int
+		stub := newStubServer(t, srv, func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path != PathWebArenaIndigoV1NwGetTemplate+"/1" {
+				return false
+			}
+			calls++
+			if calls == 1 {
+				writeStubError(w, http.StatusTooManyRequests, "Too Many Request.")
+				return true
+			}
+			return false
+		})
+
+		recorder := tracetest.NewSpanRecorder()
+		reader := sdkmetric.NewManualReader()
+		policy := DefaultRetryPolicy()
+		policy.InitialInterval = time.Millisecond
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv),
+			ClientOptionWithEndpoint(stub.URL),
+			ClientOptionWithRetryPolicy(policy),
+			ClientOptionWithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+			ClientOptionWithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		)...)
+		requirez.NoError(t, err)
+
+		created, err := client.PostWebArenaIndigoV1NwCreateFirewall(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:      "Example",
+			Inbound:   []WebArenaIndigoV1NwFirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}},
+			Outbound:  []WebArenaIndigoV1NwFirewallRule{},
+			Instances: []int64{},
+		})
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(1), created.FirewallID)
+
+		_, err = client.GetWebArenaIndigoV1NwGetTemplate(ctx, created.FirewallID)
+		requirez.NoError(t, err)
+		_, err = client.DeleteWebArenaIndigoV1NwDeleteFirewall(ctx, 999)
+		requirez.ErrorIs(t, err, ErrUnexpectedStatusCode)
+
+		var apiSpan, deleteSpan sdktrace.ReadOnlySpan
+		var attemptSpans, waitSpans []sdktrace.ReadOnlySpan
+		for _, span := range recorder.Ended() {
+			switch span.Name() {
+			case "indigo.(*Client).GetWebArenaIndigoV1NwGetTemplate":
+				apiSpan = span
+			case "indigo.(*Client).DeleteWebArenaIndigoV1NwDeleteFirewall":
+				deleteSpan = span
+			case http.MethodGet + " " + PathWebArenaIndigoV1NwGetTemplate + "/{id}":
+				attemptSpans = append(attemptSpans, span)
+			case "indigo.rateLimiter.Wait":
+				waitSpans = append(waitSpans, span)
+			}
+		}
+
+		// API span
+		requirez.NotNil(t, apiSpan)
+		v, ok := spanAttribute(apiSpan, attributeKeyFirewallID)
+		requirez.True(t, ok)
+		requirez.Equal(t, int64(1), v.AsInt64())
+		requirez.Equal(t, codes.Unset, apiSpan.Status().Code)
+
+		// attempt spans
+		requirez.Equal(t, 2, len(attemptSpans))
+		for i, span := range attemptSpans {
+			requirez.Equal(t, apiSpan.SpanContext().SpanID(), span.Parent().SpanID())
+			requirez.Equal(t, trace.SpanKindClient, span.SpanKind())
+			v, ok := spanAttribute(span, "http.request.method")
+			requirez.True(t, ok)
+			requirez.Equal(t, http.MethodGet, v.AsString())
+			v, ok = spanAttribute(span, "url.template")
+			requirez.True(t, ok)
+			requirez.Equal(t, PathWebArenaIndigoV1NwGetTemplate+"/{id}", v.AsString())
+			_, ok = spanAttribute(span, "server.address")
+			requirez.True(t, ok)
+			if i == 0 {
+				v, ok = spanAttribute(span, "http.response.status_code")
+				requirez.True(t, ok)
+				requirez.Equal(t, int64(http.StatusTooManyRequests), v.AsInt64())
+				requirez.Equal(t, codes.Error, span.Status().Code)
+			} else {
+				v, ok = spanAttribute(span, "http.request.resend_count")
+				requirez.True(t, ok)
+				requirez.Equal(t, int64(1), v.AsInt64())
+				requirez.Equal(t, codes.Unset, span.Status().Code)
+			}
+		}
+
+		// rate limiter wait spans are the children of the attempt spans
+		parents := map[trace.SpanID]bool{}
+		for _, span := range waitSpans {
+			parents[span.Parent().SpanID()] = true
+		}
+		for _, span := range attemptSpans {
+			requirez.True(t, parents[span.SpanContext().SpanID()])
+		}
+
+		// error recording
+		requirez.NotNil(t, deleteSpan)
+		requirez.Equal(t, codes.Error, deleteSpan.Status().Code)
+		requirez.True(t, len(deleteSpan.Events()) > 0)
+		requirez.Equal(t, "exception", deleteSpan.Events()[0].Name)
+
+		// metrics
+		var rm metricdata.ResourceMetrics
+		requirez.NoError(t, reader.Collect(ctx, &rm))
+
+		m, ok := findMetric(&rm, metricNameRequests)
+		requirez.True(t, ok)
+		var requests int64
+		for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			requests += dp.Value
+		}
+		// accesstokens, createfirewall, gettemplate x 2, deletefirewall
+		requirez.Equal(t, int64(5), requests)
+
+		m, ok = findMetric(&rm, metricNameRequestDuration)
+		requirez.True(t, ok)
+		var durations uint64
+		for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+			durations += dp.Count
+		}
+		requirez.Equal(t, uint64(5), durations)
+
+		m, ok = findMetric(&rm, metricNameTooManyRequests)
+		requirez.True(t, ok)
+		requirez.Equal(t, int64(1), m.Data.(metricdata.Sum[int64]).DataPoints[0].Value)
+
+		m, ok = findMetric(&rm, metricNameQuotaAvailable)
+		requirez.True(t, ok)
+		requirez.Equal(t, int64(100-4), m.Data.(metricdata.Gauge[int64]).DataPoints[0].Value)
+	})
+}
@@ -0,0 +1,132 @@
+package indigo
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type rateLimiter interface {
+	// Wait blocks until the next request is allowed to be sent.
+	Wait(ctx context.Context) error
+}
+
+// quotaObserver is implemented by a rateLimiter that adapts to the quota headers of each response.
+type quotaObserver interface {
+	observeQuota(header http.Header)
+}
+
+// quotaRateLimiter is a rateLimiter driven by the quota headers that the API sends on every response.
+//
+// Response Headers Example:
+//
+//	X-Quota-Allowed: 6
+//	X-Quota-Available: 0
+//	X-Quota-Reset: 1715521320000
+//
+// It lets requests through in a burst while X-Quota-Available is greater than 0,
+// and once the quota reaches 0, it blocks until X-Quota-Reset (epoch milliseconds).
+// Until the first response is observed, the quota is unknown and requests are not limited.
+type quotaRateLimiter struct {
+	mu  sync.Mutex
+	now func() time.Time
+	// allowed is X-Quota-Allowed, or -1 if unknown.
+	allowed int
+	// available is X-Quota-Available minus the requests sent since it was observed, or -1 if unknown.
+	available int
+	// resetAt is X-Quota-Reset, or zero if unknown.
+	resetAt time.Time
+}
+
+func newQuotaRateLimiter() *quotaRateLimiter {
+	return &quotaRateLimiter{
+		now:       time.Now,
+		allowed:   -1,
+		available: -1,
+	}
+}
+
+func (l *quotaRateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if !l.resetAt.IsZero() && !l.now().Before(l.resetAt) {
+			// NOTE: The quota has been reset. Restore it to X-Quota-Allowed until the next response tells the truth.
+			l.available = l.allowed
+			l.resetAt = time.Time{}
+		}
+		if l.available != 0 || l.resetAt.IsZero() {
+			if l.available > 0 {
+				l.available--
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		wait := l.resetAt.Sub(l.now())
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return context.Cause(ctx)
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *quotaRateLimiter) observeQuota(header http.Header) {
+	q, ok := parseQuota(header)
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if q.allowed >= 0 {
+		l.allowed = q.allowed
+	}
+	l.available = q.available
+	l.resetAt = q.resetAt
+}
+
+// quota is the rate limit quota reported by the headers of a response.
+type quota struct {
+	// allowed is X-Quota-Allowed, or -1 if the header is missing.
+	allowed   int
+	available int
+	resetAt   time.Time
+}
+
+// parseQuota parses the quota headers. It reports false if X-Quota-Available or X-Quota-Reset is missing.
+func parseQuota(header http.Header) (*quota, bool) {
+	available, err := strconv.Atoi(header.Get("X-Quota-Available"))
+	if err != nil {
+		return nil, false
+	}
+	resetAtUnixMilli, err := strconv.ParseInt(header.Get("X-Quota-Reset"), 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	q := &quota{allowed: -1, available: available, resetAt: time.UnixMilli(resetAtUnixMilli)}
+	if allowed, err := strconv.Atoi(header.Get("X-Quota-Allowed")); err == nil {
+		q.allowed = allowed
+	}
+	return q, true
+}
+
+// pace returns the interval that spreads the available quota evenly until the quota is reset,
+// so that a long-running poll leaves the quota for the other requests. It returns 0 if the quota has already been reset.
+func (q *quota) pace(now time.Time) time.Duration {
+	untilReset := q.resetAt.Sub(now)
+	if untilReset <= 0 {
+		return 0
+	}
+	if q.available <= 0 {
+		return untilReset
+	}
+	return untilReset / time.Duration(q.available)
+}
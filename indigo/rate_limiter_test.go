@@ -0,0 +1,143 @@
+package indigo
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func quotaHeader(allowed, available int, resetAt time.Time) http.Header {
+	h := http.Header{}
+	h.Set("X-Quota-Allowed", strconv.Itoa(allowed))
+	h.Set("X-Quota-Available", strconv.Itoa(available))
+	h.Set("X-Quota-Reset", strconv.FormatInt(resetAt.UnixMilli(), 10))
+	return h
+}
+
+type statusCountingTransport struct {
+	tooManyRequests atomic.Int64
+}
+
+func (t *statusCountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		t.tooManyRequests.Add(1)
+	}
+	return resp, err //nolint:wrapcheck
+}
+
+func TestQuotaRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,unknownQuota", func(t *testing.T) {
+		t.Parallel()
+
+		l := newQuotaRateLimiter()
+		for range 10 {
+			requirez.NoError(t, l.Wait(context.Background()))
+		}
+	})
+
+	t.Run("success,burstThenWaitUntilReset", func(t *testing.T) {
+		t.Parallel()
+
+		const resetAfter = 200 * time.Millisecond
+		l := newQuotaRateLimiter()
+		l.observeQuota(quotaHeader(6, 2, time.Now().Add(resetAfter)))
+
+		begin := time.Now()
+		requirez.NoError(t, l.Wait(context.Background()))
+		requirez.NoError(t, l.Wait(context.Background()))
+		requirez.True(t, time.Since(begin) < resetAfter/2)
+
+		requirez.NoError(t, l.Wait(context.Background()))
+		requirez.True(t, time.Since(begin) >= resetAfter-10*time.Millisecond)
+	})
+
+	t.Run("failure,contextCanceled", func(t *testing.T) {
+		t.Parallel()
+
+		l := newQuotaRateLimiter()
+		l.observeQuota(quotaHeader(6, 0, time.Now().Add(time.Hour)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		requirez.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("success,ignoreInvalidHeaders", func(t *testing.T) {
+		t.Parallel()
+
+		l := newQuotaRateLimiter()
+		l.observeQuota(http.Header{"X-Quota-Available": []string{"x"}})
+		requirez.Equal(t, -1, l.available)
+	})
+
+	t.Run("success,client", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			allowed = 3
+			window  = 300 * time.Millisecond
+		)
+		srv := indigotest.NewServer(indigotest.ServerOptionWithQuota(allowed, window))
+		t.Cleanup(srv.Close)
+
+		transport := &statusCountingTransport{}
+		ctx := context.Background()
+		client, err := NewClient(ctx,
+			ClientOptionWithEndpoint(srv.URL),
+			ClientOptionWithClientID(srv.ClientID()),
+			ClientOptionWithClientSecret(srv.ClientSecret()),
+			ClientOptionWithHTTPClient(&http.Client{Transport: transport}),
+			ClientOptionWithQuotaRateLimiter(),
+		)
+		requirez.NoError(t, err)
+
+		begin := time.Now()
+		for range 2 * allowed {
+			_, err := client.GetWebArenaIndigoV1VmSSHKey(ctx)
+			requirez.NoError(t, err)
+		}
+		requirez.Equal(t, int64(0), transport.tooManyRequests.Load())
+		requirez.True(t, time.Since(begin) < 3*window)
+	})
+}
+
+func TestQuota_pace(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 12, 13, 41, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{name: "success,spread", header: quotaHeader(6, 4, now.Add(40*time.Second)), want: 10 * time.Second},
+		{name: "success,exhausted", header: quotaHeader(6, 0, now.Add(40*time.Second)), want: 40 * time.Second},
+		{name: "success,reset", header: quotaHeader(6, 0, now.Add(-time.Second)), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q, ok := parseQuota(tt.header)
+			requirez.True(t, ok)
+			requirez.Equal(t, tt.want, q.pace(now))
+		})
+	}
+
+	t.Run("failure,missingHeaders", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := parseQuota(http.Header{})
+		requirez.False(t, ok)
+	})
+}
@@ -0,0 +1,126 @@
+package indigo
+
+import (
+	"bytes"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const defaultRedactedReplacement = "[REDACTED]"
+
+// Redactor masks credentials and tokens in the request and response dumps written to the debug log.
+//
+// It replaces the values of the headers in Headers, and the values of the JSON fields in JSONFields at any depth of the body.
+// Names are matched case-insensitively. The JSON fields are matched textually, so the bodies that are not valid JSON,
+// such as the response of gettemplate, are also redacted.
+//
+// A Redactor must not be modified after it is passed to ClientOptionWithRedactor.
+type Redactor struct {
+	// Headers is the names of the headers whose values are redacted.
+	Headers []string
+	// JSONFields is the names of the JSON fields whose values are redacted.
+	JSONFields []string
+	// Replacement replaces the redacted values. If empty, "[REDACTED]" is used.
+	Replacement string
+
+	once       sync.Once
+	headers    []string
+	jsonFields *regexp.Regexp
+}
+
+// DefaultRedactor returns the Redactor that the client uses by default.
+// Callers can append their own names to the returned Redactor before passing it to ClientOptionWithRedactor.
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Headers: []string{
+			"Authorization",
+			"Proxy-Authorization",
+			"Cookie",
+			"Set-Cookie",
+		},
+		JSONFields: []string{
+			"clientSecret",
+			"accessToken",
+			"apiSecret",
+			"vnc_passwd",
+			"winPassword",
+		},
+	}
+}
+
+type redactorOption struct{ redactor *Redactor }
+
+func (o *redactorOption) apply(c *Client) { c.redactor = o.redactor }
+
+// ClientOptionWithRedactor replaces DefaultRedactor with redactor. Pass &Redactor{} to disable the redaction.
+func ClientOptionWithRedactor(redactor *Redactor) ClientOption { //nolint:ireturn
+	return &redactorOption{redactor: redactor}
+}
+
+func (r *Redactor) init() {
+	r.once.Do(func() {
+		for _, h := range r.Headers {
+			r.headers = append(r.headers, textproto.CanonicalMIMEHeaderKey(h))
+		}
+
+		if len(r.JSONFields) == 0 {
+			return
+		}
+		quoted := make([]string, 0, len(r.JSONFields))
+		for _, f := range r.JSONFields {
+			quoted = append(quoted, regexp.QuoteMeta(f))
+		}
+		// NOTE: Matches `"field": <value>` where <value> is a JSON string or a scalar. Objects and arrays are left as they are.
+		r.jsonFields = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"|[^\s,}\]]+)`)
+	})
+}
+
+func (r *Redactor) replacement() string {
+	if r.Replacement == "" {
+		return defaultRedactedReplacement
+	}
+	return r.Replacement
+}
+
+// Redact returns a copy of dump, which is the output of httputil.DumpRequest or httputil.DumpResponse, with the credentials masked.
+func (r *Redactor) Redact(dump []byte) []byte {
+	if r == nil || len(dump) == 0 {
+		return dump
+	}
+	r.init()
+
+	head, body, found := bytes.Cut(dump, []byte("\r\n\r\n"))
+	lines := bytes.Split(head, []byte("\r\n"))
+	// NOTE: The first line is the request line or the status line.
+	for i := 1; i < len(lines); i++ {
+		name, _, ok := bytes.Cut(lines[i], []byte(":"))
+		if ok && slices.Contains(r.headers, textproto.CanonicalMIMEHeaderKey(string(bytes.TrimSpace(name)))) {
+			lines[i] = []byte(string(name) + ": " + r.replacement())
+		}
+	}
+
+	out := bytes.Join(lines, []byte("\r\n"))
+	if !found {
+		return out
+	}
+
+	return append(append(out, "\r\n\r\n"...), r.redactBody(body)...)
+}
+
+// redactBody returns body with the values of the JSON fields masked.
+func (r *Redactor) redactBody(body []byte) []byte {
+	if r == nil {
+		return body
+	}
+	r.init()
+
+	if r.jsonFields == nil {
+		return body
+	}
+
+	replacement := []byte(`${1}"` + strings.ReplaceAll(r.replacement(), "$", "$$") + `"`)
+	return r.jsonFields.ReplaceAll(body, replacement)
+}
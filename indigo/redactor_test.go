@@ -0,0 +1,125 @@
+package indigo
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		redactor *Redactor
+		dump     string
+		want     string
+	}{
+		{
+			name:     "success,header",
+			redactor: DefaultRedactor(),
+			dump:     "GET /webarenaIndigo/v1/vm/sshkey HTTP/1.1\r\nHost: api.customer.jp\r\nauthorization: Bearer 0123456789abcdef\r\n\r\n",
+			want:     "GET /webarenaIndigo/v1/vm/sshkey HTTP/1.1\r\nHost: api.customer.jp\r\nauthorization: [REDACTED]\r\n\r\n",
+		},
+		{
+			name:     "success,accessTokensRequest",
+			redactor: DefaultRedactor(),
+			dump:     "POST /oauth/v1/accesstokens HTTP/1.1\r\nHost: api.customer.jp\r\n\r\n" + `{"grantType":"client_credentials","clientId":"id","clientSecret":"se\"cret","code":""}`,
+			want:     "POST /oauth/v1/accesstokens HTTP/1.1\r\nHost: api.customer.jp\r\n\r\n" + `{"grantType":"client_credentials","clientId":"id","clientSecret":"[REDACTED]","code":""}`,
+		},
+		{
+			name:     "success,accessTokensResponse",
+			redactor: DefaultRedactor(),
+			dump:     "HTTP/1.1 201 Created\r\nContent-Type: application/json\r\n\r\n" + `{"accessToken": "0123456789abcdef", "tokenType": "BearerToken"}`,
+			want:     "HTTP/1.1 201 Created\r\nContent-Type: application/json\r\n\r\n" + `{"accessToken": "[REDACTED]", "tokenType": "BearerToken"}`,
+		},
+		{
+			name:     "success,nested",
+			redactor: DefaultRedactor(),
+			dump:     "HTTP/1.1 200 OK\r\n\r\n" + `{"success":true,"vms":{"id":1,"vnc_passwd":"fHTsl4EoLfMksYKW","VNC_PASSWD":null}}`,
+			want:     "HTTP/1.1 200 OK\r\n\r\n" + `{"success":true,"vms":{"id":1,"vnc_passwd":"[REDACTED]","VNC_PASSWD":"[REDACTED]"}}`,
+		},
+		{
+			name:     "success,customFieldAndReplacement",
+			redactor: &Redactor{Headers: []string{"X-Custom"}, JSONFields: []string{"sshKey"}, Replacement: "***"},
+			dump:     "POST /webarenaIndigo/v1/vm/sshkey HTTP/1.1\r\nX-Custom: value\r\nAuthorization: Bearer token\r\n\r\n" + `{"sshName":"test","sshKey":"ssh-ed25519 AAAA"}`,
+			want:     "POST /webarenaIndigo/v1/vm/sshkey HTTP/1.1\r\nX-Custom: ***\r\nAuthorization: Bearer token\r\n\r\n" + `{"sshName":"test","sshKey":"***"}`,
+		},
+		{
+			name:     "success,disabled",
+			redactor: &Redactor{},
+			dump:     "GET / HTTP/1.1\r\nAuthorization: Bearer token\r\n\r\n" + `{"accessToken":"token"}`,
+			want:     "GET / HTTP/1.1\r\nAuthorization: Bearer token\r\n\r\n" + `{"accessToken":"token"}`,
+		},
+		{
+			name:     "success,empty",
+			redactor: DefaultRedactor(),
+			dump:     "",
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			requirez.Equal(t, tt.want, string(tt.redactor.Redact([]byte(tt.dump))))
+		})
+	}
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p) //nolint:wrapcheck
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestClient_redactDebugLog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		var buf syncBuffer
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithDebugLog(log.New(&buf, "", 0)))...)
+		requirez.NoError(t, err)
+
+		accessToken, err := client.tokenSource.Token(ctx)
+		requirez.NoError(t, err)
+		apiKey, err := client.CreateWebArenaIndigoV1AuthCreateAPIKey(ctx)
+		requirez.NoError(t, err)
+		instance, err := client.PostWebArenaIndigoV1VmCreateInstance(ctx, &PostWebArenaIndigoV1VmCreateInstanceRequest{RegionID: 1, OsID: 1, InstancePlan: 1, InstanceName: "test-instance"})
+		requirez.NoError(t, err)
+		_, err = client.PostWebArenaIndigoV1VmCreateWindowsInstance(ctx, &PostWebArenaIndigoV1VmCreateWindowsInstanceRequest{WinPassword: "Test#2345jh", RegionID: 1, OsID: 10, InstancePlan: 11, InstanceName: "test-windows"})
+		requirez.NoError(t, err)
+
+		got := buf.String()
+		requirez.StringContains(t, got, "[REDACTED]")
+		for _, secret := range []string{srv.ClientSecret(), accessToken.AccessToken, apiKey.APISecret, instance.Vms.VncPasswd, "Test#2345jh"} {
+			requirez.True(t, secret != "")
+			requirez.False(t, strings.Contains(got, secret))
+		}
+	})
+}
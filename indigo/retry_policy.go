@@ -0,0 +1,184 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/hakadoriya/z.go/errorz"
+	"github.com/hakadoriya/z.go/retryz"
+)
+
+// RetryPolicy configures how the client retries a failed request.
+//
+// 429 Too Many Requests is retried for any request because the API has not processed the request.
+// The other failures are retried only for idempotent requests (GET, PUT, DELETE and issuing an access token) by default,
+// because the API may have processed the request before it failed. Set RetryNonIdempotent to retry POST requests such as createinstance as well.
+//
+// The interval before the next attempt is the larger of the backoff with jitter and the hint of the response,
+// which is Retry-After, or X-Quota-Reset for 429 Too Many Requests.
+//
+// Example:
+//
+//	policy := indigo.DefaultRetryPolicy()
+//	policy.MaxAttempts = 5
+//	policy.RetryableStatusCodes = append(policy.RetryableStatusCodes, http.StatusInternalServerError)
+//	policy.RetryNetworkErrors = true
+//	client, err := indigo.NewClient(ctx, indigo.ClientOptionWithRetryPolicy(policy))
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one. 0 means unlimited.
+	MaxAttempts int
+	// InitialInterval is the interval before the first retry, passed to Backoff.
+	InitialInterval time.Duration
+	// MaxInterval caps the interval calculated by Backoff.
+	MaxInterval time.Duration
+	// Backoff calculates the interval before each retry. If nil, retryz.DefaultBackoff is used, which doubles the interval for each retry.
+	Backoff retryz.Backoff
+	// Jitter randomizes the interval calculated by Backoff. If nil, retryz.DefaultJitter is used.
+	Jitter retryz.Jitter
+	// RetryableStatusCodes is the status codes to retry.
+	RetryableStatusCodes []int
+	// RetryNetworkErrors retries connection resets, unexpected EOFs and timeouts.
+	RetryNetworkErrors bool
+	// RetryNonIdempotent retries the failures other than 429 Too Many Requests for POST requests as well.
+	RetryNonIdempotent bool
+
+	now func() time.Time
+}
+
+// DefaultRetryPolicy returns the RetryPolicy that the client uses by default.
+// It retries 429 Too Many Requests, and 502 Bad Gateway, 503 Service Unavailable and 504 Gateway Timeout for idempotent requests,
+// without limit, with the interval from 1 second to 10 seconds. Set MaxAttempts or cancel ctx to give up on an outage.
+func DefaultRetryPolicy() *RetryPolicy {
+	const (
+		defaultInitialRetryInterval = 1 * time.Second
+		defaultMaxRetryInterval     = 10 * time.Second
+	)
+
+	return &RetryPolicy{
+		MaxAttempts:          0,
+		InitialInterval:      defaultInitialRetryInterval,
+		MaxInterval:          defaultMaxRetryInterval,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+type retryPolicyOption struct{ retryPolicy *RetryPolicy }
+
+func (o *retryPolicyOption) apply(c *Client) { c.retryPolicy = o.retryPolicy }
+
+// ClientOptionWithRetryPolicy replaces DefaultRetryPolicy with retryPolicy.
+// retryPolicy must not be modified after it is passed to the client.
+func ClientOptionWithRetryPolicy(retryPolicy *RetryPolicy) ClientOption { //nolint:ireturn
+	return &retryPolicyOption{retryPolicy: retryPolicy}
+}
+
+// idempotent reports whether req can be sent again even if the API may have processed it.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		// NOTE: Issuing an access token has no side effects other than the new access token.
+		return req.URL.Path == PathOAuthV1AccessTokens
+	default:
+		return false
+	}
+}
+
+// retryable reports whether err returned for req should be retried.
+func (p *RetryPolicy) retryable(req *http.Request, err error) bool {
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		if !slices.Contains(p.RetryableStatusCodes, apiErr.StatusCode) {
+			return false
+		}
+		return apiErr.StatusCode == http.StatusTooManyRequests || p.RetryNonIdempotent || idempotent(req)
+	case p.RetryNetworkErrors && isNetworkError(err):
+		return p.RetryNonIdempotent || idempotent(req)
+	default:
+		return false
+	}
+}
+
+// isNetworkError reports whether err is a connection reset, an unexpected EOF or a timeout.
+// The caller must check that the context of the request is not done, because it may also be reported as a timeout.
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// interval returns how long to wait before the attempt after the given failed attempt (1-origin).
+func (p *RetryPolicy) interval(attempt int, err error) time.Duration {
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = retryz.DefaultBackoff()
+	}
+	jitter := p.Jitter
+	if jitter == nil {
+		jitter = retryz.DefaultJitter()
+	}
+
+	d := backoff(p.InitialInterval, attempt-1)
+	if p.MaxInterval > 0 && (d > p.MaxInterval || d < 0) {
+		d = p.MaxInterval
+	}
+	d = max(jitter(d), 0)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		d = max(d, p.retryAfter(apiErr))
+	}
+
+	return d
+}
+
+// retryAfter returns the interval that the response asks the client to wait, or 0 if the response has no hint.
+func (p *RetryPolicy) retryAfter(apiErr *APIError) time.Duration {
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+
+	if v := apiErr.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now()), 0)
+		}
+	}
+
+	if apiErr.StatusCode == http.StatusTooManyRequests {
+		if resetAtUnixMilli, err := strconv.ParseInt(apiErr.Header.Get("X-Quota-Reset"), 10, 64); err == nil {
+			return max(time.UnixMilli(resetAtUnixMilli).Sub(now()), 0)
+		}
+	}
+
+	return 0
+}
+
+// wait blocks for the interval before the attempt after the given failed attempt, or until ctx is done.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	timer := time.NewTimer(p.interval(attempt, err))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errorz.Errorf("ctx.Done: %w", context.Cause(ctx))
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,230 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func TestRetryPolicy_retryable(t *testing.T) {
+	t.Parallel()
+
+	newReq := func(method, path string) *http.Request {
+		return &http.Request{Method: method, URL: &url.URL{Path: path}}
+	}
+	apiErr := func(statusCode int) error {
+		return fmt.Errorf("newAPIError: %w", &APIError{StatusCode: statusCode, err: ErrUnexpectedStatusCode})
+	}
+	policy := &RetryPolicy{
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		RetryNetworkErrors:   true,
+	}
+	nonIdempotentPolicy := *policy
+	nonIdempotentPolicy.RetryNonIdempotent = true
+
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		req    *http.Request
+		err    error
+		want   bool
+	}{
+		{"success,429,POST", policy, newReq(http.MethodPost, PathWebArenaIndigoV1VmCreateInstance), apiErr(http.StatusTooManyRequests), true},
+		{"success,503,GET", policy, newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), apiErr(http.StatusServiceUnavailable), true},
+		{"success,503,PUT", policy, newReq(http.MethodPut, PathWebArenaIndigoV1NwUpdateFirewall), apiErr(http.StatusServiceUnavailable), true},
+		{"success,503,POST,accessTokens", policy, newReq(http.MethodPost, PathOAuthV1AccessTokens), apiErr(http.StatusServiceUnavailable), true},
+		{"success,503,POST,nonIdempotent", policy, newReq(http.MethodPost, PathWebArenaIndigoV1VmCreateInstance), apiErr(http.StatusServiceUnavailable), false},
+		{"success,503,POST,retryNonIdempotent", &nonIdempotentPolicy, newReq(http.MethodPost, PathWebArenaIndigoV1VmCreateInstance), apiErr(http.StatusServiceUnavailable), true},
+		{"success,502,GET,notConfigured", policy, newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), apiErr(http.StatusBadGateway), false},
+		{"success,400,GET", policy, newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), apiErr(http.StatusBadRequest), false},
+		{"success,connectionReset,GET", policy, newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), fmt.Errorf("c.httpClient.Do: %w", syscall.ECONNRESET), true},
+		{"success,connectionReset,POST", policy, newReq(http.MethodPost, PathWebArenaIndigoV1VmCreateInstance), fmt.Errorf("c.httpClient.Do: %w", syscall.ECONNRESET), false},
+		{"success,connectionReset,disabled", DefaultRetryPolicy(), newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), fmt.Errorf("c.httpClient.Do: %w", syscall.ECONNRESET), false},
+		{"success,502,GET,default", DefaultRetryPolicy(), newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), apiErr(http.StatusBadGateway), true},
+		{"success,503,DELETE,default", DefaultRetryPolicy(), newReq(http.MethodDelete, PathWebArenaIndigoV1VmSSHKey+"/1"), apiErr(http.StatusServiceUnavailable), true},
+		{"success,504,GET,default", DefaultRetryPolicy(), newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), apiErr(http.StatusGatewayTimeout), true},
+		{"success,503,POST,default", DefaultRetryPolicy(), newReq(http.MethodPost, PathWebArenaIndigoV1VmCreateInstance), apiErr(http.StatusServiceUnavailable), false},
+		{"success,500,GET,default", DefaultRetryPolicy(), newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), apiErr(http.StatusInternalServerError), false},
+		{"success,otherError", policy, newReq(http.MethodGet, PathWebArenaIndigoV1VmGetInstanceList), errors.New("other"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			requirez.Equal(t, tt.want, tt.policy.retryable(tt.req, tt.err))
+		})
+	}
+}
+
+func TestRetryPolicy_interval(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 12, 13, 41, 52, 0, time.UTC)
+	policy := &RetryPolicy{
+		InitialInterval: time.Second,
+		MaxInterval:     10 * time.Second,
+		Jitter:          func(d time.Duration) time.Duration { return d },
+		now:             func() time.Time { return now },
+	}
+	apiErr := func(statusCode int, header http.Header) error {
+		return &APIError{StatusCode: statusCode, Header: header}
+	}
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		want    time.Duration
+	}{
+		{"success,backoff,1", 1, errors.New("other"), time.Second},
+		{"success,backoff,3", 3, errors.New("other"), 4 * time.Second},
+		{"success,backoff,maxInterval", 5, errors.New("other"), 10 * time.Second},
+		{"success,retryAfterSeconds", 1, apiErr(http.StatusServiceUnavailable, http.Header{"Retry-After": {"30"}}), 30 * time.Second},
+		{"success,retryAfterDate", 1, apiErr(http.StatusServiceUnavailable, http.Header{"Retry-After": {now.Add(20 * time.Second).Format(http.TimeFormat)}}), 20 * time.Second},
+		{"success,retryAfterShorterThanBackoff", 3, apiErr(http.StatusServiceUnavailable, http.Header{"Retry-After": {"1"}}), 4 * time.Second},
+		{"success,quotaReset", 1, apiErr(http.StatusTooManyRequests, http.Header{"X-Quota-Reset": {strconv.FormatInt(now.Add(15*time.Second).UnixMilli(), 10)}}), 15 * time.Second},
+		{"success,quotaResetIgnoredFor503", 1, apiErr(http.StatusServiceUnavailable, http.Header{"X-Quota-Reset": {strconv.FormatInt(now.Add(15*time.Second).UnixMilli(), 10)}}), time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			requirez.Equal(t, tt.want, policy.interval(tt.attempt, tt.err))
+		})
+	}
+}
+
+//nolint:funlen
+func TestClient_retryPolicy(t *testing.T) {
+	t.Parallel()
+
+	// newClientWithFailures returns a client whose first failures requests to path fail with fail, and a function that returns the number of requests to path.
+	newClientWithFailures := func(t *testing.T, path string, failures int64, fail func(w http.ResponseWriter), policy *RetryPolicy) (*Client, func() int64) {
+		t.Helper()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		var calls atomic.Int64
+		stub := newStubServer(t, srv, func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path == path && calls.Add(1) <= failures {
+				fail(w)
+				return true
+			}
+			return false
+		})
+
+		client, err := NewClient(context.Background(), append(testServerClientOptions(srv),
+			ClientOptionWithEndpoint(stub.URL),
+			ClientOptionWithRetryPolicy(policy),
+		)...)
+		requirez.NoError(t, err)
+
+		return client, calls.Load
+	}
+	serviceUnavailable := func(w http.ResponseWriter) {
+		writeStubError(w, http.StatusServiceUnavailable, "Service Unavailable")
+	}
+	newPolicy := func() *RetryPolicy {
+		policy := DefaultRetryPolicy()
+		policy.MaxAttempts = 3
+		policy.InitialInterval = time.Millisecond
+		policy.MaxInterval = 10 * time.Millisecond
+		policy.RetryNetworkErrors = true
+		return policy
+	}
+
+	t.Run("success,serviceUnavailable", func(t *testing.T) {
+		t.Parallel()
+
+		client, calls := newClientWithFailures(t, PathWebArenaIndigoV1VmSSHKey, 2, serviceUnavailable, newPolicy())
+
+		_, err := client.GetWebArenaIndigoV1VmSSHKey(context.Background())
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(3), calls())
+	})
+
+	t.Run("success,connectionReset", func(t *testing.T) {
+		t.Parallel()
+
+		client, calls := newClientWithFailures(t, PathWebArenaIndigoV1VmSSHKey, 1, func(w http.ResponseWriter) {
+			conn, _, err := http.NewResponseController(w).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+		}, newPolicy())
+
+		_, err := client.GetWebArenaIndigoV1VmSSHKey(context.Background())
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(2), calls())
+	})
+
+	t.Run("failure,maxAttemptsExceeded", func(t *testing.T) {
+		t.Parallel()
+
+		client, calls := newClientWithFailures(t, PathWebArenaIndigoV1VmSSHKey, 3, serviceUnavailable, newPolicy())
+
+		_, err := client.GetWebArenaIndigoV1VmSSHKey(context.Background())
+		requirez.ErrorIs(t, err, ErrMaxAttemptsExceeded)
+		requirez.ErrorIs(t, err, ErrUnexpectedStatusCode)
+		requirez.Equal(t, int64(3), calls())
+	})
+
+	t.Run("failure,nonIdempotent", func(t *testing.T) {
+		t.Parallel()
+
+		client, calls := newClientWithFailures(t, PathWebArenaIndigoV1VmCreateInstance, 1, serviceUnavailable, newPolicy())
+
+		_, err := client.PostWebArenaIndigoV1VmCreateInstance(context.Background(), &PostWebArenaIndigoV1VmCreateInstanceRequest{
+			RegionID:     1,
+			OsID:         1,
+			InstancePlan: 1,
+			InstanceName: "test-instance",
+		})
+		requirez.ErrorIs(t, err, ErrUnexpectedStatusCode)
+		requirez.Equal(t, int64(1), calls())
+	})
+
+	t.Run("success,retryNonIdempotent", func(t *testing.T) {
+		t.Parallel()
+
+		policy := newPolicy()
+		policy.RetryNonIdempotent = true
+		client, calls := newClientWithFailures(t, PathWebArenaIndigoV1VmCreateInstance, 1, serviceUnavailable, policy)
+
+		_, err := client.PostWebArenaIndigoV1VmCreateInstance(context.Background(), &PostWebArenaIndigoV1VmCreateInstanceRequest{
+			RegionID:     1,
+			OsID:         1,
+			InstancePlan: 1,
+			InstanceName: "test-instance",
+		})
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(2), calls())
+	})
+
+	t.Run("failure,contextCanceledWhileWaiting", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := newClientWithFailures(t, PathWebArenaIndigoV1VmSSHKey, 1, func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "60")
+			serviceUnavailable(w)
+		}, newPolicy())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := client.GetWebArenaIndigoV1VmSSHKey(ctx)
+		requirez.ErrorIs(t, err, context.DeadlineExceeded)
+		requirez.ErrorIs(t, err, ErrUnexpectedStatusCode)
+	})
+}
@@ -0,0 +1,211 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// The domain types of the services. They are aliases of the types of the methods named after the API paths,
+// so the values can be passed between the services and those methods as they are.
+type (
+	// Instance is an instance (VM).
+	Instance = WebArenaIndigoV1VmInstance
+	// Snapshot is a snapshot of the disk of an instance.
+	Snapshot = WebArenaIndigoV1DiskSnapshot
+	// SSHKey is an SSH public key registered to the account.
+	SSHKey = WebArenaIndigoV1VmSSHKey
+	// Firewall is a firewall, listed without its rules. Use FirewallService.Get to get the rules.
+	Firewall = WebArenaIndigoV1NwFirewall
+	// FirewallRule is an inbound or outbound rule of a firewall.
+	FirewallRule = WebArenaIndigoV1NwFirewallRule
+	// APIKey is an API key, listed without its secret.
+	APIKey = WebArenaIndigoV1AuthAPIKey
+	// APIKeyCredentials is a created API key and its secret. The secret is returned only when the key is created.
+	APIKeyCredentials = CreateWebArenaIndigoV1AuthCreateAPIKeyResponse
+	// InstanceType is a type of instances, e.g. "instance" for Linux and "winserver" for Windows.
+	InstanceType = WebArenaIndigoV1VmInstanceType
+	// Region is a region where instances of an instance type are available.
+	Region = WebArenaIndigoV1VmRegion
+	// OS is an OS image that instances of an instance type can be created with.
+	OS = WebArenaIndigoV1VmOS
+	// InstanceSpec is a plan of instances, e.g. "2CR2GB".
+	InstanceSpec = WebArenaIndigoV1VmInstanceSpec
+)
+
+// FirewallDirection is the direction of a rule in FirewallTemplate.
+type FirewallDirection string
+
+const (
+	FirewallDirectionInbound  FirewallDirection = "in"
+	FirewallDirectionOutbound FirewallDirection = "out"
+)
+
+// FirewallTemplate is a firewall with its rules.
+type FirewallTemplate struct {
+	ID       int64
+	Name     string
+	Inbound  []FirewallRule
+	Outbound []FirewallRule
+}
+
+// NewFirewallTemplate returns the firewall of id from the output of GetWebArenaIndigoV1NwGetTemplate,
+// grouping its rows, one per rule, by direction.
+// A firewall without rules has no rows, and its Name is left empty.
+//
+// It returns ErrUnknownFirewallDirection if a row is neither inbound nor outbound,
+// so that a rule of a direction this package does not know is not mistaken for an inbound or outbound one.
+func NewFirewallTemplate(id int64, rows []WebArenaIndigoV1NwGetTemplateFirewall) (*FirewallTemplate, error) {
+	template := &FirewallTemplate{ID: id}
+	for i, row := range rows {
+		template.Name = row.Name
+		rule := FirewallRule{Type: row.Type, Protocol: row.Protocol, Port: row.Port, Source: row.Source}
+		switch FirewallDirection(row.Direction) {
+		case FirewallDirectionInbound:
+			template.Inbound = append(template.Inbound, rule)
+		case FirewallDirectionOutbound:
+			template.Outbound = append(template.Outbound, rule)
+		default:
+			return nil, errorz.Errorf("firewallID=%d rows[%d].direction=%q: %w", id, i, row.Direction, ErrUnknownFirewallDirection)
+		}
+	}
+	return template, nil
+}
+
+// InstanceService operates instances.
+//
+// Start, Stop, ForceStop, Reset and Delete check the transition as Client.StartInstance and the like do,
+// and return before the instance reaches the target status. Use Wait to wait for it.
+type InstanceService interface {
+	// List returns all instances of the account.
+	List(ctx context.Context) ([]Instance, error)
+	// Find returns the instances that match filter. See Client.ListInstances.
+	Find(ctx context.Context, filter *InstanceFilter) ([]Instance, error)
+	// Get returns the instance, or ErrNotFound if it does not exist. See Client.GetInstance.
+	Get(ctx context.Context, id int64) (*Instance, error)
+	// FindByName returns the instance of the name. See Client.FindInstanceByName.
+	FindByName(ctx context.Context, name string) (*Instance, error)
+	// Create creates a Linux instance.
+	Create(ctx context.Context, req *PostWebArenaIndigoV1VmCreateInstanceRequest) (*Instance, error)
+	// CreateWindows creates a Windows instance.
+	CreateWindows(ctx context.Context, req *PostWebArenaIndigoV1VmCreateWindowsInstanceRequest) (*Instance, error)
+	// CreateFromImportURL creates an instance from the image at req.ImportURL.
+	CreateFromImportURL(ctx context.Context, req *PostWebArenaIndigoV1VmCreateImportURLInstanceRequest) (*Instance, error)
+	// CreateFromSnapshot creates an instance from the snapshot of req.SnapshotID.
+	CreateFromSnapshot(ctx context.Context, req *PostWebArenaIndigoV1VmCreateSnapshotInstanceRequest) (*Instance, error)
+	Start(ctx context.Context, id int64) error
+	Stop(ctx context.Context, id int64) error
+	ForceStop(ctx context.Context, id int64) error
+	Reset(ctx context.Context, id int64) error
+	// Delete destroys the instance.
+	Delete(ctx context.Context, id int64) error
+	// Wait waits for the instance to be in one of targets. See Client.WaitForInstanceStatus.
+	Wait(ctx context.Context, id int64, targets ...InstanceStatus) (*Instance, error)
+}
+
+// SSHKeyService operates SSH keys.
+type SSHKeyService interface {
+	// List returns all SSH keys of the account.
+	List(ctx context.Context) ([]SSHKey, error)
+	// ListActive returns the SSH keys whose status is "ACTIVE".
+	ListActive(ctx context.Context) ([]SSHKey, error)
+	// Get returns the SSH key, or ErrNotFound if it does not exist.
+	Get(ctx context.Context, id int64) (*SSHKey, error)
+	Create(ctx context.Context, req *CreateWebArenaIndigoV1VmSSHKeyRequest) (*SSHKey, error)
+	Update(ctx context.Context, id int64, req *UpdateWebArenaIndigoV1VmSSHKeyRequest) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// FirewallService operates firewalls.
+type FirewallService interface {
+	// List returns all firewalls of the account without their rules.
+	List(ctx context.Context) ([]Firewall, error)
+	// Get returns the firewall with its rules, or ErrNotFound if it does not exist.
+	// If the firewall has no rules, it also gets the firewall list, because the template of such a firewall tells neither its name nor whether it exists.
+	Get(ctx context.Context, id int64) (*FirewallTemplate, error)
+	// Create creates a firewall and returns its ID.
+	Create(ctx context.Context, req *PostWebArenaIndigoV1NwCreateFirewallRequest) (int64, error)
+	// Update replaces the name, the rules and the instances of the firewall of req.TemplateID.
+	Update(ctx context.Context, req *UpdateWebArenaIndigoV1NwFirewallRequest) error
+	// Assign assigns the firewall to the instance.
+	Assign(ctx context.Context, firewallID, instanceID int64) error
+	// Plan returns the changes that Apply would make, without making them. See Client.PlanFirewall.
+	Plan(ctx context.Context, desired *PostWebArenaIndigoV1NwCreateFirewallRequest) (*FirewallPlan, error)
+	// Apply reconciles the firewall of the name with desired. See Client.ApplyFirewall.
+	Apply(ctx context.Context, desired *PostWebArenaIndigoV1NwCreateFirewallRequest) (*FirewallPlan, error)
+	// CheckDrift reports the firewalls that have drifted from desired. See Client.CheckFirewallDrift.
+	CheckDrift(ctx context.Context, desired []*FirewallTemplate) (*FirewallDriftReport, error)
+	// Audit checks the rules of every firewall by linter. See Client.AuditFirewalls.
+	Audit(ctx context.Context, linter *FirewallLinter) (FirewallLintFindings, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// SnapshotService operates snapshots.
+//
+// Create, Retake and Restore return before the snapshot is processed. Use Wait or CreateAndWait, but see Client.WaitForSnapshotStatus for Restore.
+type SnapshotService interface {
+	// List returns the snapshots of the instance.
+	List(ctx context.Context, instanceID int64) ([]Snapshot, error)
+	// Create takes a snapshot of the instance. The instance must be stopped.
+	Create(ctx context.Context, req *PostWebArenaIndigoV1DiskTakeSnapshotRequest) error
+	// CreateAndWait takes a snapshot of the instance and returns it when it is "created". See Client.TakeSnapshotAndWait.
+	CreateAndWait(ctx context.Context, req *PostWebArenaIndigoV1DiskTakeSnapshotRequest) (*Snapshot, error)
+	// Retake takes the snapshot of req.SnapshotID again.
+	Retake(ctx context.Context, req *PostWebArenaIndigoV1DiskRetakeSnapshotRequest) error
+	// Restore restores the snapshot to the instance. The instance must be stopped.
+	Restore(ctx context.Context, req *PostWebArenaIndigoV1DiskRestoreSnapshotRequest) error
+	Delete(ctx context.Context, id int64) error
+	// Wait waits for the snapshot to be in one of targets. See Client.WaitForSnapshotStatus.
+	Wait(ctx context.Context, instanceID, snapshotID int64, targets ...string) (*Snapshot, error)
+}
+
+// APIKeyService operates API keys.
+type APIKeyService interface {
+	// List returns all API keys of the account without their secrets.
+	List(ctx context.Context) ([]APIKey, error)
+	// Create creates an API key and returns it with its secret.
+	Create(ctx context.Context) (*APIKeyCredentials, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// CatalogService lists what instances can be created with.
+type CatalogService interface {
+	ListInstanceTypes(ctx context.Context) ([]InstanceType, error)
+	ListRegions(ctx context.Context, instanceTypeID int64) ([]Region, error)
+	// ListOSes returns the OSes of all the categories for the instance type.
+	ListOSes(ctx context.Context, instanceTypeID int64) ([]OS, error)
+	ListSpecs(ctx context.Context, instanceTypeID, osID int64) ([]InstanceSpec, error)
+	// Resolve resolves the names of query into the IDs. See Client.ResolveCatalog.
+	Resolve(ctx context.Context, query *CatalogQuery) (*ResolvedCatalog, error)
+}
+
+//nolint:gochecknoglobals
+var (
+	_ InstanceService = (*instanceService)(nil)
+	_ SSHKeyService   = (*sshKeyService)(nil)
+	_ FirewallService = (*firewallService)(nil)
+	_ SnapshotService = (*snapshotService)(nil)
+	_ APIKeyService   = (*apiKeyService)(nil)
+	_ CatalogService  = (*catalogService)(nil)
+)
+
+// wrapNotFound makes err also satisfy errors.Is for ErrNotFound if the API has responded 404 Not Found.
+func wrapNotFound(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+func (c *Client) initServices() {
+	c.Instances = &instanceService{c: c}
+	c.SSHKeys = &sshKeyService{c: c}
+	c.Firewalls = &firewallService{c: c}
+	c.Snapshots = &snapshotService{c: c}
+	c.APIKeys = &apiKeyService{c: c}
+	c.Catalog = &catalogService{c: c}
+}
@@ -0,0 +1,32 @@
+package indigo
+
+import (
+	"context"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+type apiKeyService struct{ c *Client }
+
+func (s *apiKeyService) List(ctx context.Context) ([]APIKey, error) {
+	resp, err := s.c.GetWebArenaIndigoV1AuthAPIKey(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1AuthAPIKey: %w", err)
+	}
+	return resp.AccessTokens, nil
+}
+
+func (s *apiKeyService) Create(ctx context.Context) (*APIKeyCredentials, error) {
+	resp, err := s.c.CreateWebArenaIndigoV1AuthCreateAPIKey(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.CreateWebArenaIndigoV1AuthCreateAPIKey: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *apiKeyService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.c.DeleteWebArenaIndigoV1AuthAPIKey(ctx, id); err != nil {
+		return errorz.Errorf("c.DeleteWebArenaIndigoV1AuthAPIKey: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package indigo
+
+import (
+	"context"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+type catalogService struct{ c *Client }
+
+func (s *catalogService) ListInstanceTypes(ctx context.Context) ([]InstanceType, error) {
+	resp, err := s.c.GetWebArenaIndigoV1VmInstanceTypes(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmInstanceTypes: %w", err)
+	}
+	return resp.InstanceTypes, nil
+}
+
+func (s *catalogService) ListRegions(ctx context.Context, instanceTypeID int64) ([]Region, error) {
+	resp, err := s.c.GetWebArenaIndigoV1VmGetRegion(ctx, instanceTypeID)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmGetRegion: %w", err)
+	}
+	return resp.RegionList, nil
+}
+
+func (s *catalogService) ListOSes(ctx context.Context, instanceTypeID int64) ([]OS, error) {
+	resp, err := s.c.GetWebArenaIndigoV1VmOSList(ctx, instanceTypeID)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmOSList: %w", err)
+	}
+
+	var oses []OS
+	for _, category := range resp.OsCategory {
+		oses = append(oses, category.OsLists...)
+	}
+	return oses, nil
+}
+
+func (s *catalogService) ListSpecs(ctx context.Context, instanceTypeID, osID int64) ([]InstanceSpec, error) {
+	resp, err := s.c.GetWebArenaIndigoV1VmInstanceSpec(ctx, instanceTypeID, osID)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmInstanceSpec: %w", err)
+	}
+	return resp.SpecList, nil
+}
+
+func (s *catalogService) Resolve(ctx context.Context, query *CatalogQuery) (*ResolvedCatalog, error) {
+	resolved, err := s.c.ResolveCatalog(ctx, query)
+	if err != nil {
+		return nil, errorz.Errorf("c.ResolveCatalog: %w", err)
+	}
+	return resolved, nil
+}
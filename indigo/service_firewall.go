@@ -0,0 +1,105 @@
+package indigo
+
+import (
+	"context"
+	"slices"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+type firewallService struct{ c *Client }
+
+func (s *firewallService) List(ctx context.Context) ([]Firewall, error) {
+	resp, err := s.c.GetWebArenaIndigoV1NwGetFirewallList(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1NwGetFirewallList: %w", err)
+	}
+	return *resp, nil
+}
+
+func (s *firewallService) Get(ctx context.Context, id int64) (*FirewallTemplate, error) {
+	resp, err := s.c.GetWebArenaIndigoV1NwGetTemplate(ctx, id)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1NwGetTemplate: firewallID=%d: %w", id, wrapNotFound(err))
+	}
+	template, err := NewFirewallTemplate(id, *resp)
+	if err != nil {
+		return nil, errorz.Errorf("NewFirewallTemplate: %w", err)
+	}
+	if len(*resp) > 0 {
+		return template, nil
+	}
+
+	// NOTE: The template of a firewall without rules has no rows, so whether the firewall exists and its name come from the list.
+	list, err := s.List(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("s.List: %w", err)
+	}
+	i := slices.IndexFunc(list, func(firewall Firewall) bool { return firewall.ID == id })
+	if i < 0 {
+		return nil, errorz.Errorf("firewallID=%d: %w", id, ErrNotFound)
+	}
+	template.Name = list[i].Name
+	return template, nil
+}
+
+func (s *firewallService) Create(ctx context.Context, req *PostWebArenaIndigoV1NwCreateFirewallRequest) (int64, error) {
+	resp, err := s.c.PostWebArenaIndigoV1NwCreateFirewall(ctx, req)
+	if err != nil {
+		return 0, errorz.Errorf("c.PostWebArenaIndigoV1NwCreateFirewall: %w", err)
+	}
+	return resp.FirewallID, nil
+}
+
+func (s *firewallService) Update(ctx context.Context, req *UpdateWebArenaIndigoV1NwFirewallRequest) error {
+	if _, err := s.c.UpdateWebArenaIndigoV1NwFirewall(ctx, req); err != nil {
+		return errorz.Errorf("c.UpdateWebArenaIndigoV1NwFirewall: %w", err)
+	}
+	return nil
+}
+
+func (s *firewallService) Assign(ctx context.Context, firewallID, instanceID int64) error {
+	if _, err := s.c.PostWebArenaIndigoV1NwAssign(ctx, &PostWebArenaIndigoV1NwAssignRequest{InstanceID: instanceID, TemplateID: firewallID}); err != nil {
+		return errorz.Errorf("c.PostWebArenaIndigoV1NwAssign: %w", err)
+	}
+	return nil
+}
+
+func (s *firewallService) Plan(ctx context.Context, desired *PostWebArenaIndigoV1NwCreateFirewallRequest) (*FirewallPlan, error) {
+	plan, err := s.c.PlanFirewall(ctx, desired)
+	if err != nil {
+		return nil, errorz.Errorf("c.PlanFirewall: %w", err)
+	}
+	return plan, nil
+}
+
+func (s *firewallService) Apply(ctx context.Context, desired *PostWebArenaIndigoV1NwCreateFirewallRequest) (*FirewallPlan, error) {
+	plan, err := s.c.ApplyFirewall(ctx, desired)
+	if err != nil {
+		return nil, errorz.Errorf("c.ApplyFirewall: %w", err)
+	}
+	return plan, nil
+}
+
+func (s *firewallService) CheckDrift(ctx context.Context, desired []*FirewallTemplate) (*FirewallDriftReport, error) {
+	report, err := s.c.CheckFirewallDrift(ctx, desired)
+	if err != nil {
+		return nil, errorz.Errorf("c.CheckFirewallDrift: %w", err)
+	}
+	return report, nil
+}
+
+func (s *firewallService) Audit(ctx context.Context, linter *FirewallLinter) (FirewallLintFindings, error) {
+	findings, err := s.c.AuditFirewalls(ctx, linter)
+	if err != nil {
+		return nil, errorz.Errorf("c.AuditFirewalls: %w", err)
+	}
+	return findings, nil
+}
+
+func (s *firewallService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.c.DeleteWebArenaIndigoV1NwDeleteFirewall(ctx, id); err != nil {
+		return errorz.Errorf("c.DeleteWebArenaIndigoV1NwDeleteFirewall: %w", err)
+	}
+	return nil
+}
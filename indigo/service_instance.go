@@ -0,0 +1,116 @@
+package indigo
+
+import (
+	"context"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+type instanceService struct{ c *Client }
+
+func (s *instanceService) List(ctx context.Context) ([]Instance, error) {
+	instances, err := s.c.GetWebArenaIndigoV1VmGetInstanceList(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmGetInstanceList: %w", err)
+	}
+	return instances, nil
+}
+
+func (s *instanceService) Find(ctx context.Context, filter *InstanceFilter) ([]Instance, error) {
+	instances, err := s.c.ListInstances(ctx, filter)
+	if err != nil {
+		return nil, errorz.Errorf("c.ListInstances: %w", err)
+	}
+	return instances, nil
+}
+
+func (s *instanceService) Get(ctx context.Context, id int64) (*Instance, error) {
+	instance, err := s.c.GetInstance(ctx, id)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetInstance: %w", err)
+	}
+	return instance, nil
+}
+
+func (s *instanceService) FindByName(ctx context.Context, name string) (*Instance, error) {
+	instance, err := s.c.FindInstanceByName(ctx, name)
+	if err != nil {
+		return nil, errorz.Errorf("c.FindInstanceByName: %w", err)
+	}
+	return instance, nil
+}
+
+func (s *instanceService) Create(ctx context.Context, req *PostWebArenaIndigoV1VmCreateInstanceRequest) (*Instance, error) {
+	resp, err := s.c.PostWebArenaIndigoV1VmCreateInstance(ctx, req)
+	if err != nil {
+		return nil, errorz.Errorf("c.PostWebArenaIndigoV1VmCreateInstance: %w", err)
+	}
+	return &resp.Vms, nil
+}
+
+func (s *instanceService) CreateWindows(ctx context.Context, req *PostWebArenaIndigoV1VmCreateWindowsInstanceRequest) (*Instance, error) {
+	resp, err := s.c.PostWebArenaIndigoV1VmCreateWindowsInstance(ctx, req)
+	if err != nil {
+		return nil, errorz.Errorf("c.PostWebArenaIndigoV1VmCreateWindowsInstance: %w", err)
+	}
+	return &resp.Vms, nil
+}
+
+func (s *instanceService) CreateFromImportURL(ctx context.Context, req *PostWebArenaIndigoV1VmCreateImportURLInstanceRequest) (*Instance, error) {
+	resp, err := s.c.PostWebArenaIndigoV1VmCreateImportURLInstance(ctx, req)
+	if err != nil {
+		return nil, errorz.Errorf("c.PostWebArenaIndigoV1VmCreateImportURLInstance: %w", err)
+	}
+	return &resp.Vms, nil
+}
+
+func (s *instanceService) CreateFromSnapshot(ctx context.Context, req *PostWebArenaIndigoV1VmCreateSnapshotInstanceRequest) (*Instance, error) {
+	resp, err := s.c.PostWebArenaIndigoV1VmCreateSnapshotInstance(ctx, req)
+	if err != nil {
+		return nil, errorz.Errorf("c.PostWebArenaIndigoV1VmCreateSnapshotInstance: %w", err)
+	}
+	return &resp.Vms, nil
+}
+
+func (s *instanceService) Start(ctx context.Context, id int64) error {
+	if _, err := s.c.StartInstance(ctx, id); err != nil {
+		return errorz.Errorf("c.StartInstance: %w", err)
+	}
+	return nil
+}
+
+func (s *instanceService) Stop(ctx context.Context, id int64) error {
+	if _, err := s.c.StopInstance(ctx, id); err != nil {
+		return errorz.Errorf("c.StopInstance: %w", err)
+	}
+	return nil
+}
+
+func (s *instanceService) ForceStop(ctx context.Context, id int64) error {
+	if _, err := s.c.ForceStopInstance(ctx, id); err != nil {
+		return errorz.Errorf("c.ForceStopInstance: %w", err)
+	}
+	return nil
+}
+
+func (s *instanceService) Reset(ctx context.Context, id int64) error {
+	if _, err := s.c.ResetInstance(ctx, id); err != nil {
+		return errorz.Errorf("c.ResetInstance: %w", err)
+	}
+	return nil
+}
+
+func (s *instanceService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.c.DestroyInstance(ctx, id); err != nil {
+		return errorz.Errorf("c.DestroyInstance: %w", err)
+	}
+	return nil
+}
+
+func (s *instanceService) Wait(ctx context.Context, id int64, targets ...InstanceStatus) (*Instance, error) {
+	instance, err := s.c.WaitForInstanceStatus(ctx, id, targets...)
+	if err != nil {
+		return nil, errorz.Errorf("c.WaitForInstanceStatus: %w", err)
+	}
+	return instance, nil
+}
@@ -0,0 +1,61 @@
+package indigo
+
+import (
+	"context"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+type snapshotService struct{ c *Client }
+
+func (s *snapshotService) List(ctx context.Context, instanceID int64) ([]Snapshot, error) {
+	resp, err := s.c.GetWebArenaIndigoV1DiskSnapshotList(ctx, instanceID)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1DiskSnapshotList: %w", err)
+	}
+	return *resp, nil
+}
+
+func (s *snapshotService) Create(ctx context.Context, req *PostWebArenaIndigoV1DiskTakeSnapshotRequest) error {
+	if _, err := s.c.PostWebArenaIndigoV1DiskTakeSnapshot(ctx, req); err != nil {
+		return errorz.Errorf("c.PostWebArenaIndigoV1DiskTakeSnapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *snapshotService) CreateAndWait(ctx context.Context, req *PostWebArenaIndigoV1DiskTakeSnapshotRequest) (*Snapshot, error) {
+	snapshot, err := s.c.TakeSnapshotAndWait(ctx, req)
+	if err != nil {
+		return nil, errorz.Errorf("c.TakeSnapshotAndWait: %w", err)
+	}
+	return snapshot, nil
+}
+
+func (s *snapshotService) Retake(ctx context.Context, req *PostWebArenaIndigoV1DiskRetakeSnapshotRequest) error {
+	if _, err := s.c.PostWebArenaIndigoV1DiskRetakeSnapshot(ctx, req); err != nil {
+		return errorz.Errorf("c.PostWebArenaIndigoV1DiskRetakeSnapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *snapshotService) Restore(ctx context.Context, req *PostWebArenaIndigoV1DiskRestoreSnapshotRequest) error {
+	if _, err := s.c.PostWebArenaIndigoV1DiskRestoreSnapshot(ctx, req); err != nil {
+		return errorz.Errorf("c.PostWebArenaIndigoV1DiskRestoreSnapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *snapshotService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.c.DeleteWebArenaIndigoV1DiskDeleteSnapshot(ctx, id); err != nil {
+		return errorz.Errorf("c.DeleteWebArenaIndigoV1DiskDeleteSnapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *snapshotService) Wait(ctx context.Context, instanceID, snapshotID int64, targets ...string) (*Snapshot, error) {
+	snapshot, err := s.c.WaitForSnapshotStatus(ctx, instanceID, snapshotID, targets...)
+	if err != nil {
+		return nil, errorz.Errorf("c.WaitForSnapshotStatus: %w", err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,59 @@
+package indigo
+
+import (
+	"context"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+type sshKeyService struct{ c *Client }
+
+func (s *sshKeyService) List(ctx context.Context) ([]SSHKey, error) {
+	resp, err := s.c.GetWebArenaIndigoV1VmSSHKey(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmSSHKey: %w", err)
+	}
+	return resp.Sshkeys, nil
+}
+
+func (s *sshKeyService) ListActive(ctx context.Context) ([]SSHKey, error) {
+	resp, err := s.c.GetWebArenaIndigoV1VmSSHKeyActiveStatus(ctx)
+	if err != nil {
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1VmSSHKeyActiveStatus: %w", err)
+	}
+	return resp.Sshkeys, nil
+}
+
+func (s *sshKeyService) Get(ctx context.Context, id int64) (*SSHKey, error) {
+	resp, err := s.c.RetrieveWebArenaIndigoV1VmSSHKey(ctx, id)
+	if err != nil {
+		return nil, errorz.Errorf("c.RetrieveWebArenaIndigoV1VmSSHKey: sshKeyID=%d: %w", id, wrapNotFound(err))
+	}
+	// NOTE: The API returns the SSH key in an array.
+	if len(resp.SshKey) == 0 {
+		return nil, errorz.Errorf("sshKeyID=%d: %w", id, ErrNotFound)
+	}
+	return &resp.SshKey[0], nil
+}
+
+func (s *sshKeyService) Create(ctx context.Context, req *CreateWebArenaIndigoV1VmSSHKeyRequest) (*SSHKey, error) {
+	resp, err := s.c.CreateWebArenaIndigoV1VmSSHKey(ctx, req)
+	if err != nil {
+		return nil, errorz.Errorf("c.CreateWebArenaIndigoV1VmSSHKey: %w", err)
+	}
+	return &resp.SshKey, nil
+}
+
+func (s *sshKeyService) Update(ctx context.Context, id int64, req *UpdateWebArenaIndigoV1VmSSHKeyRequest) error {
+	if _, err := s.c.UpdateWebArenaIndigoV1VmSSHKey(ctx, id, req); err != nil {
+		return errorz.Errorf("c.UpdateWebArenaIndigoV1VmSSHKey: %w", err)
+	}
+	return nil
+}
+
+func (s *sshKeyService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.c.DestroyWebArenaIndigoV1VmSSHKey(ctx, id); err != nil {
+		return errorz.Errorf("c.DestroyWebArenaIndigoV1VmSSHKey: %w", err)
+	}
+	return nil
+}
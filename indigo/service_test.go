@@ -0,0 +1,287 @@
+package indigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+func newTestServiceClient(ctx context.Context, tb testing.TB) *Client {
+	tb.Helper()
+
+	srv := indigotest.NewServer()
+	tb.Cleanup(srv.Close)
+
+	client, err := NewClient(ctx, testServerClientOptions(srv)...)
+	requirez.NoError(tb, err)
+
+	return client
+}
+
+// fakeInstanceService shows that the services can be replaced in tests.
+type fakeInstanceService struct {
+	InstanceService
+	instances []Instance
+}
+
+func (s *fakeInstanceService) List(context.Context) ([]Instance, error) { return s.instances, nil }
+
+func TestClient_services(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,instances", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+
+		created, err := client.Instances.Create(ctx, &PostWebArenaIndigoV1VmCreateInstanceRequest{RegionID: 1, OsID: 1, InstancePlan: 1, InstanceName: "test-instance"})
+		requirez.NoError(t, err)
+		requirez.Equal(t, "test-instance", created.InstanceName)
+
+		requirez.NoError(t, client.Instances.Start(ctx, created.ID))
+		running, err := client.Instances.Wait(ctx, created.ID, InstanceStatusRunning)
+		requirez.NoError(t, err)
+		requirez.Equal(t, created.ID, running.ID)
+
+		requirez.ErrorIs(t, client.Instances.Start(ctx, created.ID), ErrInvalidInstanceTransition)
+		requirez.NoError(t, client.Instances.Stop(ctx, created.ID))
+		requirez.NoError(t, client.Instances.Delete(ctx, created.ID))
+
+		instances, err := client.Instances.List(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 0, len(instances))
+	})
+
+	t.Run("success,sshKeys", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+
+		created, err := client.SSHKeys.Create(ctx, &CreateWebArenaIndigoV1VmSSHKeyRequest{SshName: "test", SshKey: "ssh-ed25519 AAAA"})
+		requirez.NoError(t, err)
+
+		requirez.NoError(t, client.SSHKeys.Update(ctx, created.Id, &UpdateWebArenaIndigoV1VmSSHKeyRequest{SshName: "renamed", SshKey: "ssh-ed25519 AAAA", SshKeyState: "ACTIVE"}))
+
+		got, err := client.SSHKeys.Get(ctx, created.Id)
+		requirez.NoError(t, err)
+		requirez.Equal(t, "renamed", got.Name)
+
+		keys, err := client.SSHKeys.List(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 1, len(keys))
+
+		active, err := client.SSHKeys.ListActive(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 1, len(active))
+
+		requirez.NoError(t, client.SSHKeys.Delete(ctx, created.Id))
+
+		_, err = client.SSHKeys.Get(ctx, created.Id)
+		requirez.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("success,firewalls", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+		instanceID := createTestInstance(ctx, t, client)
+
+		id, err := client.Firewalls.Create(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{
+			Name:      "web",
+			Inbound:   []FirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}},
+			Outbound:  []FirewallRule{{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}},
+			Instances: []int64{},
+		})
+		requirez.NoError(t, err)
+
+		firewalls, err := client.Firewalls.List(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 1, len(firewalls))
+		requirez.Equal(t, id, firewalls[0].ID)
+
+		got, err := client.Firewalls.Get(ctx, id)
+		requirez.NoError(t, err)
+		requirez.Equal(t, &FirewallTemplate{
+			ID:       id,
+			Name:     "web",
+			Inbound:  []FirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}},
+			Outbound: []FirewallRule{{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}},
+		}, got)
+
+		requirez.NoError(t, client.Firewalls.Update(ctx, &UpdateWebArenaIndigoV1NwFirewallRequest{TemplateID: id, Name: "web", Inbound: []FirewallRule{}, Outbound: []FirewallRule{}, Instances: []int64{}}))
+		requirez.NoError(t, client.Firewalls.Assign(ctx, id, instanceID))
+		requirez.NoError(t, client.Firewalls.Delete(ctx, id))
+
+		_, err = client.Firewalls.Get(ctx, id)
+		requirez.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("success,firewallWithoutRules", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+		// NOTE: The API may respond to an unknown firewall with no rows instead of 404 Not Found.
+		stub := newStubServer(t, srv, func(w http.ResponseWriter, r *http.Request) bool {
+			if !strings.HasPrefix(r.URL.Path, PathWebArenaIndigoV1NwGetTemplate+"/") {
+				return false
+			}
+			w.Header().Set("Content-Type", "application/json")
+			return true
+		})
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithEndpoint(stub.URL))...)
+		requirez.NoError(t, err)
+		id, err := client.Firewalls.Create(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "empty", Inbound: []FirewallRule{}, Outbound: []FirewallRule{}, Instances: []int64{}})
+		requirez.NoError(t, err)
+
+		got, err := client.Firewalls.Get(ctx, id)
+		requirez.NoError(t, err)
+		requirez.Equal(t, &FirewallTemplate{ID: id, Name: "empty"}, got)
+
+		_, err = client.Firewalls.Get(ctx, id+1)
+		requirez.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("success,snapshots", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+		instanceID := createTestInstance(ctx, t, client)
+
+		created, err := client.Snapshots.CreateAndWait(ctx, &PostWebArenaIndigoV1DiskTakeSnapshotRequest{Name: "snap", InstanceID: instanceID})
+		requirez.NoError(t, err)
+		requirez.Equal(t, "created", created.Status)
+
+		requirez.NoError(t, client.Snapshots.Retake(ctx, &PostWebArenaIndigoV1DiskRetakeSnapshotRequest{InstanceID: instanceID, SnapshotID: created.ID}))
+		_, err = client.Snapshots.Wait(ctx, instanceID, created.ID, "created")
+		requirez.NoError(t, err)
+
+		requirez.NoError(t, client.Snapshots.Restore(ctx, &PostWebArenaIndigoV1DiskRestoreSnapshotRequest{InstanceID: instanceID, SnapshotID: created.ID}))
+
+		requirez.NoError(t, client.Snapshots.Delete(ctx, created.ID))
+
+		snapshots, err := client.Snapshots.List(ctx, instanceID)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 0, len(snapshots))
+	})
+
+	t.Run("success,apiKeys", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+
+		created, err := client.APIKeys.Create(ctx)
+		requirez.NoError(t, err)
+		requirez.True(t, created.APISecret != "")
+
+		keys, err := client.APIKeys.List(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 1, len(keys))
+		requirez.Equal(t, created.APIKey, keys[0].APIKey)
+
+		requirez.NoError(t, client.APIKeys.Delete(ctx, keys[0].ID))
+	})
+
+	t.Run("success,catalog", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+
+		types, err := client.Catalog.ListInstanceTypes(ctx)
+		requirez.NoError(t, err)
+		requirez.True(t, len(types) > 0)
+
+		regions, err := client.Catalog.ListRegions(ctx, types[0].ID)
+		requirez.NoError(t, err)
+		requirez.True(t, len(regions) > 0)
+
+		oses, err := client.Catalog.ListOSes(ctx, types[0].ID)
+		requirez.NoError(t, err)
+		requirez.True(t, len(oses) > 0)
+
+		specs, err := client.Catalog.ListSpecs(ctx, types[0].ID, oses[0].ID)
+		requirez.NoError(t, err)
+		requirez.True(t, len(specs) > 0)
+	})
+
+	t.Run("success,mock", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestServiceClient(ctx, t)
+		client.Instances = &fakeInstanceService{instances: []Instance{{ID: 1, InstanceName: "fake"}}}
+
+		instances, err := client.Instances.List(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, "fake", instances[0].InstanceName)
+	})
+}
+
+func TestNewFirewallTemplate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		template, err := NewFirewallTemplate(5, []WebArenaIndigoV1NwGetTemplateFirewall{
+			{ID: 5, Name: "web", Direction: "in", Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"},
+			{ID: 5, Name: "web", Direction: "out", Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"},
+		})
+		requirez.NoError(t, err)
+		requirez.Equal(t, &FirewallTemplate{
+			ID:       5,
+			Name:     "web",
+			Inbound:  []FirewallRule{{Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"}},
+			Outbound: []FirewallRule{{Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"}},
+		}, template)
+	})
+
+	t.Run("failure,unknownDirection", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewFirewallTemplate(5, []WebArenaIndigoV1NwGetTemplateFirewall{
+			{ID: 5, Name: "web", Direction: "in", Type: "HTTP", Protocol: "TCP", Port: "80", Source: "0.0.0.0"},
+			{ID: 5, Name: "web", Direction: "both", Type: "HTTPS", Protocol: "TCP", Port: "443", Source: "0.0.0.0"},
+		})
+		requirez.ErrorIs(t, err, ErrUnknownFirewallDirection)
+		requirez.ErrorContains(t, err, `rows[1].direction="both"`)
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+		stub := newStubServer(t, srv, func(w http.ResponseWriter, r *http.Request) bool {
+			if !strings.HasPrefix(r.URL.Path, PathWebArenaIndigoV1NwGetTemplate+"/") {
+				return false
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"id":1,"name":"web","direction":"","type":"HTTP","protocol":"TCP","port":"80","source":"0.0.0.0"}`)
+			return true
+		})
+
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithEndpoint(stub.URL))...)
+		requirez.NoError(t, err)
+		_, err = client.Firewalls.Create(ctx, &PostWebArenaIndigoV1NwCreateFirewallRequest{Name: "web", Inbound: []FirewallRule{}, Outbound: []FirewallRule{}, Instances: []int64{}})
+		requirez.NoError(t, err)
+
+		_, err = client.Firewalls.Get(ctx, 1)
+		requirez.ErrorIs(t, err, ErrUnknownFirewallDirection)
+		_, err = client.AuditFirewalls(ctx, nil)
+		requirez.ErrorIs(t, err, ErrUnknownFirewallDirection)
+		_, err = client.CheckFirewallDrift(ctx, []*FirewallTemplate{{Name: "web"}})
+		requirez.ErrorIs(t, err, ErrUnknownFirewallDirection)
+	})
+}
@@ -0,0 +1,128 @@
+package indigo
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// snapshotStatusCreated is the status of a snapshot that has been taken, retaken or restored successfully.
+const snapshotStatusCreated = "created"
+
+// snapshotFailureStatuses is the statuses from which a snapshot never reaches the other statuses by itself.
+//
+//nolint:gochecknoglobals
+var snapshotFailureStatuses = []string{"failed", "error"}
+
+// WaitForSnapshotStatus polls GetWebArenaIndigoV1DiskSnapshotList until the snapshot of snapshotID is in one of targets,
+// and returns the snapshot in that status.
+//
+// PostWebArenaIndigoV1DiskTakeSnapshot and PostWebArenaIndigoV1DiskRetakeSnapshot return before the snapshot is processed,
+// and the snapshot stays in a pending status until it becomes "created" or "failed". Call this with "created" to wait for them to complete.
+//
+// PostWebArenaIndigoV1DiskRestoreSnapshot also returns before the restore completes, but the API does not document how a snapshot reports a restore:
+// the snapshot may still be "created" right after the request, so waiting for "created" does not tell that the restore has completed.
+// Therefore no waiter of this package waits for a restore. Wait for it only if the snapshot of your account is known to report it, e.g. as "restoring".
+//
+// The polls follow the WaitPolicy of the client, or the one set by ContextWithWaitPolicy.
+// It fails fast with ErrNotFound if the snapshot disappears, and with ErrWaitTerminalStatus if the snapshot is "failed".
+// It returns ErrWaitTimeout if WaitPolicy.Timeout elapses.
+func (c *Client) WaitForSnapshotStatus(ctx context.Context, instanceID, snapshotID int64, targets ...string) (*WebArenaIndigoV1DiskSnapshot, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(instanceID), attributeKeySnapshotID.Int64(snapshotID)))
+	defer span.End()
+
+	if len(targets) == 0 {
+		err := errorz.Errorf("instanceID=%d: targets must not be empty: %w", instanceID, ErrInvalidArgument)
+		recordError(span, err)
+		return nil, errorz.Errorf("snapshotID=%d: %w", snapshotID, err)
+	}
+
+	snapshot, err := c.waitForSnapshot(ctx, instanceID, snapshotIDIs(snapshotID), snapshotStatusIn(targets...), false)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("snapshotID=%d: %w", snapshotID, err)
+	}
+
+	return snapshot, nil
+}
+
+func snapshotIDIs(snapshotID int64) func(snapshot WebArenaIndigoV1DiskSnapshot) bool {
+	return func(snapshot WebArenaIndigoV1DiskSnapshot) bool { return snapshot.ID == snapshotID }
+}
+
+func snapshotStatusIn(statuses ...string) func(snapshot WebArenaIndigoV1DiskSnapshot) bool {
+	return func(snapshot WebArenaIndigoV1DiskSnapshot) bool { return slices.Contains(statuses, snapshot.Status) }
+}
+
+// waitForSnapshot waits until the first snapshot of the instance that matches is done.
+// If allowMissing is true, the wait continues while no snapshot matches, e.g. because the API has not listed a new snapshot yet.
+func (c *Client) waitForSnapshot(ctx context.Context, instanceID int64, match, done func(snapshot WebArenaIndigoV1DiskSnapshot) bool, allowMissing bool) (*WebArenaIndigoV1DiskSnapshot, error) {
+	var found *WebArenaIndigoV1DiskSnapshot
+	if err := c.wait(ctx, func(ctx context.Context) (string, bool, error) {
+		snapshots, err := c.GetWebArenaIndigoV1DiskSnapshotList(ctx, instanceID)
+		if err != nil {
+			return "", false, errorz.Errorf("c.GetWebArenaIndigoV1DiskSnapshotList: %w", err)
+		}
+
+		i := slices.IndexFunc(*snapshots, match)
+		if i < 0 {
+			if allowMissing {
+				return "", false, nil
+			}
+			return "", false, errorz.Errorf("instanceID=%d: %w", instanceID, ErrNotFound)
+		}
+
+		found = &(*snapshots)[i]
+		status := found.Status
+		if done(*found) {
+			return status, true, nil
+		}
+		if slices.ContainsFunc(snapshotFailureStatuses, func(s string) bool { return strings.EqualFold(s, status) }) {
+			return status, false, errorz.Errorf("instanceID=%d snapshotID=%d status=%s: %w", instanceID, found.ID, status, ErrWaitTerminalStatus)
+		}
+		return status, false, nil
+	}); err != nil {
+		return nil, errorz.Errorf("c.wait: %w", err)
+	}
+
+	return found, nil
+}
+
+// TakeSnapshotAndWait takes a snapshot of the instance by PostWebArenaIndigoV1DiskTakeSnapshot, and waits for it to be "created".
+//
+// PostWebArenaIndigoV1DiskTakeSnapshot does not return the ID of the new snapshot,
+// so the new snapshot is identified as the one with req.Name that did not exist before the request.
+// The instance must be stopped.
+func (c *Client) TakeSnapshotAndWait(ctx context.Context, req *PostWebArenaIndigoV1DiskTakeSnapshotRequest) (*WebArenaIndigoV1DiskSnapshot, error) {
+	ctx, span := c.start(ctx, trace.WithAttributes(attributeKeyInstanceID.Int64(req.InstanceID)))
+	defer span.End()
+
+	before, err := c.GetWebArenaIndigoV1DiskSnapshotList(ctx, req.InstanceID)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.GetWebArenaIndigoV1DiskSnapshotList: %w", err)
+	}
+	known := make(map[int64]bool, len(*before))
+	for _, snapshot := range *before {
+		known[snapshot.ID] = true
+	}
+
+	if _, err := c.PostWebArenaIndigoV1DiskTakeSnapshot(ctx, req); err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.PostWebArenaIndigoV1DiskTakeSnapshot: %w", err)
+	}
+
+	snapshot, err := c.waitForSnapshot(ctx, req.InstanceID, func(snapshot WebArenaIndigoV1DiskSnapshot) bool {
+		return !known[snapshot.ID] && snapshot.Name == req.Name
+	}, snapshotStatusIn(snapshotStatusCreated), true)
+	if err != nil {
+		recordError(span, err)
+		return nil, errorz.Errorf("c.waitForSnapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,96 @@
+package indigo
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+//nolint:funlen
+func TestClient_WaitForSnapshotStatus(t *testing.T) {
+	t.Parallel()
+
+	const transitionDelay = 50 * time.Millisecond
+
+	t.Run("success,takeAndRestore", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(transitionDelay))
+		t.Cleanup(srv.Close)
+
+		recorder := &progressRecorder{}
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(recorder.record))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		instanceID := createTestInstance(ctx, t, client)
+
+		snapshot, err := client.TakeSnapshotAndWait(ctx, &PostWebArenaIndigoV1DiskTakeSnapshotRequest{Name: "snap", InstanceID: instanceID, SlotNum: 0})
+		requirez.NoError(t, err)
+		requirez.Equal(t, "snap", snapshot.Name)
+		requirez.Equal(t, "created", snapshot.Status)
+		requirez.True(t, slices.Contains(recorder.statuses(), "pending"))
+
+		// NOTE: A snapshot with the same name is a different snapshot.
+		again, err := client.TakeSnapshotAndWait(ctx, &PostWebArenaIndigoV1DiskTakeSnapshotRequest{Name: "snap", InstanceID: instanceID, SlotNum: 0})
+		requirez.NoError(t, err)
+		requirez.True(t, again.ID != snapshot.ID)
+
+		// NOTE: The fake server reports a snapshot being restored as "restoring", so the restore can be waited for as "created".
+		_, err = client.PostWebArenaIndigoV1DiskRestoreSnapshot(ctx, &PostWebArenaIndigoV1DiskRestoreSnapshotRequest{InstanceID: instanceID, SnapshotID: snapshot.ID})
+		requirez.NoError(t, err)
+		restored, err := client.WaitForSnapshotStatus(ctx, instanceID, snapshot.ID, "created")
+		requirez.NoError(t, err)
+		requirez.Equal(t, snapshot.ID, restored.ID)
+		requirez.True(t, slices.Contains(recorder.statuses(), "restoring"))
+
+		_, err = client.PostWebArenaIndigoV1DiskRetakeSnapshot(ctx, &PostWebArenaIndigoV1DiskRetakeSnapshotRequest{InstanceID: instanceID, SnapshotID: snapshot.ID})
+		requirez.NoError(t, err)
+		retaken, err := client.WaitForSnapshotStatus(ctx, instanceID, snapshot.ID, "created")
+		requirez.NoError(t, err)
+		requirez.Equal(t, "created", retaken.Status)
+	})
+
+	t.Run("failure,failed", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer(indigotest.ServerOptionWithTransitionDelay(time.Hour))
+		t.Cleanup(srv.Close)
+
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(nil))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		instanceID := createTestInstance(ctx, t, client)
+		_, err = client.PostWebArenaIndigoV1DiskTakeSnapshot(ctx, &PostWebArenaIndigoV1DiskTakeSnapshotRequest{Name: "snap", InstanceID: instanceID, SlotNum: 0})
+		requirez.NoError(t, err)
+		snapshots, err := client.GetWebArenaIndigoV1DiskSnapshotList(ctx, instanceID)
+		requirez.NoError(t, err)
+		snapshotID := (*snapshots)[0].ID
+		requirez.True(t, srv.SetSnapshotStatus(snapshotID, "failed"))
+
+		_, err = client.WaitForSnapshotStatus(ctx, instanceID, snapshotID, "created")
+		requirez.ErrorIs(t, err, ErrWaitTerminalStatus)
+	})
+
+	t.Run("failure,notFound", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := ContextWithWaitPolicy(context.Background(), newTestWaitPolicy(nil))
+		client, err := NewClient(ctx, testServerClientOptions(srv)...)
+		requirez.NoError(t, err)
+
+		instanceID := createTestInstance(ctx, t, client)
+
+		_, err = client.WaitForSnapshotStatus(ctx, instanceID, 999, "created")
+		requirez.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,198 @@
+package indigo
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// TimeLayout is the layout of the timestamps that the API returns as strings, e.g. "2018-11-07 17:11:21".
+const TimeLayout = "2006-01-02 15:04:05"
+
+// Time is a timestamp returned by the API.
+//
+// The API returns timestamps in several forms, and Time accepts all of them:
+//
+//   - a string in TimeLayout, optionally with fractional seconds, e.g. "2018-11-10 10:03:17.744562"
+//   - a string in RFC 3339, e.g. "2018-11-10T10:03:17+09:00"
+//   - epoch milliseconds as a number or a string, e.g. "1550570350202" of issuedAt
+//   - a PHP DateTime object, e.g. {"date": "2018-11-10 10:03:17.744562", "timezone_type": 3, "timezone": "UTC"}
+//   - null, "" and the zero sentinel "0000-00-00 00:00:00", which are decoded as the zero Time
+//
+// A string without a timezone is interpreted as UTC, as the PHP DateTime objects of the API report.
+// Use IsZero to check whether the API has returned a timestamp.
+type Time struct {
+	time.Time
+}
+
+// zeroTimeSentinel is the value that the API returns for a timestamp that has not been set.
+const zeroTimeSentinel = "0000-00-00 00:00:00"
+
+// NewTime returns t as Time.
+func NewTime(t time.Time) Time { return Time{Time: t} }
+
+// phpDateTime is the JSON representation of a PHP DateTime object.
+//
+//nolint:tagliatelle // JSON field name is defined by the API
+type phpDateTime struct {
+	Date string `json:"date"`
+	// TimezoneType is 1 for a UTC offset such as "+09:00", 2 for an abbreviation such as "JST", and 3 for an identifier such as "Asia/Tokyo".
+	TimezoneType int64  `json:"timezone_type"`
+	Timezone     string `json:"timezone"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Time) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		t.Time = time.Time{}
+		return nil
+	case len(b) > 0 && b[0] == '{':
+		var d phpDateTime
+		if err := json.Unmarshal(b, &d); err != nil {
+			return errorz.Errorf("json.Unmarshal: %w", err)
+		}
+		loc, err := d.location()
+		if err != nil {
+			return errorz.Errorf("d.location: %w", err)
+		}
+		parsed, err := parseTime(d.Date, loc)
+		if err != nil {
+			return errorz.Errorf("parseTime: %w", err)
+		}
+		t.Time = parsed
+		return nil
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return errorz.Errorf("json.Unmarshal: %w", err)
+		}
+		parsed, err := parseTime(s, time.UTC)
+		if err != nil {
+			return errorz.Errorf("parseTime: %w", err)
+		}
+		t.Time = parsed
+		return nil
+	default:
+		parsed, err := parseTime(string(b), time.UTC)
+		if err != nil {
+			return errorz.Errorf("parseTime: %w", err)
+		}
+		t.Time = parsed
+		return nil
+	}
+}
+
+// MarshalJSON implements json.Marshaler. It emits the time in TimeLayout in UTC, or null if the time is zero.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(t.UTC().Format(TimeLayout))), nil
+}
+
+// MarshalText implements encoding.TextMarshaler, which is used by the encoders other than encoding/json and for map keys.
+// It emits the time in TimeLayout in UTC as MarshalJSON does, or "" if the time is zero.
+func (t Time) MarshalText() ([]byte, error) {
+	return t.AppendText(nil)
+}
+
+// AppendText appends the text of MarshalText to b. It overrides the method of time.Time, which encoding/json prefers to MarshalText.
+func (t Time) AppendText(b []byte) ([]byte, error) {
+	if t.IsZero() {
+		return b, nil
+	}
+	return t.UTC().AppendFormat(b, TimeLayout), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the strings that UnmarshalJSON accepts.
+func (t *Time) UnmarshalText(b []byte) error {
+	parsed, err := parseTime(string(b), time.UTC)
+	if err != nil {
+		return errorz.Errorf("parseTime: %w", err)
+	}
+	t.Time = parsed
+	return nil
+}
+
+// String returns the time in TimeLayout in UTC, or "" if the time is zero.
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TimeLayout)
+}
+
+func (d *phpDateTime) location() (*time.Location, error) {
+	const (
+		timezoneTypeOffset       = 1
+		timezoneTypeAbbreviation = 2
+		timezoneTypeIdentifier   = 3
+	)
+
+	switch d.TimezoneType {
+	case timezoneTypeOffset:
+		offset, err := time.Parse("-07:00", d.Timezone)
+		if err != nil {
+			return nil, errorz.Errorf("time.Parse: timezone=%s: %w", d.Timezone, err)
+		}
+		_, seconds := offset.Zone()
+		return time.FixedZone(d.Timezone, seconds), nil
+	case timezoneTypeAbbreviation:
+		switch strings.ToUpper(d.Timezone) {
+		case "", "UTC", "GMT", "Z":
+			return time.UTC, nil
+		case "JST":
+			return time.FixedZone("JST", 9*60*60), nil //nolint:mnd
+		default:
+			return nil, errorz.Errorf("timezone=%s: unknown timezone abbreviation: %w", d.Timezone, ErrInvalidArgument)
+		}
+	default:
+		if d.Timezone == "" {
+			return time.UTC, nil
+		}
+		loc, err := time.LoadLocation(d.Timezone)
+		if err != nil {
+			return nil, errorz.Errorf("time.LoadLocation: timezone=%s: %w", d.Timezone, err)
+		}
+		return loc, nil
+	}
+}
+
+// parseTime parses s in one of the forms that Time accepts. A time without a timezone is interpreted in loc.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == zeroTimeSentinel || s == "0000-00-00" {
+		return time.Time{}, nil
+	}
+
+	if isDigits(s) {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, errorz.Errorf("strconv.ParseInt: %w", err)
+		}
+		return time.UnixMilli(v), nil
+	}
+
+	for _, layout := range []string{TimeLayout + ".999999999", time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errorz.Errorf("time=%q: unknown time format: %w", s, ErrInvalidArgument)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || '9' < r {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,148 @@
+package indigo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+)
+
+//nolint:funlen
+func TestTime_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*60*60)
+	tests := []struct {
+		name string
+		json string
+		want time.Time
+	}{
+		{name: "success,layout", json: `"2018-11-07 17:11:21"`, want: time.Date(2018, 11, 7, 17, 11, 21, 0, time.UTC)},
+		{name: "success,fraction", json: `"2018-11-10 10:03:17.744562"`, want: time.Date(2018, 11, 10, 10, 3, 17, 744562000, time.UTC)},
+		{name: "success,rfc3339", json: `"2018-11-10T10:03:17+09:00"`, want: time.Date(2018, 11, 10, 10, 3, 17, 0, jst)},
+		{name: "success,date", json: `"2018-09-30"`, want: time.Date(2018, 9, 30, 0, 0, 0, 0, time.UTC)},
+		{name: "success,epochMillisString", json: `"1550570350202"`, want: time.UnixMilli(1550570350202)},
+		{name: "success,epochMillisNumber", json: `1550570350202`, want: time.UnixMilli(1550570350202)},
+		{name: "success,phpDateTimeIdentifier", json: `{"date": "2018-11-10 10:03:17.744562", "timezone_type": 3, "timezone": "UTC"}`, want: time.Date(2018, 11, 10, 10, 3, 17, 744562000, time.UTC)},
+		{name: "success,phpDateTimeTokyo", json: `{"date": "2018-11-10 10:03:17.000000", "timezone_type": 3, "timezone": "Asia/Tokyo"}`, want: time.Date(2018, 11, 10, 10, 3, 17, 0, jst)},
+		{name: "success,phpDateTimeOffset", json: `{"date": "2018-11-10 10:03:17.000000", "timezone_type": 1, "timezone": "+09:00"}`, want: time.Date(2018, 11, 10, 10, 3, 17, 0, jst)},
+		{name: "success,phpDateTimeAbbreviation", json: `{"date": "2018-11-10 10:03:17.000000", "timezone_type": 2, "timezone": "JST"}`, want: time.Date(2018, 11, 10, 10, 3, 17, 0, jst)},
+		{name: "success,null", json: `null`},
+		{name: "success,empty", json: `""`},
+		{name: "success,zeroSentinel", json: `"0000-00-00 00:00:00"`},
+		{name: "success,zeroDateSentinel", json: `"0000-00-00"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := NewTime(time.Now())
+			requirez.NoError(t, json.Unmarshal([]byte(tt.json), &got))
+			requirez.Equal(t, tt.want.UnixNano(), got.UnixNano())
+			requirez.Equal(t, tt.want.IsZero(), got.IsZero())
+		})
+	}
+
+	failures := []struct {
+		name string
+		json string
+	}{
+		{name: "failure,unknownFormat", json: `"yesterday"`},
+		{name: "failure,unknownTimezone", json: `{"date": "2018-11-10 10:03:17", "timezone_type": 3, "timezone": "Mars/Olympus"}`},
+		{name: "failure,unknownAbbreviation", json: `{"date": "2018-11-10 10:03:17", "timezone_type": 2, "timezone": "XYZ"}`},
+		{name: "failure,invalidObject", json: `{"date": 1}`},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got Time
+			requirez.Error(t, json.Unmarshal([]byte(tt.json), &got))
+		})
+	}
+
+	t.Run("success,struct", func(t *testing.T) {
+		t.Parallel()
+
+		var snapshot WebArenaIndigoV1DiskSnapshot
+		requirez.NoError(t, json.Unmarshal([]byte(`{"completed_timestamp": "2018-11-27 07:24:05", "deleted_timestamp": "0000-00-00 00:00:00"}`), &snapshot))
+		requirez.Equal(t, 2018, snapshot.CompletedTimestamp.Year())
+		requirez.True(t, snapshot.DeletedTimestamp.IsZero())
+
+		var instance WebArenaIndigoV1VmInstance
+		requirez.NoError(t, json.Unmarshal([]byte(`{"start_date": {"date": "2018-11-10 10:03:17.744562", "timezone_type": 3, "timezone": "UTC"}, "updated_at": null}`), &instance))
+		requirez.Equal(t, 2018, instance.StartDate.Year())
+		requirez.True(t, instance.UpdatedAt.IsZero())
+	})
+}
+
+func TestTime_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := json.Marshal(NewTime(time.Date(2018, 11, 10, 19, 3, 17, 0, time.FixedZone("JST", 9*60*60))))
+		requirez.NoError(t, err)
+		requirez.Equal(t, `"2018-11-10 10:03:17"`, string(b))
+
+		var got Time
+		requirez.NoError(t, json.Unmarshal(b, &got))
+		requirez.Equal(t, "2018-11-10 10:03:17", got.String())
+	})
+
+	t.Run("success,zero", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := json.Marshal(Time{})
+		requirez.NoError(t, err)
+		requirez.Equal(t, `null`, string(b))
+		requirez.Equal(t, "", Time{}.String())
+	})
+}
+
+func TestTime_MarshalText(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := NewTime(time.Date(2018, 11, 10, 19, 3, 17, 0, time.FixedZone("JST", 9*60*60))).MarshalText()
+		requirez.NoError(t, err)
+		requirez.Equal(t, "2018-11-10 10:03:17", string(b))
+
+		b, err = Time{}.MarshalText()
+		requirez.NoError(t, err)
+		requirez.Equal(t, "", string(b))
+
+		// NOTE: A map key is encoded by MarshalText, in the same format as MarshalJSON.
+		b, err = json.Marshal(map[Time]int{NewTime(time.Date(2018, 11, 10, 10, 3, 17, 0, time.UTC)): 1})
+		requirez.NoError(t, err)
+		requirez.Equal(t, `{"2018-11-10 10:03:17":1}`, string(b))
+	})
+
+	t.Run("success,UnmarshalText", func(t *testing.T) {
+		t.Parallel()
+
+		want := time.Date(2018, 11, 10, 10, 3, 17, 0, time.UTC)
+		for _, text := range []string{"2018-11-10 10:03:17", "2018-11-10T19:03:17+09:00", "1541844197000"} {
+			var got Time
+			requirez.NoError(t, got.UnmarshalText([]byte(text)))
+			requirez.True(t, want.Equal(got.Time))
+		}
+
+		for _, text := range []string{"", "0000-00-00 00:00:00"} {
+			got := NewTime(want)
+			requirez.NoError(t, got.UnmarshalText([]byte(text)))
+			requirez.True(t, got.IsZero())
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		var got Time
+		requirez.ErrorIs(t, got.UnmarshalText([]byte("10 Nov 2018")), ErrInvalidArgument)
+	})
+}
@@ -0,0 +1,125 @@
+package indigo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hakadoriya/z.go/errorz"
+)
+
+// TokenSource supplies access tokens, like golang.org/x/oauth2.TokenSource.
+//
+// The client caches the returned access token in memory and calls Token again only when the access token is about to expire
+// or the API rejects it with 401 Unauthorized.
+type TokenSource interface {
+	Token(ctx context.Context) (*AccessToken, error)
+}
+
+// TokenInvalidator is optionally implemented by a TokenSource that caches access tokens itself.
+// The client calls InvalidateToken with the access token that the API rejected with 401 Unauthorized,
+// before calling Token again, so that the TokenSource does not return the rejected access token again.
+type TokenInvalidator interface {
+	InvalidateToken(ctx context.Context, token *AccessToken)
+}
+
+// TokenSourceFunc is an adapter to allow the use of an ordinary function as a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*AccessToken, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (*AccessToken, error) { return f(ctx) }
+
+// reuseTokenSource holds an access token and refreshes it shortly before it expires.
+//
+// It is safe for concurrent use. When the token needs to be refreshed, only one goroutine calls the source,
+// and the other goroutines wait for its result instead of issuing their own tokens.
+type reuseTokenSource struct {
+	source TokenSource
+	// refreshSkew is how long before IssuedAt+ExpiresIn the token is regarded as expired.
+	refreshSkew time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	token    *AccessToken
+	inflight *tokenCall
+}
+
+type tokenCall struct {
+	done  chan struct{}
+	token *AccessToken
+	err   error
+}
+
+func newReuseTokenSource(source TokenSource, refreshSkew time.Duration) *reuseTokenSource {
+	return &reuseTokenSource{
+		source:      source,
+		refreshSkew: refreshSkew,
+		now:         time.Now,
+	}
+}
+
+func (s *reuseTokenSource) valid(token *AccessToken) bool {
+	return token != nil && s.now().Add(s.refreshSkew).Before(token.IssuedAt.Add(token.ExpiresIn))
+}
+
+// Token returns the current access token, refreshing it if it is nil or about to expire.
+func (s *reuseTokenSource) Token(ctx context.Context) (*AccessToken, error) {
+	s.mu.Lock()
+	if s.valid(s.token) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+
+	call := s.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		s.inflight = call
+		// NOTE: Detach the cancellation of ctx so that a canceled caller does not fail the other goroutines waiting for the same refresh.
+		go s.doRefresh(context.WithoutCancel(ctx), call)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, errorz.Errorf("ctx.Done: %w", context.Cause(ctx))
+	case <-call.done:
+	}
+
+	if call.err != nil {
+		return nil, errorz.Errorf("refresh: %w", call.err)
+	}
+
+	return call.token, nil
+}
+
+// invalidate discards the current access token if it is still the given token, so that the next Token call refreshes it.
+//
+// Comparing against the given token prevents goroutines that got 401 with the same token from discarding
+// a token that another goroutine has already refreshed.
+func (s *reuseTokenSource) invalidate(ctx context.Context, token *AccessToken) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != token {
+		return
+	}
+	s.token = nil
+
+	// NOTE: Invalidate the token of the source while holding s.mu, so that no refresh can read the rejected token from the source in the meantime.
+	if invalidator, ok := s.source.(TokenInvalidator); ok {
+		invalidator.InvalidateToken(ctx, token)
+	}
+}
+
+func (s *reuseTokenSource) doRefresh(ctx context.Context, call *tokenCall) {
+	call.token, call.err = s.source.Token(ctx)
+
+	s.mu.Lock()
+	if call.err == nil {
+		s.token = call.token
+	}
+	s.inflight = nil
+	s.mu.Unlock()
+
+	close(call.done)
+}
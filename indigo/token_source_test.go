@@ -0,0 +1,254 @@
+package indigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hakadoriya/z.go/testingz/requirez"
+
+	"github.com/hakadoriya/webarena-go/indigo/indigotest"
+)
+
+type pathCountingTransport struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (t *pathCountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	if t.counts == nil {
+		t.counts = make(map[string]int)
+	}
+	t.counts[req.URL.Path]++
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req) //nolint:wrapcheck
+}
+
+func (t *pathCountingTransport) count(path string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[path]
+}
+
+func TestReuseTokenSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,singleFlight", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+		s := newReuseTokenSource(TokenSourceFunc(func(ctx context.Context) (*AccessToken, error) {
+			calls.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			return &AccessToken{AccessToken: "token", ExpiresIn: time.Hour, IssuedAt: time.Now()}, nil
+		}), time.Minute)
+
+		var wg sync.WaitGroup
+		for range 32 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := s.Token(context.Background())
+				requirez.NoError(t, err)
+				requirez.Equal(t, "token", token.AccessToken)
+			}()
+		}
+		wg.Wait()
+
+		requirez.Equal(t, int64(1), calls.Load())
+	})
+
+	t.Run("success,refreshWithinSkew", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+		now := time.Date(2024, 5, 12, 13, 41, 52, 0, time.UTC)
+		s := newReuseTokenSource(TokenSourceFunc(func(ctx context.Context) (*AccessToken, error) {
+			calls.Add(1)
+			return &AccessToken{AccessToken: "token", ExpiresIn: time.Hour, IssuedAt: now}, nil
+		}), time.Minute)
+		s.now = func() time.Time { return now }
+
+		_, err := s.Token(context.Background())
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(1), calls.Load())
+
+		s.now = func() time.Time { return now.Add(58 * time.Minute) }
+		_, err = s.Token(context.Background())
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(1), calls.Load())
+
+		s.now = func() time.Time { return now.Add(59*time.Minute + time.Second) }
+		_, err = s.Token(context.Background())
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(2), calls.Load())
+	})
+
+	t.Run("failure,refresh", func(t *testing.T) {
+		t.Parallel()
+
+		errRefresh := errors.New("refresh failed")
+		var calls atomic.Int64
+		s := newReuseTokenSource(TokenSourceFunc(func(ctx context.Context) (*AccessToken, error) {
+			if calls.Add(1) == 1 {
+				return nil, errRefresh
+			}
+			return &AccessToken{AccessToken: "token", ExpiresIn: time.Hour, IssuedAt: time.Now()}, nil
+		}), time.Minute)
+
+		_, err := s.Token(context.Background())
+		requirez.ErrorIs(t, err, errRefresh)
+
+		token, err := s.Token(context.Background())
+		requirez.NoError(t, err)
+		requirez.Equal(t, "token", token.AccessToken)
+	})
+
+	t.Run("failure,contextCanceled", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		s := newReuseTokenSource(TokenSourceFunc(func(ctx context.Context) (*AccessToken, error) {
+			<-release
+			return &AccessToken{AccessToken: "token", ExpiresIn: time.Hour, IssuedAt: time.Now()}, nil
+		}), time.Minute)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.Token(ctx)
+		requirez.ErrorIs(t, err, context.Canceled)
+
+		close(release)
+		token, err := s.Token(context.Background())
+		requirez.NoError(t, err)
+		requirez.Equal(t, "token", token.AccessToken)
+	})
+}
+
+func TestClient_concurrent(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name             string
+		tokenRefreshSkew time.Duration
+		assert           func(t *testing.T, issued int)
+	}{
+		{
+			name:             "success,reuseToken",
+			tokenRefreshSkew: 0,
+			assert:           func(t *testing.T, issued int) { t.Helper(); requirez.Equal(t, 1, issued) },
+		},
+		{
+			name:             "success,refreshToken",
+			tokenRefreshSkew: indigotest.DefaultTokenTTL,
+			assert:           func(t *testing.T, issued int) { t.Helper(); requirez.True(t, issued > 1) },
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := indigotest.NewServer()
+			t.Cleanup(srv.Close)
+
+			transport := &pathCountingTransport{}
+			ctx := context.Background()
+			client, err := NewClient(ctx, append(testServerClientOptions(srv),
+				ClientOptionWithHTTPClient(&http.Client{Transport: transport}),
+				ClientOptionWithTokenRefreshSkew(tt.tokenRefreshSkew),
+			)...)
+			requirez.NoError(t, err)
+
+			var wg sync.WaitGroup
+			for i := range 16 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range 4 {
+						if i%2 == 0 {
+							_, err := client.GetWebArenaIndigoV1VmSSHKey(ctx)
+							requirez.NoError(t, err)
+						} else {
+							_, err := client.CreateWebArenaIndigoV1VmSSHKey(ctx, &CreateWebArenaIndigoV1VmSSHKeyRequest{SshName: "test", SshKey: strings.Repeat("A", i)})
+							requirez.NoError(t, err)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			requirez.Equal(t, 64, transport.count(PathWebArenaIndigoV1VmSSHKey))
+			tt.assert(t, transport.count(PathOAuthV1AccessTokens))
+		})
+	}
+}
+
+func TestClient_tokenSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success,lazyAccessToken", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		transport := &pathCountingTransport{}
+		ctx := context.Background()
+		client, err := NewClient(ctx, append(testServerClientOptions(srv),
+			ClientOptionWithHTTPClient(&http.Client{Transport: transport}),
+			ClientOptionWithLazyAccessToken(),
+		)...)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 0, transport.count(PathOAuthV1AccessTokens))
+
+		_, err = client.GetWebArenaIndigoV1VmSSHKey(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, 1, transport.count(PathOAuthV1AccessTokens))
+	})
+
+	t.Run("success,customTokenSource", func(t *testing.T) {
+		t.Parallel()
+
+		srv := indigotest.NewServer()
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		issuer, err := NewClient(ctx, append(testServerClientOptions(srv), ClientOptionWithLazyAccessToken())...)
+		requirez.NoError(t, err)
+
+		var calls atomic.Int64
+		// NOTE: The client credentials are not required with a custom TokenSource.
+		client, err := NewClient(ctx,
+			ClientOptionWithEndpoint(srv.URL),
+			ClientOptionWithClientID(""),
+			ClientOptionWithClientSecret(""),
+			ClientOptionWithoutRateLimiter(),
+			ClientOptionWithTokenSource(TokenSourceFunc(func(ctx context.Context) (*AccessToken, error) {
+				calls.Add(1)
+				return issuer.IssueAccessToken(ctx)
+			})),
+		)
+		requirez.NoError(t, err)
+
+		_, err = client.GetWebArenaIndigoV1VmSSHKey(ctx)
+		requirez.NoError(t, err)
+		requirez.Equal(t, int64(1), calls.Load())
+	})
+
+	t.Run("failure,emptyClientCredentials", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewClient(context.Background(),
+			ClientOptionWithClientID(""),
+			ClientOptionWithClientSecret(""),
+			ClientOptionWithFileTokenCache(filepath.Join(t.TempDir(), "token.json")),
+		)
+		requirez.ErrorIs(t, err, ErrInvalidClientCredentials)
+	})
+}
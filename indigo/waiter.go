@@ -0,0 +1,167 @@
+package indigo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hakadoriya/z.go/errorz"
+	"github.com/hakadoriya/z.go/retryz"
+)
+
+// WaitPolicy configures how the waiters such as WaitForInstanceStatus poll the API.
+//
+// The interval between the polls grows by Backoff from InitialInterval up to MaxInterval.
+// The interval is also stretched so that the polls spread the remaining rate limit quota until X-Quota-Reset,
+// because the API allows only a few requests per minute and the other requests of the caller need the quota as well.
+//
+// Example:
+//
+//	policy := indigo.DefaultWaitPolicy()
+//	policy.Timeout = 30 * time.Minute
+//	policy.OnProgress = func(p indigo.WaitProgress) { log.Printf("poll=%d status=%s elapsed=%s", p.Poll, p.Status, p.Elapsed) }
+//	client, err := indigo.NewClient(ctx, indigo.ClientOptionWithWaitPolicy(policy))
+type WaitPolicy struct {
+	// Timeout limits the whole wait. 0 means no limit other than the context.
+	Timeout time.Duration
+	// InitialInterval is the interval after the first poll, passed to Backoff.
+	InitialInterval time.Duration
+	// MaxInterval caps the interval calculated by Backoff.
+	MaxInterval time.Duration
+	// Backoff calculates the interval after each poll. If nil, retryz.DefaultBackoff is used, which doubles the interval for each poll.
+	Backoff retryz.Backoff
+	// Jitter randomizes the interval calculated by Backoff. If nil, retryz.DefaultJitter is used.
+	Jitter retryz.Jitter
+	// OnProgress is called after each poll. It must not block.
+	OnProgress func(progress WaitProgress)
+
+	now func() time.Time
+}
+
+// WaitProgress is the progress of a wait, passed to WaitPolicy.OnProgress after each poll.
+type WaitProgress struct {
+	// Poll is the number of polls so far, starting from 1.
+	Poll int
+	// Elapsed is the time since the wait started.
+	Elapsed time.Duration
+	// Status is the status observed by the poll.
+	Status string
+	// Done reports whether the status is one of the targets.
+	Done bool
+	// Next is the interval before the next poll, or 0 if Done.
+	Next time.Duration
+}
+
+// DefaultWaitPolicy returns the WaitPolicy that the client uses by default.
+// It polls from every 10 seconds to every minute, for up to 15 minutes.
+func DefaultWaitPolicy() *WaitPolicy {
+	const (
+		defaultWaitTimeout         = 15 * time.Minute
+		defaultInitialWaitInterval = 10 * time.Second
+		defaultMaxWaitInterval     = 1 * time.Minute
+	)
+
+	return &WaitPolicy{
+		Timeout:         defaultWaitTimeout,
+		InitialInterval: defaultInitialWaitInterval,
+		MaxInterval:     defaultMaxWaitInterval,
+	}
+}
+
+type waitPolicyOption struct{ waitPolicy *WaitPolicy }
+
+func (o *waitPolicyOption) apply(c *Client) { c.waitPolicy = o.waitPolicy }
+
+// ClientOptionWithWaitPolicy replaces DefaultWaitPolicy with waitPolicy.
+// waitPolicy must not be modified after it is passed to the client. Use ContextWithWaitPolicy to override it for a call.
+func ClientOptionWithWaitPolicy(waitPolicy *WaitPolicy) ClientOption { //nolint:ireturn
+	return &waitPolicyOption{waitPolicy: waitPolicy}
+}
+
+type waitPolicyContextKey struct{}
+
+// ContextWithWaitPolicy returns a copy of ctx that makes the waiters called with it use waitPolicy instead of the WaitPolicy of the client.
+func ContextWithWaitPolicy(ctx context.Context, waitPolicy *WaitPolicy) context.Context {
+	return context.WithValue(ctx, waitPolicyContextKey{}, waitPolicy)
+}
+
+func (c *Client) waitPolicyFromContext(ctx context.Context) *WaitPolicy {
+	if p, ok := ctx.Value(waitPolicyContextKey{}).(*WaitPolicy); ok && p != nil {
+		return p
+	}
+	return c.waitPolicy
+}
+
+func (p *WaitPolicy) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// interval returns the backoff with jitter after the given poll (1-origin).
+func (p *WaitPolicy) interval(poll int) time.Duration {
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = retryz.DefaultBackoff()
+	}
+	jitter := p.Jitter
+	if jitter == nil {
+		jitter = retryz.DefaultJitter()
+	}
+
+	d := backoff(p.InitialInterval, poll-1)
+	if p.MaxInterval > 0 && (d > p.MaxInterval || d < 0) {
+		d = p.MaxInterval
+	}
+	return max(jitter(d), 0)
+}
+
+// pollFunc polls the status of a resource once. It reports done if the status is one of the targets,
+// and returns an error to stop the wait, e.g. if the status is terminal.
+type pollFunc func(ctx context.Context) (status string, done bool, err error)
+
+// wait calls poll until it reports done or returns an error, following the WaitPolicy of ctx.
+func (c *Client) wait(ctx context.Context, poll pollFunc) error {
+	p := c.waitPolicyFromContext(ctx)
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, p.Timeout, fmt.Errorf("timeout=%s: %w: %w", p.Timeout, ErrWaitTimeout, context.DeadlineExceeded))
+		defer cancel()
+	}
+
+	start := p.clock()
+	var status string
+	for n := 1; ; n++ {
+		s, done, err := poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return errorz.Errorf("poll=%d status=%q: %w", n, status, context.Cause(ctx))
+			}
+			return errorz.Errorf("poll=%d: %w", n, err)
+		}
+		status = s
+
+		progress := WaitProgress{Poll: n, Elapsed: p.clock().Sub(start), Status: status, Done: done}
+		if !done {
+			progress.Next = p.interval(n)
+			if q := c.lastQuota.Load(); q != nil {
+				progress.Next = max(progress.Next, q.pace(p.clock()))
+			}
+		}
+		if p.OnProgress != nil {
+			p.OnProgress(progress)
+		}
+		if done {
+			return nil
+		}
+
+		timer := time.NewTimer(progress.Next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errorz.Errorf("poll=%d status=%q: %w", n, status, context.Cause(ctx))
+		case <-timer.C:
+		}
+	}
+}